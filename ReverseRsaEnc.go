@@ -1,82 +1,83 @@
 // Package rsa contains simple exploration of the math concepts
 // behind RSA encryption & decryption.
 // There's usage of Pollard's Rho factorization method to reverse simple encryption keys.
+//
+// The math/big arithmetic follows math/big's own conventions: functions
+// taking a destination z store the result in z and return it, z may alias
+// any of the operands, and operands are never modified. Callers in hot
+// loops reuse z to avoid allocating a new big.Int per call.
 package rsa
 
 import (
 	"fmt"
 	"math/big"
+	"time"
 )
 
 // EuclideanMod in contrast to go's native % modulus operator (sign matches the dividend's)
 // returns only positive remainder results according to the Euclidean definition
-// in which the remainder is nonnegative always, 0 ≤ r, and is thus consistent
+// in which the remainder is nonnegative always, 0 ≤ r < |m|, and is thus consistent
 // with the Euclidean division algorithm to produce correct results when used
 // with the [Extended] Euclidean algorithms for number inversions.
-// Overriding the default go's sign result allows the GetPrimeFactors below
-// to calculate accurate factors.
-// https://en.wikipedia.org/wiki/Modulo_operation
-// https://stackoverflow.com/questions/43018206/modulo-of-negative-integers-in-go
-// func EuclideanMod(d, m int64) int64 {
-// 	res := d % m
-// 	if res < 0 && m > 0 {
-// 		return res + m
-// 	}
-// 	return res
-// }
-
-// EuclideanMod in contrast to go's native % modulus operator (sign matches the dividend's)
-// returns only positive remainder results according to the Euclidean definition
-// in which the remainder is nonnegative always, 0 ≤ r, and is thus consistent
-// with the Euclidean division algorithm to produce correct results when used
-// with the [Extended] Euclidean algorithms for number inversions.
-// Overriding the default go's sign result allows the GetPrimeFactors below
-// to calculate accurate factors.
+// It is the int64 counterpart of ModEuclid.
 // https://en.wikipedia.org/wiki/Modulo_operation
 // https://stackoverflow.com/questions/43018206/modulo-of-negative-integers-in-go
 func EuclideanMod(d, m int64) int64 {
 
-	// fmt.Println("d: ", d, ", m: ", m, ", zero: ", zero)
-	res := d & m
-
-	//fmt.Println("res: ", res)
-	if res < -1 && m > 0 {
-		return res + m
+	res := d % m
+	if res < 0 {
+		if m > 0 {
+			return res + m
+		}
+		return res - m
 	}
 	return res
 }
 
-// GetMod is applying Euclidean Modulus to math/big integers
-// without side effects.
-func GetMod(n1, n2 big.Int) big.Int {
+// DivEuclid sets z to the quotient q of the Euclidean division a = q*b + r
+// with 0 ≤ r < |b| and returns z. Unlike Quo, which truncates towards zero,
+// q rounds down for b > 0 and up for b < 0, so that r is never negative.
+// A zero b panics, as for big.Int's division.
+// https://en.wikipedia.org/wiki/Euclidean_division
+func DivEuclid(z, a, b *big.Int) *big.Int {
+
+	return z.Div(a, b)
+}
+
+// ModEuclid sets z to the remainder r of the Euclidean division a = q*b + r,
+// the canonical representative 0 ≤ r < |b| of a modulo b, and returns z.
+// It is the normalization every inverse and residue of this package goes
+// through, so that equal residues always compare equal.
+func ModEuclid(z, a, b *big.Int) *big.Int {
+
+	return z.Mod(a, b)
+}
 
-	// Clone and perform modulus to avoid mutation.
-	res := new(big.Int).Mod(&n1, &n2)
+// GetMod sets z to the Euclidean Modulus n1 mod n2 of math/big integers,
+// always nonnegative, and returns z.
+func GetMod(z, n1, n2 *big.Int) *big.Int {
 
-	return *res
+	return ModEuclid(z, n1, n2)
 }
 
-// GetGcd calculates the greatest common divisor
-// or highest common factor (hcf) of 2 numbers without side effects.
+// GetGcd sets z to the greatest common divisor
+// or highest common factor (hcf) of 2 numbers and returns z.
 // Overriding bigInt's gcd because of bigInt's modulus behavior.
-func GetGcd(n1, n2 big.Int) *big.Int {
-
-	zero := big.NewInt(0)
-
-	// Clone to avoid side effects to the caller's args.
-	n1Copy := new(big.Int).Set(&n1)
-	n2Copy := new(big.Int).Set(&n2)
-	//fmt.Printf("Starting..n1: %v, n2: %v, n1n2Mod: %v\n", n1Copy, n2Copy, GetMod(*n1Copy, *n2Copy))
-
-	for n1n2Mod := GetMod(*n1Copy, *n2Copy); n1n2Mod.Cmp(zero) != 0; {
-		n1Copy.Set(n2Copy)
-		//fmt.Printf("n1: %v, n2: %v, n1n2Mod: %v\n", n1Copy, n2Copy, &n1n2Mod)
-		n2Copy.Set(&n1n2Mod)
-		//fmt.Printf("n1: %v, n2: %v, n1n2Mod: %v\n", n1Copy, n2Copy, &n1n2Mod)
-		n1n2Mod = GetMod(*n1Copy, *n2Copy)
-		//fmt.Printf("n1: %v, n2: %v, n1n2Mod: %v\n", n1Copy, n2Copy, &n1n2Mod)
+func GetGcd(z, n1, n2 *big.Int) *big.Int {
+
+	// Work on copies so that z may alias n1 or n2.
+	n1Copy := new(big.Int).Set(n1)
+	n2Copy := new(big.Int).Set(n2)
+	n1n2Mod := new(big.Int)
+	// DivMod is the Euclidean modulus of GetMod with a reusable quotient,
+	// Mod would allocate a new one on every step.
+	quotient := new(big.Int)
+
+	for quotient.DivMod(n1Copy, n2Copy, n1n2Mod); n1n2Mod.Sign() != 0; quotient.DivMod(n1Copy, n2Copy, n1n2Mod) {
+		// Rotate the 3 numbers instead of copying them.
+		n1Copy, n2Copy, n1n2Mod = n2Copy, n1n2Mod, n1Copy
 	}
-	return n2Copy
+	return z.Set(n2Copy)
 }
 
 // GetPrimeFactors is an implementation of
@@ -86,52 +87,105 @@ func GetGcd(n1, n2 big.Int) *big.Int {
 // we attempt to break RSA's N number to its 2 prime factors
 // so we may recreate the private key.
 // https://en.wikipedia.org/wiki/Pollard's_rho_algorithm
-func GetPrimeFactors(n int64) (big.Int, big.Int) {
+func GetPrimeFactors(n int64) (*big.Int, *big.Int) {
 
-	xFixed := big.NewInt(2)
-	tempX := big.NewInt(2)
-	cycleSize := 2
-	x := big.NewInt(2)
-	factor := big.NewInt(1)
+	p, q, _ := GetPrimeFactorsWithStats(n)
+	fmt.Println("p: ", p, ", q: ", q)
+	return p, q
+}
+
+// maxRhoRestarts bounds the fresh starts of GetPrimeFactorsWithStats
+// so a prime n, which has no nontrivial factor to find, terminates.
+const maxRhoRestarts = 8
+
+// RhoStats quantifies a Pollard's Rho run so that experiments comparing
+// polynomial constants or cycle detectors can be measured.
+type RhoStats struct {
+	// Iterations counts the x = x*x + c steps.
+	Iterations int
+	// GcdCalls counts the gcd(x - xFixed, n) evaluations.
+	GcdCalls int
+	// CycleLength is the distance between the colliding x and xFixed
+	// values of the successful run, a multiple of the cycle length modulo p.
+	CycleLength int
+	// Restarts counts the runs that collided modulo n itself
+	// and were repeated with the next polynomial constant c.
+	Restarts int
+	// Duration is the wall time of the whole factorization.
+	Duration time.Duration
+}
+
+// Cost returns the stats as the Cost other attacks report: each iteration
+// is one modular squaring, and no heap is sampled.
+func (s RhoStats) Cost() Cost {
+
+	return Cost{ModMuls: s.Iterations, Duration: s.Duration}
+}
+
+// GetPrimeFactorsWithStats is GetPrimeFactors returning RhoStats as well.
+// A perfect square returns its root twice without walking. A run whose gcd
+// hits n itself is restarted with x = x*x + c for the next c, up to
+// maxRhoRestarts times, after which n, 1 is returned.
+func GetPrimeFactorsWithStats(n int64) (*big.Int, *big.Int, RhoStats) {
+
+	start := time.Now()
+	stats := RhoStats{}
 	one := big.NewInt(1)
 	nBig := big.NewInt(n)
+	if root, ok := exactSqrt(nBig); ok && root.Cmp(one) > 0 {
+		stats.Duration = time.Since(start)
+		return root, new(big.Int).Set(root), stats
+	}
+	gcd := fastestGcd(nBig.BitLen())
+	factor := big.NewInt(1)
 
-	for factor.Cmp(one) == 0 {
-		for count := 1; count <= cycleSize && factor.Cmp(one) <= 0; count++ {
-			x.Mul(x, x)
-			x.Add(x, one)
-			x.Mod(x, nBig) // x = (x*x + 1) % n
-			tempX.Sub(x, xFixed)
-			//fmt.Printf("tempX: %v, x: %v, xFixed: %v\n", tempX, x, xFixed)
-			factor = GetGcd(*tempX, *nBig)
-			// fmt.Printf(", x: %v, xFixed: %v, tempX: %v, factor: %v\n", x, xFixed, tempX, factor)
+	for c := int64(1); ; c++ {
+		xFixed := big.NewInt(2)
+		tempX := big.NewInt(2)
+		cycleSize := 2
+		x := big.NewInt(2)
+		cBig := big.NewInt(c)
+		quotient := new(big.Int)
+		factor.Set(one)
+
+		for factor.Cmp(one) == 0 {
+			for count := 1; count <= cycleSize && factor.Cmp(one) <= 0; count++ {
+				x.Mul(x, x)
+				x.Add(x, cBig)
+				quotient.DivMod(x, nBig, x) // x = (x*x + c) % n
+				tempX.Sub(x, xFixed)
+				gcd(factor, tempX, nBig)
+				stats.Iterations++
+				stats.GcdCalls++
+				stats.CycleLength = count
+			}
+			cycleSize *= 2
+			xFixed.Set(x)
 		}
-		cycleSize *= 2
-		//fmt.Printf(" ,cycleSize: %v\n", cycleSize)
-		xFixed.Set(x)
+
+		if factor.Cmp(nBig) != 0 || stats.Restarts == maxRhoRestarts {
+			break
+		}
+		stats.Restarts++
 	}
 
 	p := factor
-	q := nBig.Div(nBig, p)
-	fmt.Println("p: ", p, ", q: ", q)
-	return *p, *q
+	q := new(big.Int).Div(nBig, p)
+	stats.Duration = time.Since(start)
+	return p, q, stats
 }
 
-// GetPhi calculates Phi(n) as phi = (p-1)*(q-1)
-// with big.Int without side effects.
-func GetPhi(p, q big.Int) *big.Int {
+// GetPhi sets z to Phi(n) as phi = (p-1)*(q-1) and returns z.
+func GetPhi(z, p, q *big.Int) *big.Int {
 
-	pCopy := new(big.Int).Set(&p)
-	qCopy := new(big.Int).Set(&q)
 	one := big.NewInt(1)
+	qMinus1 := new(big.Int).Sub(q, one)
 
-	pCopy = pCopy.Sub(pCopy, one)
-	qCopy = qCopy.Sub(qCopy, one)
-
-	phi := pCopy.Mul(pCopy, qCopy)
-	fmt.Println("Phi: ", phi)
+	z.Sub(p, one)
+	z.Mul(z, qMinus1)
+	fmt.Println("Phi: ", z)
 
-	return phi
+	return z
 }
 
 // simpleModularInverse calculates the multiplicative inverse of num (i) so that num*i = 1 mod n.
@@ -171,7 +225,7 @@ func GetExtEuclideanAlgorithm(a, b int64) (int64, int64, int64) {
 
 // GetMultInverse returns the multiplicative inverse of
 // n modulo p.
-// This function returns an integer m such that
+// This function returns an integer m in [0, p) such that
 // (n * m) % p == 1.
 func GetMultInverse(n, modulusBase int64) (int64, error) {
 
@@ -180,13 +234,25 @@ func GetMultInverse(n, modulusBase int64) (int64, error) {
 	if gcd != 1 {
 		return 0, fmt.Errorf("GetMultInverse: no inverse is found either because gcd is not 1 but %v, or n is 0 (%v), or modulusBase (%v) is not a prime number", gcd, n, modulusBase)
 	}
-	return x % modulusBase, nil
+	// x may be negative, or n may have been, ModEuclid returns the
+	// canonical representative.
+	return ModEuclid(new(big.Int), big.NewInt(x), big.NewInt(modulusBase)).Int64(), nil
 }
 
 // GetEncOrDecMsg calculates a ** power % number
+// Moduli too large to square within an int64 are transparently promoted
+// to big.Int, the result always fits as it is below the modulus.
 // https://stackoverflow.com/questions/8496182/calculating-powa-b-mod-n
 func GetEncOrDecMsg(base, exp, modulus int64) int64 {
 
+	result, _ := NewNumber(base).ExpMod(NewNumber(exp), NewNumber(modulus)).Int64()
+	return result
+}
+
+// expMod64 is the int64 square and multiply of GetEncOrDecMsg,
+// correct only while (modulus-1)^2 fits an int64.
+func expMod64(base, exp, modulus int64) int64 {
+
 	base %= modulus
 	var result int64 = 1
 	for exp > 0 {
@@ -205,7 +271,7 @@ func GetEncOrDecMsg(base, exp, modulus int64) int64 {
 func DecryptCipher(cipher, n, e int64) int64 {
 
 	p, q := GetPrimeFactors(n)
-	phi := GetPhi(p, q)
+	phi := GetPhi(new(big.Int), p, q)
 	d, err := GetMultInverse(e, phi.Int64())
 	if err != nil {
 		fmt.Println(e)
@@ -217,3 +283,47 @@ func DecryptCipher(cipher, n, e int64) int64 {
 
 	return m
 }
+
+// DecryptWithFactors returns m = c^d mod n for n = p*q, deriving d from the
+// known distinct primes p and q and e, and decrypting with the CRT. Unlike
+// DecryptCipher it never factors n, so any modulus size works.
+func DecryptWithFactors(c, p, q, e *big.Int) (*big.Int, error) {
+
+	priv, err := NewPrivateKey(p, q, e)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptWithFactors: %v", err)
+	}
+	defer priv.Zeroize()
+	if c.Sign() < 0 || c.Cmp(priv.N) >= 0 {
+		return nil, fmt.Errorf("DecryptWithFactors: ciphertext out of range [0, n)")
+	}
+	m, err := crtExp(priv, c, nil)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptWithFactors: %v", err)
+	}
+	return m, nil
+}
+
+// DecryptWithPhi returns m = c^d mod n with d = e^-1 mod phi, for a known
+// φ(n), or any multiple of λ(n) such as φ(n), without factoring n.
+func DecryptWithPhi(c, n, e, phi *big.Int) (*big.Int, error) {
+
+	d := new(big.Int).ModInverse(e, phi)
+	if d == nil {
+		return nil, fmt.Errorf("DecryptWithPhi: e (%v) is not invertible modulo φ(n)", e)
+	}
+	defer zeroizeInt(d)
+	return DecryptWithD(c, n, d)
+}
+
+// DecryptWithD returns m = c^d mod n for a known private exponent d.
+func DecryptWithD(c, n, d *big.Int) (*big.Int, error) {
+
+	if n.Sign() <= 0 || d.Sign() <= 0 {
+		return nil, fmt.Errorf("DecryptWithD: n (%v) and d must be positive", n)
+	}
+	if c.Sign() < 0 || c.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("DecryptWithD: ciphertext out of range [0, n)")
+	}
+	return new(big.Int).Exp(c, d, n), nil
+}
@@ -0,0 +1,158 @@
+package rsa
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// Armor types of the package's results.
+const (
+	ArmorCiphertext = "RSA CIPHERTEXT"
+	ArmorSignature  = "RSA SIGNATURE"
+)
+
+// armorLineLen is the number of base64 characters per body line.
+const armorLineLen = 64
+
+// Armor is a result wrapped as text in the style of OpenPGP's ASCII
+// armor, so it survives being pasted into an email or a chat:
+//
+//	-----BEGIN RSA CIPHERTEXT-----
+//	Key: 2c9b3f1e...
+//
+//	Vx3Ep0...
+//	=n5tW
+//	-----END RSA CIPHERTEXT-----
+//
+// The body is base64 in lines of 64 characters, followed by the base64
+// CRC-24 of the data that catches a line lost or mangled on the way.
+// https://www.rfc-editor.org/rfc/rfc4880#section-6.2
+type Armor struct {
+	// Type names the content, e.g. ArmorCiphertext.
+	Type string
+	// Headers are "Key: value" lines, written in sorted key order.
+	Headers map[string]string
+	Data    []byte
+}
+
+// ArmorInt armors x, a ciphertext or a signature, as a big-endian byte
+// string with a Key header holding the hexadecimal modulus it belongs to.
+func ArmorInt(armorType string, x *big.Int, pub *PublicKey) *Armor {
+
+	return &Armor{
+		Type:    armorType,
+		Headers: map[string]string{"Key": pub.N.Text(16)},
+		Data:    x.Bytes(),
+	}
+}
+
+// Int returns the armored data as a big-endian number.
+func (a *Armor) Int() *big.Int {
+
+	return new(big.Int).SetBytes(a.Data)
+}
+
+// Serialize returns the armored text.
+func (a *Armor) Serialize() string {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "-----BEGIN %v-----\n", a.Type)
+	keys := make([]string, 0, len(a.Headers))
+	for key := range a.Headers {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%v: %v\n", key, a.Headers[key])
+	}
+	b.WriteString("\n")
+
+	body := base64.StdEncoding.EncodeToString(a.Data)
+	for len(body) > armorLineLen {
+		b.WriteString(body[:armorLineLen] + "\n")
+		body = body[armorLineLen:]
+	}
+	if body != "" {
+		b.WriteString(body + "\n")
+	}
+	fmt.Fprintf(&b, "=%v\n", crc24Base64(a.Data))
+	fmt.Fprintf(&b, "-----END %v-----\n", a.Type)
+	return b.String()
+}
+
+// ParseArmor parses the first armored block of text, ignoring text around
+// it and surrounding whitespace on every line as chat clients add it.
+func ParseArmor(text string) (*Armor, error) {
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	a := &Armor{Headers: map[string]string{}}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if t, ok := strings.CutPrefix(line, "-----BEGIN "); ok && strings.HasSuffix(t, "-----") {
+			a.Type = strings.TrimSuffix(t, "-----")
+			break
+		}
+	}
+	if a.Type == "" {
+		return nil, fmt.Errorf("ParseArmor: no BEGIN line found")
+	}
+
+	inHeaders := true
+	var body strings.Builder
+	var crc string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "-----END "+a.Type+"-----":
+			data, err := base64.StdEncoding.DecodeString(body.String())
+			if err != nil {
+				return nil, fmt.Errorf("ParseArmor: %v", err)
+			}
+			if crc == "" {
+				return nil, fmt.Errorf("ParseArmor: missing checksum")
+			}
+			if got := crc24Base64(data); got != crc {
+				return nil, fmt.Errorf("ParseArmor: checksum %v does not match the data's %v, the text was altered", crc, got)
+			}
+			a.Data = data
+			return a, nil
+		case inHeaders && line == "":
+			inHeaders = false
+		case inHeaders:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, fmt.Errorf("ParseArmor: malformed header %q", line)
+			}
+			a.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		case strings.HasPrefix(line, "="):
+			crc = line[1:]
+		default:
+			body.WriteString(line)
+		}
+	}
+	return nil, fmt.Errorf("ParseArmor: no END line for %v", a.Type)
+}
+
+// crc24Base64 is the base64 of OpenPGP's CRC-24 of data.
+func crc24Base64(data []byte) string {
+
+	const (
+		crc24Init = 0xb704ce
+		crc24Poly = 0x1864cfb
+	)
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)})
+}
@@ -0,0 +1,54 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestArmor(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := new(big.Int).Exp(big.NewInt(42), priv.E, priv.N)
+	text := rsa.ArmorInt(rsa.ArmorCiphertext, c, &priv.PublicKey).Serialize()
+
+	// Chat clients indent and surround the pasted block.
+	pasted := "here it is:\n" + strings.ReplaceAll(text, "\n", "\n  ") + "\nthanks"
+	a, err := rsa.ParseArmor(pasted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Type != rsa.ArmorCiphertext || a.Int().Cmp(c) != 0 || a.Headers["Key"] != priv.N.Text(16) {
+		t.Errorf("parsed %+v, expected the ciphertext %v", a, c)
+	}
+
+	// Altering a body character breaks the checksum.
+	lines := strings.Split(text, "\n")
+	body := []byte(lines[3])
+	body[10] ^= 'A' ^ 'B'
+	lines[3] = string(body)
+	if _, err := rsa.ParseArmor(strings.Join(lines, "\n")); err == nil {
+		t.Error("expected an altered body to fail the checksum")
+	}
+}
+
+func TestArmorEmpty(t *testing.T) {
+	// CRC-24 of no data is its initial value 0xb704ce.
+	text := (&rsa.Armor{Type: rsa.ArmorSignature}).Serialize()
+	if !strings.Contains(text, "\n=twTO\n") {
+		t.Errorf("unexpected checksum in\n%v", text)
+	}
+	a, err := rsa.ParseArmor(text)
+	if err != nil || len(a.Data) != 0 {
+		t.Errorf("parsed %+v (%v), expected empty data", a, err)
+	}
+	if _, err := rsa.ParseArmor("no armor here"); err == nil {
+		t.Error("expected an error for text without armor")
+	}
+}
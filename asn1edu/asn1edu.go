@@ -0,0 +1,343 @@
+// Package asn1edu is a minimal, educational ASN.1 DER encoder and decoder
+// for the two PKCS#1 RSA key structures and the PKCS#8 and
+// SubjectPublicKeyInfo containers that wrap them.
+// It encodes and decodes RSAPrivateKey and RSAPublicKey field by field
+// and dumps the bytes of a .der key with the field each of them belongs to.
+// https://datatracker.ietf.org/doc/html/rfc8017#appendix-A.1
+// https://en.wikipedia.org/wiki/X.690#DER_encoding
+package asn1edu
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DER tags of the types the RSA key structures and their containers use.
+const (
+	TagInteger          byte = 0x02
+	TagBitString        byte = 0x03
+	TagOctetString      byte = 0x04
+	TagNull             byte = 0x05
+	TagObjectIdentifier byte = 0x06
+	TagSequence         byte = 0x30
+)
+
+// RSAPublicKey mirrors PKCS#1's
+//
+//	RSAPublicKey ::= SEQUENCE {
+//	    modulus           INTEGER,  -- n
+//	    publicExponent    INTEGER   -- e
+//	}
+type RSAPublicKey struct {
+	N, E *big.Int
+}
+
+// RSAPrivateKey mirrors PKCS#1's two-prime
+//
+//	RSAPrivateKey ::= SEQUENCE {
+//	    version           Version,
+//	    modulus           INTEGER,  -- n
+//	    publicExponent    INTEGER,  -- e
+//	    privateExponent   INTEGER,  -- d
+//	    prime1            INTEGER,  -- p
+//	    prime2            INTEGER,  -- q
+//	    exponent1         INTEGER,  -- d mod (p-1)
+//	    exponent2         INTEGER,  -- d mod (q-1)
+//	    coefficient       INTEGER   -- (inverse of q) mod p
+//	}
+type RSAPrivateKey struct {
+	Version                     int64
+	N, E, D, P, Q, Dp, Dq, Qinv *big.Int
+}
+
+// publicKeyFields and privateKeyFields name the INTEGERs in order.
+var (
+	publicKeyFields  = []string{"modulus", "publicExponent"}
+	privateKeyFields = []string{"version", "modulus", "publicExponent", "privateExponent",
+		"prime1", "prime2", "exponent1", "exponent2", "coefficient"}
+)
+
+// Element is one decoded tag-length-value triple.
+// Offset is where the tag byte sits in the parsed input and
+// HeaderLen the number of tag and length bytes before Content.
+type Element struct {
+	Tag       byte
+	Offset    int
+	HeaderLen int
+	Content   []byte
+	Children  []Element // set for sequences only
+}
+
+// EncodeLength returns the DER length octets: a single byte below 128,
+// otherwise 0x80 | count followed by count big-endian bytes.
+func EncodeLength(length int) []byte {
+
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var octets []byte
+	for l := length; l > 0; l >>= 8 {
+		octets = append([]byte{byte(l)}, octets...)
+	}
+	return append([]byte{0x80 | byte(len(octets))}, octets...)
+}
+
+// EncodeInteger returns the DER INTEGER of a nonnegative n: the minimal
+// big-endian two's complement bytes, so a 0x00 is prepended whenever
+// the top bit is set to keep the number positive.
+func EncodeInteger(n *big.Int) ([]byte, error) {
+
+	if n == nil || n.Sign() < 0 {
+		return nil, fmt.Errorf("EncodeInteger: only nonnegative integers are supported, got %v", n)
+	}
+
+	content := n.Bytes()
+	if len(content) == 0 || content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+	return append(append([]byte{TagInteger}, EncodeLength(len(content))...), content...), nil
+}
+
+// EncodeSequence wraps already encoded elements in a DER SEQUENCE.
+func EncodeSequence(elements ...[]byte) []byte {
+
+	var content []byte
+	for _, element := range elements {
+		content = append(content, element...)
+	}
+	return append(append([]byte{TagSequence}, EncodeLength(len(content))...), content...)
+}
+
+// Parse decodes a single DER element spanning all of der,
+// descending into sequences.
+func Parse(der []byte) (Element, error) {
+
+	element, rest, err := parseElement(der, 0)
+	if err != nil {
+		return Element{}, err
+	}
+	if len(rest) > 0 {
+		return Element{}, fmt.Errorf("Parse: %v trailing bytes after the element", len(rest))
+	}
+	return element, nil
+}
+
+// parseElement decodes the element at the start of der, located at
+// offset in the original input, and returns the bytes following it.
+func parseElement(der []byte, offset int) (Element, []byte, error) {
+
+	if len(der) < 2 {
+		return Element{}, nil, fmt.Errorf("parse: truncated element at offset %v", offset)
+	}
+
+	element := Element{Tag: der[0], Offset: offset}
+	length, headerLen := int(der[1]), 2
+	if der[1]&0x80 != 0 {
+		count := int(der[1] & 0x7f)
+		if count == 0 || count > 4 || len(der) < 2+count {
+			return Element{}, nil, fmt.Errorf("parse: bad length octets at offset %v", offset)
+		}
+		length = 0
+		for _, b := range der[2 : 2+count] {
+			length = length<<8 | int(b)
+		}
+		if length < 0x80 || der[2] == 0 {
+			return Element{}, nil, fmt.Errorf("parse: length %v at offset %v is not minimally encoded", length, offset)
+		}
+		headerLen += count
+	}
+	if len(der) < headerLen+length {
+		return Element{}, nil, fmt.Errorf("parse: element at offset %v needs %v bytes, only %v left", offset, length, len(der)-headerLen)
+	}
+	element.HeaderLen = headerLen
+	element.Content = der[headerLen : headerLen+length]
+
+	if element.Tag == TagSequence {
+		content := element.Content
+		childOffset := offset + headerLen
+		for len(content) > 0 {
+			child, rest, err := parseElement(content, childOffset)
+			if err != nil {
+				return Element{}, nil, err
+			}
+			element.Children = append(element.Children, child)
+			childOffset += len(content) - len(rest)
+			content = rest
+		}
+	}
+
+	return element, der[headerLen+length:], nil
+}
+
+// Integer decodes the content of an INTEGER element, rejecting the
+// negative and non-minimal encodings DER forbids for key fields.
+func (e Element) Integer() (*big.Int, error) {
+
+	if e.Tag != TagInteger {
+		return nil, fmt.Errorf("Integer: element at offset %v has tag 0x%02x, not INTEGER", e.Offset, e.Tag)
+	}
+	switch {
+	case len(e.Content) == 0:
+		return nil, fmt.Errorf("Integer: empty INTEGER at offset %v", e.Offset)
+	case e.Content[0]&0x80 != 0:
+		return nil, fmt.Errorf("Integer: negative INTEGER at offset %v", e.Offset)
+	case len(e.Content) > 1 && e.Content[0] == 0 && e.Content[1]&0x80 == 0:
+		return nil, fmt.Errorf("Integer: INTEGER at offset %v has a superfluous leading zero", e.Offset)
+	}
+	return new(big.Int).SetBytes(e.Content), nil
+}
+
+// MarshalDER encodes the public key as a PKCS#1 RSAPublicKey.
+func (k *RSAPublicKey) MarshalDER() ([]byte, error) {
+
+	return encodeIntegers(k.N, k.E)
+}
+
+// MarshalDER encodes the private key as a PKCS#1 RSAPrivateKey.
+func (k *RSAPrivateKey) MarshalDER() ([]byte, error) {
+
+	return encodeIntegers(big.NewInt(k.Version), k.N, k.E, k.D, k.P, k.Q, k.Dp, k.Dq, k.Qinv)
+}
+
+// ParseRSAPublicKey decodes a PKCS#1 RSAPublicKey.
+func ParseRSAPublicKey(der []byte) (*RSAPublicKey, error) {
+
+	ints, err := decodeIntegers(der, len(publicKeyFields))
+	if err != nil {
+		return nil, fmt.Errorf("ParseRSAPublicKey: %v", err)
+	}
+	return &RSAPublicKey{N: ints[0], E: ints[1]}, nil
+}
+
+// ParseRSAPrivateKey decodes a two-prime PKCS#1 RSAPrivateKey.
+func ParseRSAPrivateKey(der []byte) (*RSAPrivateKey, error) {
+
+	ints, err := decodeIntegers(der, len(privateKeyFields))
+	if err != nil {
+		return nil, fmt.Errorf("ParseRSAPrivateKey: %v", err)
+	}
+	if ints[0].Sign() != 0 {
+		return nil, fmt.Errorf("ParseRSAPrivateKey: only version 0 (two-prime) keys are supported, got %v", ints[0])
+	}
+	return &RSAPrivateKey{
+		Version: ints[0].Int64(),
+		N:       ints[1], E: ints[2], D: ints[3],
+		P: ints[4], Q: ints[5],
+		Dp: ints[6], Dq: ints[7], Qinv: ints[8],
+	}, nil
+}
+
+// Dumper writes the dumps of the Dump functions with the INTEGER values
+// written by Format, e.g. rsa.FormatHex.Format, so that a dump follows
+// the number format of the rest of a demo. A nil Format writes numbers
+// of up to 64 bits in decimal and larger ones in hex with their bit length.
+type Dumper struct {
+	Format func(*big.Int) string
+}
+
+// DumpRSAPublicKey annotates every element of a PKCS#1 RSAPublicKey.
+func DumpRSAPublicKey(der []byte) (string, error) {
+
+	return Dumper{}.RSAPublicKey(der)
+}
+
+// DumpRSAPrivateKey annotates every element of a PKCS#1 RSAPrivateKey.
+func DumpRSAPrivateKey(der []byte) (string, error) {
+
+	return Dumper{}.RSAPrivateKey(der)
+}
+
+// RSAPublicKey is DumpRSAPublicKey in d's format.
+func (d Dumper) RSAPublicKey(der []byte) (string, error) {
+
+	return d.dump(der, "RSAPublicKey", publicKeyFields)
+}
+
+// RSAPrivateKey is DumpRSAPrivateKey in d's format.
+func (d Dumper) RSAPrivateKey(der []byte) (string, error) {
+
+	return d.dump(der, "RSAPrivateKey", privateKeyFields)
+}
+
+// encodeIntegers encodes a SEQUENCE of INTEGERs.
+func encodeIntegers(ints ...*big.Int) ([]byte, error) {
+
+	elements := make([][]byte, len(ints))
+	for i, n := range ints {
+		element, err := EncodeInteger(n)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = element
+	}
+	return EncodeSequence(elements...), nil
+}
+
+// decodeIntegers decodes a SEQUENCE of exactly count INTEGERs.
+func decodeIntegers(der []byte, count int) ([]*big.Int, error) {
+
+	seq, err := Parse(der)
+	if err != nil {
+		return nil, err
+	}
+	if seq.Tag != TagSequence || len(seq.Children) != count {
+		return nil, fmt.Errorf("expected a SEQUENCE of %v INTEGERs", count)
+	}
+
+	ints := make([]*big.Int, count)
+	for i, child := range seq.Children {
+		if ints[i], err = child.Integer(); err != nil {
+			return nil, err
+		}
+	}
+	return ints, nil
+}
+
+// dump prints one line per element: its offset, tag and length bytes,
+// the field it encodes and the decoded value.
+func (d Dumper) dump(der []byte, name string, fields []string) (string, error) {
+
+	var sb strings.Builder
+	if err := d.dumpIntegers(&sb, der, 0, name, fields); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// dumpIntegers writes the dump of a SEQUENCE of INTEGERs found at offset
+// base of an enclosing structure to sb.
+func (d Dumper) dumpIntegers(sb *strings.Builder, der []byte, base int, name string, fields []string) error {
+
+	seq, err := Parse(der)
+	if err != nil {
+		return err
+	}
+	if seq.Tag != TagSequence || len(seq.Children) != len(fields) {
+		return fmt.Errorf("dump: expected a SEQUENCE of %v INTEGERs for %v", len(fields), name)
+	}
+
+	fmt.Fprintf(sb, "%04x  % x  SEQUENCE %v (%d bytes)\n", base+seq.Offset, der[:seq.HeaderLen], name, len(seq.Content))
+	for i, child := range seq.Children {
+		n, err := child.Integer()
+		if err != nil {
+			return err
+		}
+		header := der[child.Offset : child.Offset+child.HeaderLen]
+		fmt.Fprintf(sb, "%04x  % x  INTEGER %v = %v\n", base+child.Offset, header, fields[i], d.integer(n))
+	}
+	return nil
+}
+
+// integer writes n in d's format. The default prints small numbers in
+// decimal and large ones in hex with their bit length.
+func (d Dumper) integer(n *big.Int) string {
+
+	if d.Format != nil {
+		return d.Format(n)
+	}
+	if n.BitLen() <= 64 {
+		return n.String()
+	}
+	return fmt.Sprintf("0x%x (%d bits)", n, n.BitLen())
+}
@@ -0,0 +1,92 @@
+package asn1edu_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa/asn1edu"
+)
+
+func TestMatchesPKCS1(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv := &asn1edu.RSAPrivateKey{
+		N: key.N, E: big.NewInt(int64(key.E)), D: key.D,
+		P: key.Primes[0], Q: key.Primes[1],
+		Dp: key.Precomputed.Dp, Dq: key.Precomputed.Dq, Qinv: key.Precomputed.Qinv,
+	}
+	der, err := priv.MarshalDER()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := x509.MarshalPKCS1PrivateKey(key); !bytes.Equal(der, expected) {
+		t.Errorf("private key DER differs from crypto/x509:\n% x\n% x", der, expected)
+	}
+
+	parsed, err := asn1edu.ParseRSAPrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.D.Cmp(key.D) != 0 || parsed.Qinv.Cmp(key.Precomputed.Qinv) != 0 {
+		t.Error("parsed private key does not match the original")
+	}
+
+	pub := &asn1edu.RSAPublicKey{N: key.N, E: big.NewInt(int64(key.E))}
+	pubDer, err := pub.MarshalDER()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := x509.MarshalPKCS1PublicKey(&key.PublicKey); !bytes.Equal(pubDer, expected) {
+		t.Errorf("public key DER differs from crypto/x509:\n% x\n% x", pubDer, expected)
+	}
+
+	dump, err := asn1edu.DumpRSAPrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(dump), "\n"); len(lines) != 10 {
+		t.Errorf("expected a line for the SEQUENCE and each of its 9 fields, got:\n%v", dump)
+	}
+	if !strings.Contains(dump, "INTEGER publicExponent = 65537") {
+		t.Errorf("dump does not show the public exponent:\n%v", dump)
+	}
+
+	hex := asn1edu.Dumper{Format: func(n *big.Int) string { return n.Text(16) }}
+	if dump, err = hex.RSAPublicKey(pubDer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dump, "INTEGER publicExponent = 10001") {
+		t.Errorf("hex dump does not show the public exponent in hex:\n%v", dump)
+	}
+}
+
+func TestParseRejectsNonDER(t *testing.T) {
+	tests := map[string][]byte{
+		"negative integer": {0x30, 0x06, 0x02, 0x01, 0x80, 0x02, 0x01, 0x03},
+		"leading zero":     {0x30, 0x07, 0x02, 0x02, 0x00, 0x05, 0x02, 0x01, 0x03},
+		"long form length": {0x30, 0x81, 0x06, 0x02, 0x01, 0x05, 0x02, 0x01, 0x03},
+		"truncated":        {0x30, 0x06, 0x02, 0x01, 0x05},
+		"trailing bytes":   {0x30, 0x06, 0x02, 0x01, 0x05, 0x02, 0x01, 0x03, 0x00},
+		"too few integers": {0x30, 0x03, 0x02, 0x01, 0x05},
+		"not a sequence":   {0x02, 0x01, 0x05},
+	}
+
+	for name, der := range tests {
+		if _, err := asn1edu.ParseRSAPublicKey(der); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+
+	valid := []byte{0x30, 0x06, 0x02, 0x01, 0x05, 0x02, 0x01, 0x03}
+	if key, err := asn1edu.ParseRSAPublicKey(valid); err != nil || key.N.Int64() != 5 || key.E.Int64() != 3 {
+		t.Errorf("ParseRSAPublicKey(% x) = %+v, %v", valid, key, err)
+	}
+}
@@ -0,0 +1,325 @@
+package asn1edu
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RSAEncryptionOID is rsaEncryption, 1.2.840.113549.1.1.1, the algorithm
+// naming an RSA key in PKCS#8 and SubjectPublicKeyInfo.
+// https://datatracker.ietf.org/doc/html/rfc8017#appendix-A.1
+var RSAEncryptionOID = []int{1, 2, 840, 113549, 1, 1, 1}
+
+// EncodeObjectIdentifier returns the DER OBJECT IDENTIFIER of arcs: the
+// first 2 arcs packed into the byte 40*arcs[0] + arcs[1], then every arc
+// in base 128, most significant group first, with the top bit of every
+// byte but the last of an arc set.
+func EncodeObjectIdentifier(arcs []int) ([]byte, error) {
+
+	if len(arcs) < 2 || arcs[0] < 0 || arcs[0] > 2 || arcs[1] < 0 || (arcs[0] < 2 && arcs[1] > 39) {
+		return nil, fmt.Errorf("EncodeObjectIdentifier: %v is not a valid object identifier", arcs)
+	}
+
+	var content []byte
+	for i, arc := range append([]int{40*arcs[0] + arcs[1]}, arcs[2:]...) {
+		if arc < 0 {
+			return nil, fmt.Errorf("EncodeObjectIdentifier: negative arc %v at %v", arc, i+1)
+		}
+		group := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			group = append([]byte{byte(arc&0x7f) | 0x80}, group...)
+		}
+		content = append(content, group...)
+	}
+	return encodeElement(TagObjectIdentifier, content), nil
+}
+
+// ObjectIdentifier decodes the arcs of an OBJECT IDENTIFIER element.
+func (e Element) ObjectIdentifier() ([]int, error) {
+
+	if e.Tag != TagObjectIdentifier {
+		return nil, fmt.Errorf("ObjectIdentifier: element at offset %v has tag 0x%02x, not OBJECT IDENTIFIER", e.Offset, e.Tag)
+	}
+
+	var arcs []int
+	arc := 0
+	for i, b := range e.Content {
+		if arc == 0 && b == 0x80 {
+			return nil, fmt.Errorf("ObjectIdentifier: arc at offset %v has a superfluous leading group", e.Offset+e.HeaderLen+i)
+		}
+		if arc > 1<<24 {
+			return nil, fmt.Errorf("ObjectIdentifier: arc at offset %v is too large", e.Offset+e.HeaderLen+i)
+		}
+		arc = arc<<7 | int(b&0x7f)
+		if b&0x80 != 0 {
+			continue
+		}
+		if len(arcs) == 0 {
+			first := min(arc/40, 2)
+			arcs = append(arcs, first, arc-40*first)
+		} else {
+			arcs = append(arcs, arc)
+		}
+		arc = 0
+	}
+	if len(arcs) == 0 || e.Content[len(e.Content)-1]&0x80 != 0 {
+		return nil, fmt.Errorf("ObjectIdentifier: truncated OBJECT IDENTIFIER at offset %v", e.Offset)
+	}
+	return arcs, nil
+}
+
+// EncodeBitString returns the DER BIT STRING of the whole bytes b: the
+// count of unused bits in the last byte, always 0 here, followed by b.
+func EncodeBitString(b []byte) []byte {
+
+	return encodeElement(TagBitString, append([]byte{0}, b...))
+}
+
+// EncodeOctetString returns the DER OCTET STRING of b.
+func EncodeOctetString(b []byte) []byte {
+
+	return encodeElement(TagOctetString, b)
+}
+
+// MarshalPKIX encodes the public key as a SubjectPublicKeyInfo, the
+// PKCS#1 RSAPublicKey in a BIT STRING labelled with its algorithm:
+//
+//	SubjectPublicKeyInfo ::= SEQUENCE {
+//	    algorithm         AlgorithmIdentifier,
+//	    subjectPublicKey  BIT STRING  -- RSAPublicKey
+//	}
+//
+// https://datatracker.ietf.org/doc/html/rfc5280#section-4.1.2.7
+func (k *RSAPublicKey) MarshalPKIX() ([]byte, error) {
+
+	inner, err := k.MarshalDER()
+	if err != nil {
+		return nil, fmt.Errorf("MarshalPKIX: %v", err)
+	}
+	algorithm, err := rsaAlgorithmIdentifier()
+	if err != nil {
+		return nil, fmt.Errorf("MarshalPKIX: %v", err)
+	}
+	return EncodeSequence(algorithm, EncodeBitString(inner)), nil
+}
+
+// MarshalPKCS8 encodes the private key as a PKCS#8 PrivateKeyInfo, the
+// PKCS#1 RSAPrivateKey in an OCTET STRING labelled with its algorithm:
+//
+//	PrivateKeyInfo ::= SEQUENCE {
+//	    version              INTEGER,  -- 0
+//	    privateKeyAlgorithm  AlgorithmIdentifier,
+//	    privateKey           OCTET STRING  -- RSAPrivateKey
+//	}
+//
+// https://datatracker.ietf.org/doc/html/rfc5208#section-5
+func (k *RSAPrivateKey) MarshalPKCS8() ([]byte, error) {
+
+	inner, err := k.MarshalDER()
+	if err != nil {
+		return nil, fmt.Errorf("MarshalPKCS8: %v", err)
+	}
+	algorithm, err := rsaAlgorithmIdentifier()
+	if err != nil {
+		return nil, fmt.Errorf("MarshalPKCS8: %v", err)
+	}
+	return EncodeSequence(encodeElement(TagInteger, []byte{0}), algorithm, EncodeOctetString(inner)), nil
+}
+
+// ParsePKIXPublicKey decodes an RSA SubjectPublicKeyInfo.
+func ParsePKIXPublicKey(der []byte) (*RSAPublicKey, error) {
+
+	inner, _, err := unwrapPKIX(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePKIXPublicKey: %v", err)
+	}
+	key, err := ParseRSAPublicKey(inner)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePKIXPublicKey: %v", err)
+	}
+	return key, nil
+}
+
+// ParsePKCS8PrivateKey decodes an RSA PKCS#8 PrivateKeyInfo without
+// attributes.
+func ParsePKCS8PrivateKey(der []byte) (*RSAPrivateKey, error) {
+
+	inner, _, err := unwrapPKCS8(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePKCS8PrivateKey: %v", err)
+	}
+	key, err := ParseRSAPrivateKey(inner)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePKCS8PrivateKey: %v", err)
+	}
+	return key, nil
+}
+
+// DumpPKIXPublicKey annotates every element of a SubjectPublicKeyInfo,
+// including those of the RSAPublicKey inside its BIT STRING.
+func DumpPKIXPublicKey(der []byte) (string, error) {
+
+	return Dumper{}.PKIXPublicKey(der)
+}
+
+// DumpPKCS8PrivateKey annotates every element of a PKCS#8 PrivateKeyInfo,
+// including those of the RSAPrivateKey inside its OCTET STRING.
+func DumpPKCS8PrivateKey(der []byte) (string, error) {
+
+	return Dumper{}.PKCS8PrivateKey(der)
+}
+
+// PKIXPublicKey is DumpPKIXPublicKey in d's format.
+func (d Dumper) PKIXPublicKey(der []byte) (string, error) {
+
+	inner, info, err := unwrapPKIX(der)
+	if err != nil {
+		return "", fmt.Errorf("DumpPKIXPublicKey: %v", err)
+	}
+	var sb strings.Builder
+	dumpHeader(&sb, der, info, "SEQUENCE SubjectPublicKeyInfo")
+	dumpAlgorithm(&sb, der, info.Children[0])
+	key := info.Children[1]
+	dumpHeader(&sb, der, key, "BIT STRING subjectPublicKey, 0 unused bits")
+	if err := d.dumpIntegers(&sb, inner, key.Offset+key.HeaderLen+1, "RSAPublicKey", publicKeyFields); err != nil {
+		return "", fmt.Errorf("DumpPKIXPublicKey: %v", err)
+	}
+	return sb.String(), nil
+}
+
+// PKCS8PrivateKey is DumpPKCS8PrivateKey in d's format.
+func (d Dumper) PKCS8PrivateKey(der []byte) (string, error) {
+
+	inner, info, err := unwrapPKCS8(der)
+	if err != nil {
+		return "", fmt.Errorf("DumpPKCS8PrivateKey: %v", err)
+	}
+	var sb strings.Builder
+	dumpHeader(&sb, der, info, "SEQUENCE PrivateKeyInfo")
+	dumpHeader(&sb, der, info.Children[0], "INTEGER version = 0")
+	dumpAlgorithm(&sb, der, info.Children[1])
+	key := info.Children[2]
+	dumpHeader(&sb, der, key, "OCTET STRING privateKey")
+	if err := d.dumpIntegers(&sb, inner, key.Offset+key.HeaderLen, "RSAPrivateKey", privateKeyFields); err != nil {
+		return "", fmt.Errorf("DumpPKCS8PrivateKey: %v", err)
+	}
+	return sb.String(), nil
+}
+
+// rsaAlgorithmIdentifier encodes
+//
+//	AlgorithmIdentifier ::= SEQUENCE {
+//	    algorithm   OBJECT IDENTIFIER,  -- rsaEncryption
+//	    parameters  NULL
+//	}
+func rsaAlgorithmIdentifier() ([]byte, error) {
+
+	oid, err := EncodeObjectIdentifier(RSAEncryptionOID)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeSequence(oid, []byte{TagNull, 0x00}), nil
+}
+
+// checkRSAAlgorithm checks that algorithm is rsaEncryption with NULL
+// parameters.
+func checkRSAAlgorithm(algorithm Element) error {
+
+	if algorithm.Tag != TagSequence || len(algorithm.Children) != 2 {
+		return fmt.Errorf("expected an AlgorithmIdentifier SEQUENCE at offset %v", algorithm.Offset)
+	}
+	oid, err := algorithm.Children[0].ObjectIdentifier()
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(oid, RSAEncryptionOID) {
+		return fmt.Errorf("algorithm %v is not rsaEncryption", formatOID(oid))
+	}
+	if params := algorithm.Children[1]; params.Tag != TagNull || len(params.Content) != 0 {
+		return fmt.Errorf("rsaEncryption parameters at offset %v are not NULL", params.Offset)
+	}
+	return nil
+}
+
+// unwrapPKIX returns the RSAPublicKey DER inside a SubjectPublicKeyInfo
+// and the parsed SubjectPublicKeyInfo.
+func unwrapPKIX(der []byte) ([]byte, Element, error) {
+
+	info, err := Parse(der)
+	if err != nil {
+		return nil, Element{}, err
+	}
+	if info.Tag != TagSequence || len(info.Children) != 2 {
+		return nil, Element{}, fmt.Errorf("expected a SEQUENCE of an AlgorithmIdentifier and a BIT STRING")
+	}
+	if err := checkRSAAlgorithm(info.Children[0]); err != nil {
+		return nil, Element{}, err
+	}
+	key := info.Children[1]
+	if key.Tag != TagBitString || len(key.Content) == 0 || key.Content[0] != 0 {
+		return nil, Element{}, fmt.Errorf("expected a BIT STRING of whole bytes at offset %v", key.Offset)
+	}
+	return key.Content[1:], info, nil
+}
+
+// unwrapPKCS8 returns the RSAPrivateKey DER inside a PrivateKeyInfo
+// and the parsed PrivateKeyInfo.
+func unwrapPKCS8(der []byte) ([]byte, Element, error) {
+
+	info, err := Parse(der)
+	if err != nil {
+		return nil, Element{}, err
+	}
+	if info.Tag != TagSequence || len(info.Children) != 3 {
+		return nil, Element{}, fmt.Errorf("expected a SEQUENCE of a version, an AlgorithmIdentifier and an OCTET STRING")
+	}
+	version, err := info.Children[0].Integer()
+	if err != nil {
+		return nil, Element{}, err
+	}
+	if version.Sign() != 0 {
+		return nil, Element{}, fmt.Errorf("only version 0 PrivateKeyInfo is supported, got %v", version)
+	}
+	if err := checkRSAAlgorithm(info.Children[1]); err != nil {
+		return nil, Element{}, err
+	}
+	key := info.Children[2]
+	if key.Tag != TagOctetString {
+		return nil, Element{}, fmt.Errorf("expected an OCTET STRING at offset %v", key.Offset)
+	}
+	return key.Content, info, nil
+}
+
+// dumpHeader writes the line of a single element, described by label.
+func dumpHeader(sb *strings.Builder, der []byte, e Element, label string) {
+
+	fmt.Fprintf(sb, "%04x  % x  %v (%d bytes)\n", e.Offset, der[e.Offset:e.Offset+e.HeaderLen], label, len(e.Content))
+}
+
+// dumpAlgorithm writes the lines of an AlgorithmIdentifier already
+// checked by checkRSAAlgorithm.
+func dumpAlgorithm(sb *strings.Builder, der []byte, algorithm Element) {
+
+	dumpHeader(sb, der, algorithm, "SEQUENCE AlgorithmIdentifier")
+	oid, params := algorithm.Children[0], algorithm.Children[1]
+	fmt.Fprintf(sb, "%04x  % x  OBJECT IDENTIFIER algorithm = %v (rsaEncryption)\n",
+		oid.Offset, der[oid.Offset:oid.Offset+oid.HeaderLen], formatOID(RSAEncryptionOID))
+	fmt.Fprintf(sb, "%04x  % x  NULL parameters\n", params.Offset, der[params.Offset:params.Offset+params.HeaderLen])
+}
+
+// formatOID prints arcs in the usual dotted notation.
+func formatOID(arcs []int) string {
+
+	parts := make([]string, len(arcs))
+	for i, arc := range arcs {
+		parts[i] = fmt.Sprint(arc)
+	}
+	return strings.Join(parts, ".")
+}
+
+// encodeElement returns the DER tag-length-value triple of content.
+func encodeElement(tag byte, content []byte) []byte {
+
+	return append(append([]byte{tag}, EncodeLength(len(content))...), content...)
+}
@@ -0,0 +1,140 @@
+package asn1edu_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa/asn1edu"
+)
+
+func TestMatchesPKCS8AndPKIX(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv := &asn1edu.RSAPrivateKey{
+		N: key.N, E: big.NewInt(int64(key.E)), D: key.D,
+		P: key.Primes[0], Q: key.Primes[1],
+		Dp: key.Precomputed.Dp, Dq: key.Precomputed.Dq, Qinv: key.Precomputed.Qinv,
+	}
+	der, err := priv.MarshalPKCS8()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(der, expected) {
+		t.Errorf("PKCS#8 DER differs from crypto/x509:\n% x\n% x", der, expected)
+	}
+	parsed, err := asn1edu.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.D.Cmp(key.D) != 0 || parsed.Qinv.Cmp(key.Precomputed.Qinv) != 0 {
+		t.Error("parsed PKCS#8 key does not match the original")
+	}
+
+	pub := &asn1edu.RSAPublicKey{N: key.N, E: big.NewInt(int64(key.E))}
+	pubDer, err := pub.MarshalPKIX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err = x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pubDer, expected) {
+		t.Errorf("SubjectPublicKeyInfo DER differs from crypto/x509:\n% x\n% x", pubDer, expected)
+	}
+	parsedPub, err := asn1edu.ParsePKIXPublicKey(pubDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsedPub.N.Cmp(key.N) != 0 || parsedPub.E.Int64() != int64(key.E) {
+		t.Error("parsed SubjectPublicKeyInfo does not match the original")
+	}
+
+	dump, err := asn1edu.DumpPKIXPublicKey(pubDer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SubjectPublicKeyInfo, AlgorithmIdentifier, OID, NULL, BIT STRING,
+	// then the RSAPublicKey SEQUENCE and its 2 fields.
+	if lines := strings.Split(strings.TrimSpace(dump), "\n"); len(lines) != 8 {
+		t.Errorf("expected 8 lines, got:\n%v", dump)
+	}
+	for _, want := range []string{"1.2.840.113549.1.1.1 (rsaEncryption)", "INTEGER publicExponent = 65537"} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump lacks %q:\n%v", want, dump)
+		}
+	}
+	dump, err = asn1edu.DumpPKCS8PrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(dump), "\n"); len(lines) != 16 {
+		t.Errorf("expected 16 lines, got:\n%v", dump)
+	}
+
+	// The PKCS#1 parsers reject the containers and vice versa.
+	if _, err := asn1edu.ParseRSAPublicKey(pubDer); err == nil {
+		t.Error("ParseRSAPublicKey accepted a SubjectPublicKeyInfo")
+	}
+	pkcs1, err := pub.MarshalDER()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := asn1edu.ParsePKIXPublicKey(pkcs1); err == nil {
+		t.Error("ParsePKIXPublicKey accepted a PKCS#1 RSAPublicKey")
+	}
+}
+
+func TestObjectIdentifier(t *testing.T) {
+	for _, arcs := range [][]int{asn1edu.RSAEncryptionOID, {2, 999, 3}, {0, 39}, {1, 3, 6, 1, 4, 1, 1 << 20}} {
+		der, err := asn1edu.EncodeObjectIdentifier(arcs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		element, err := asn1edu.Parse(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := element.ObjectIdentifier()
+		if err != nil || !slices.Equal(got, arcs) {
+			t.Errorf("%v decoded to %v (%v)", arcs, got, err)
+		}
+	}
+	// 1.2.840.113549.1.1.1 as crypto/x509 writes it.
+	der, _ := asn1edu.EncodeObjectIdentifier(asn1edu.RSAEncryptionOID)
+	if expected := []byte{0x06, 0x09, 0x2a, 0x86, 0x48, 0x86, 0xf7, 0x0d, 0x01, 0x01, 0x01}; !bytes.Equal(der, expected) {
+		t.Errorf("rsaEncryption encoded as % x", der)
+	}
+
+	for _, arcs := range [][]int{{1}, {3, 1}, {1, 40}, {1, 2, -1}} {
+		if _, err := asn1edu.EncodeObjectIdentifier(arcs); err == nil {
+			t.Errorf("expected an error encoding %v", arcs)
+		}
+	}
+	for name, der := range map[string][]byte{
+		"empty":         {0x06, 0x00},
+		"truncated arc": {0x06, 0x02, 0x2a, 0x86},
+		"leading group": {0x06, 0x03, 0x2a, 0x80, 0x01},
+	} {
+		element, err := asn1edu.Parse(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := element.ObjectIdentifier(); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+}
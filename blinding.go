@@ -0,0 +1,82 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// defaultBlindingBits is the size of the random multiplier k
+// of exponent blinding when DecryptOptions leaves it unset.
+const defaultBlindingBits = 64
+
+// DecryptOptions selects how Decrypt exponentiates.
+// The zero value is textbook square and multiply with d.
+type DecryptOptions struct {
+	// Mode is the exponentiation algorithm.
+	Mode ExpMode
+	// ExponentBlinding replaces d with d' = d + k*λ(n) for a fresh random k
+	// on every call. As c^λ(n) = 1 mod n the result is unchanged, but the
+	// exponent whose bits drive the running time differs every time, so
+	// timings averaged over many decryptions no longer reveal d.
+	ExponentBlinding bool
+	// BlindingBits is the size of k, 64 if 0.
+	BlindingBits int
+	// Random is the source of k, crypto/rand.Reader if nil.
+	Random io.Reader
+}
+
+// Decrypt returns c^d mod n computed as opts selects, nil opts
+// meaning the zero DecryptOptions.
+func Decrypt(priv *PrivateKey, c *big.Int, opts *DecryptOptions) (*big.Int, error) {
+
+	if opts == nil {
+		opts = &DecryptOptions{}
+	}
+	if c.Sign() < 0 || c.Cmp(priv.N) >= 0 {
+		return nil, fmt.Errorf("Decrypt: ciphertext out of range [0, n)")
+	}
+
+	d := priv.D
+	if opts.ExponentBlinding {
+		bits := opts.BlindingBits
+		if bits == 0 {
+			bits = defaultBlindingBits
+		}
+		blinded, err := BlindExponent(new(big.Int), priv, bits, opts.Random)
+		if err != nil {
+			return nil, err
+		}
+		defer zeroizeInt(blinded)
+		d = blinded
+	}
+
+	return ModExp(new(big.Int), c, d, priv.N, opts.Mode), nil
+}
+
+// BlindExponent sets z to d + k*λ(n) for a random k of at most bits bits,
+// bits >= 1, drawn from random or crypto/rand.Reader if nil, and returns z.
+// https://en.wikipedia.org/wiki/Blinding_(cryptography)
+func BlindExponent(z *big.Int, priv *PrivateKey, bits int, random io.Reader) (*big.Int, error) {
+
+	if priv.P == nil || priv.Q == nil || priv.D == nil {
+		return nil, fmt.Errorf("BlindExponent: private key needs p, q and d")
+	}
+	if bits < 1 {
+		return nil, fmt.Errorf("BlindExponent: %v blinding bits, expected at least 1", bits)
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	k, err := rand.Int(random, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	if err != nil {
+		return nil, err
+	}
+	lambda := carmichael(new(big.Int), priv.P, priv.Q)
+	defer zeroizeInt(lambda)
+	defer zeroizeInt(k)
+	k.Mul(k, lambda)
+	return z.Add(priv.D, k), nil
+}
@@ -0,0 +1,72 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestDecryptExponentBlinding(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := big.NewInt(888888)
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+
+	for _, opts := range []*rsa.DecryptOptions{
+		nil,
+		{Mode: rsa.ExpConstantTime},
+		{ExponentBlinding: true},
+		{Mode: rsa.ExpConstantTime, ExponentBlinding: true, BlindingBits: 32},
+	} {
+		got, err := rsa.Decrypt(priv, c, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(m) != 0 {
+			t.Errorf("%+v: decrypted %v, expected %v", opts, got, m)
+		}
+	}
+
+	d1, err := rsa.BlindExponent(new(big.Int), priv, 64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d2, err := rsa.BlindExponent(new(big.Int), priv, 64, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d1.Cmp(d2) == 0 {
+		t.Error("expected a fresh blinded exponent per call")
+	}
+
+	for _, bits := range []int{0, -1, -1 << 40} {
+		if _, err := rsa.BlindExponent(new(big.Int), priv, bits, nil); err == nil {
+			t.Errorf("expected an error for %v blinding bits", bits)
+		}
+	}
+	if _, err := rsa.Decrypt(priv, c, &rsa.DecryptOptions{ExponentBlinding: true, BlindingBits: -8}); err == nil {
+		t.Error("expected Decrypt to reject negative blinding bits")
+	}
+}
+
+func TestExponentBlindingDefeatsTimingAttack(t *testing.T) {
+	priv := timingLabKey(t)
+	for _, blinding := range []bool{false, true} {
+		server, err := rsa.NewTimingLabServer(priv, time.Microsecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server.ExponentBlinding = blinding
+		samples := simulatedTimings(t, server, &priv.PublicKey, 2000)
+		d, _, err := rsa.TimingAttack(&priv.PublicKey, samples)
+		if recovered := err == nil && d.Cmp(priv.D) == 0; recovered == blinding {
+			t.Errorf("blinding %v: recovered d %v, expected %v", blinding, recovered, !blinding)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// BroadcastScenario is the classroom setting of Håstad's broadcast attack:
+// one message sent without padding to several recipients whose keys share
+// a small public exponent e. With at least e recipients an eavesdropper
+// holding nothing but the ciphertexts recovers the message.
+type BroadcastScenario struct {
+	Message     *big.Int
+	Recipients  []*PrivateKey
+	Ciphertexts []Ciphertext
+	// Format is how Run writes numbers, decimal by default.
+	Format NumberFormat
+}
+
+// NewBroadcastScenario generates recipients keys of bits bits with public
+// exponent e, drawing from random or crypto/rand.Reader if nil, and
+// encrypts msg to each of them.
+func NewBroadcastScenario(random io.Reader, msg *big.Int, e, recipients, bits int) (*BroadcastScenario, error) {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	if msg.Sign() < 0 || msg.BitLen() >= bits {
+		return nil, fmt.Errorf("NewBroadcastScenario: message must be below every %v bit modulus", bits)
+	}
+
+	s := &BroadcastScenario{Message: new(big.Int).Set(msg)}
+	for i := 0; i < recipients; i++ {
+		priv, err := GenerateKeyPair(random, bits, big.NewInt(int64(e)))
+		if err != nil {
+			return nil, fmt.Errorf("NewBroadcastScenario: %v", err)
+		}
+		s.Recipients = append(s.Recipients, priv)
+		s.Ciphertexts = append(s.Ciphertexts, Ciphertext{
+			Key: priv.PublicKey,
+			C:   new(big.Int).Exp(msg, priv.E, priv.N),
+		})
+	}
+	return s, nil
+}
+
+// Run plays the eavesdropper: it combines the ciphertexts with the CRT
+// into m^e modulo the product of the moduli, takes the integer e-th root
+// with HastadBroadcast and checks the result against the message, writing
+// every step to w. Fewer than e recipients make it fail, as they should.
+func (s *BroadcastScenario) Run(w io.Writer) (*big.Int, Cost, error) {
+
+	if w == nil {
+		w = io.Discard
+	}
+	if len(s.Ciphertexts) == 0 {
+		return nil, Cost{}, fmt.Errorf("BroadcastScenario: no recipients")
+	}
+	e := s.Ciphertexts[0].Key.E
+	fmt.Fprintf(w, "%v recipients, e = %v, message %v (%v bits)\n", len(s.Ciphertexts), e, s.Format.Format(s.Message), s.Message.BitLen())
+	for i, ct := range s.Ciphertexts {
+		fmt.Fprintf(w, "c%v = m^%v mod n%v = %v\n", i+1, e, i+1, s.Format.Format(ct.C))
+	}
+
+	residues, moduli := make([]*big.Int, len(s.Ciphertexts)), make([]*big.Int, len(s.Ciphertexts))
+	for i, ct := range s.Ciphertexts {
+		residues[i], moduli[i] = ct.C, ct.Key.N
+	}
+	if power, product, err := CRT(residues, moduli); err == nil {
+		fmt.Fprintf(w, "CRT: m^%v mod n1*...*n%v = %v (%v of %v bits)\n", e, len(moduli), s.Format.Format(power), power.BitLen(), product.BitLen())
+	}
+
+	m, cost, err := HastadBroadcast(s.Ciphertexts)
+	if err != nil {
+		fmt.Fprintf(w, "attack failed: %v\n", err)
+		return nil, cost, fmt.Errorf("BroadcastScenario: %v", err)
+	}
+	fmt.Fprintf(w, "integer %v-th root: m = %v\n", e, s.Format.Format(m))
+	if m.Cmp(s.Message) != 0 {
+		return nil, cost, fmt.Errorf("BroadcastScenario: recovered %v, not the message", s.Format.Format(m))
+	}
+	fmt.Fprintln(w, "recovered the message from the ciphertexts alone")
+	return m, cost, nil
+}
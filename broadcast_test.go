@@ -0,0 +1,42 @@
+package rsa_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestBroadcastScenario(t *testing.T) {
+	msg := new(big.Int).SetBytes([]byte("meet me at noon"))
+	s, err := rsa.NewBroadcastScenario(nil, msg, 3, 3, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	m, _, err := s.Run(&log)
+	if err != nil || m.Cmp(msg) != 0 {
+		t.Errorf("recovered %v (%v), expected %v", m, err, msg)
+	}
+	if !strings.Contains(log.String(), "recovered the message") {
+		t.Errorf("unexpected log:\n%v", log.String())
+	}
+
+	s.Format = rsa.FormatHex
+	log.Reset()
+	s.Run(&log)
+	if !strings.Contains(log.String(), "m = "+msg.Text(16)+"\n") {
+		t.Errorf("expected the message in hex in the log:\n%v", log.String())
+	}
+
+	// 2 recipients are not enough for e = 3.
+	s.Ciphertexts = s.Ciphertexts[:2]
+	if _, _, err := s.Run(nil); err == nil {
+		t.Error("expected the attack to fail with fewer than e recipients")
+	}
+
+	if _, err := rsa.NewBroadcastScenario(nil, msg, 3, 3, 64); err == nil {
+		t.Error("expected an error for a message larger than the moduli")
+	}
+}
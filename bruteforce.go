@@ -0,0 +1,143 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// MessageSpace is a finite, enumerable set of candidate plaintexts.
+type MessageSpace interface {
+	// Len is the number of candidates.
+	Len() int64
+	// Message returns candidate i as text and as the integer encrypted.
+	Message(i int64) (string, *big.Int, error)
+}
+
+// digitSpace holds the decimal strings of a fixed number of digits.
+type digitSpace struct {
+	digits int
+	size   int64
+}
+
+// maxSpaceDigits is the longest code whose 10^digits count fits an int64.
+const maxSpaceDigits = 18
+
+// DigitSpace returns the space of all digits long decimal codes, such as
+// the 10^6 six digit PINs, each encrypted as its numeric value, for
+// 1 <= digits <= 18.
+func DigitSpace(digits int) (MessageSpace, error) {
+
+	if digits < 1 || digits > maxSpaceDigits {
+		return nil, fmt.Errorf("DigitSpace: need 1 to %v digits, got %v", maxSpaceDigits, digits)
+	}
+	size := int64(1)
+	for i := 0; i < digits; i++ {
+		size *= 10
+	}
+	return digitSpace{digits: digits, size: size}, nil
+}
+
+// Len is 10^digits.
+func (s digitSpace) Len() int64 {
+
+	return s.size
+}
+
+// Message returns i zero padded to the number of digits.
+func (s digitSpace) Message(i int64) (string, *big.Int, error) {
+
+	return fmt.Sprintf("%0*d", s.digits, i), big.NewInt(i), nil
+}
+
+// wordSpace holds dictionary words encoded with an alphabet.
+type wordSpace struct {
+	words    []string
+	alphabet *Alphabet
+}
+
+// WordSpace returns the space of the given words, each encrypted as its
+// Encode value with alphabet.
+func WordSpace(words []string, alphabet *Alphabet) MessageSpace {
+
+	return wordSpace{words: words, alphabet: alphabet}
+}
+
+// Len is the number of words.
+func (s wordSpace) Len() int64 {
+
+	return int64(len(s.words))
+}
+
+// Message returns word i and its encoding.
+func (s wordSpace) Message(i int64) (string, *big.Int, error) {
+
+	m, err := Encode(s.words[i], s.alphabet)
+	return s.words[i], m, err
+}
+
+// BruteForceDecrypt finds the plaintext of c among the candidates of space
+// by encrypting each of them with the public key until one matches.
+// Textbook RSA is deterministic, so a plaintext from a small space, a PIN,
+// a yes/no or a dictionary word, falls to anyone holding the public key;
+// randomized padding is what closes this hole. The space is split
+// between one goroutine per CPU, stopping all of them at the first match.
+func BruteForceDecrypt(c *big.Int, pub *PublicKey, space MessageSpace) (string, Cost, error) {
+
+	meter := newCostMeter()
+	workers := runtime.NumCPU()
+	var (
+		tried    atomic.Int64
+		found    atomic.Bool
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		match    string
+		firstErr error
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(start int64) {
+
+			defer wg.Done()
+			candidate := new(big.Int)
+			count := int64(0)
+			defer func() { tried.Add(count) }()
+			for i := start; i < space.Len() && !found.Load(); i += int64(workers) {
+				count++
+				// The meter is not safe for concurrent use: one worker samples.
+				if start == 0 && count%1024 == 0 {
+					meter.sample()
+				}
+				text, m, err := space.Message(i)
+				if err != nil {
+					mu.Lock()
+					firstErr = err
+					mu.Unlock()
+					found.Store(true)
+					return
+				}
+				if candidate.Exp(m, pub.E, pub.N).Cmp(c) == 0 {
+					mu.Lock()
+					match = text
+					mu.Unlock()
+					found.Store(true)
+					return
+				}
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+
+	meter.cost.ModMuls += int(tried.Load()) * expMuls(pub.E)
+	cost := meter.done()
+	switch {
+	case firstErr != nil:
+		return "", cost, fmt.Errorf("BruteForceDecrypt: %v", firstErr)
+	case !found.Load():
+		return "", cost, fmt.Errorf("BruteForceDecrypt: plaintext not among the %v candidates", space.Len())
+	}
+	return match, cost, nil
+}
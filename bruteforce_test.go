@@ -0,0 +1,61 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestBruteForceDecrypt(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+
+	pin := big.NewInt(4711)
+	c := new(big.Int).Exp(pin, pub.E, pub.N)
+	pins, err := rsa.DigitSpace(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _, err := rsa.BruteForceDecrypt(c, pub, pins); err != nil || got != "4711" {
+		t.Errorf("PIN: got %q, %v, expected 4711", got, err)
+	}
+
+	words := []string{"ATTACK", "RETREAT", "HOLD", "ADVANCE"}
+	m, err := rsa.Encode("HOLD", rsa.ClassroomAlphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = new(big.Int).Exp(m, pub.E, pub.N)
+	if got, _, err := rsa.BruteForceDecrypt(c, pub, rsa.WordSpace(words, rsa.ClassroomAlphabet)); err != nil || got != "HOLD" {
+		t.Errorf("word: got %q, %v, expected HOLD", got, err)
+	}
+
+	codes, _ := rsa.DigitSpace(2)
+	if _, _, err := rsa.BruteForceDecrypt(c, pub, codes); err == nil {
+		t.Error("expected no match among 2 digit codes")
+	}
+	if _, _, err := rsa.BruteForceDecrypt(c, pub, rsa.WordSpace([]string{"lower"}, rsa.ClassroomAlphabet)); err == nil {
+		t.Error("expected the encoding error to surface")
+	}
+}
+
+func TestDigitSpace(t *testing.T) {
+	space, err := rsa.DigitSpace(18)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if space.Len() != 1e18 {
+		t.Errorf("18 digits: %v codes, expected 10^18", space.Len())
+	}
+	for _, digits := range []int{0, 19, -1} {
+		if _, err := rsa.DigitSpace(digits); err == nil {
+			t.Errorf("accepted %v digits", digits)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package rsa
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// challengeVersion is the bundle format SaveChallenge writes and the
+// newest LoadChallenge reads.
+const challengeVersion = 1
+
+// Challenge is an exercise to distribute: ciphertexts with the public keys
+// they were encrypted under, free-form metadata such as the course or the
+// expected attack, and hints ordered from gentle to revealing. Ciphertexts
+// feeds attacks like HastadBroadcast directly.
+type Challenge struct {
+	Title       string
+	Description string
+	Ciphertexts []Ciphertext
+	Metadata    map[string]string
+	Hints       []string
+	// Format is how SaveChallenge writes numbers, decimal by default.
+	Format NumberFormat
+}
+
+// challengeJSON is the bundle on the wire: the distinct keys are listed
+// once and ciphertexts refer to them by index.
+type challengeJSON struct {
+	Version     int                   `json:"version"`
+	Title       string                `json:"title"`
+	Description string                `json:"description,omitempty"`
+	Format      NumberFormat          `json:"format"`
+	Keys        []challengeKeyJSON    `json:"keys"`
+	Ciphertexts []challengeCipherJSON `json:"ciphertexts"`
+	Metadata    map[string]string     `json:"metadata,omitempty"`
+	Hints       []string              `json:"hints,omitempty"`
+}
+
+type challengeKeyJSON struct {
+	N string `json:"n"`
+	E string `json:"e"`
+}
+
+type challengeCipherJSON struct {
+	Key int    `json:"key"`
+	C   string `json:"c"`
+}
+
+// Keys returns the distinct public keys of the ciphertexts in order of
+// first use.
+func (ch *Challenge) Keys() []*PublicKey {
+
+	var keys []*PublicKey
+	for i := range ch.Ciphertexts {
+		if challengeKeyIndex(keys, &ch.Ciphertexts[i].Key) < 0 {
+			keys = append(keys, &ch.Ciphertexts[i].Key)
+		}
+	}
+	return keys
+}
+
+// SaveChallenge writes ch to w as an indented JSON bundle.
+func SaveChallenge(w io.Writer, ch *Challenge) error {
+
+	bundle := challengeJSON{
+		Version:     challengeVersion,
+		Title:       ch.Title,
+		Description: ch.Description,
+		Format:      ch.Format,
+		Metadata:    ch.Metadata,
+		Hints:       ch.Hints,
+	}
+	keys := ch.Keys()
+	for _, key := range keys {
+		bundle.Keys = append(bundle.Keys, challengeKeyJSON{N: ch.Format.Format(key.N), E: ch.Format.Format(key.E)})
+	}
+	for _, ct := range ch.Ciphertexts {
+		bundle.Ciphertexts = append(bundle.Ciphertexts, challengeCipherJSON{
+			Key: challengeKeyIndex(keys, &ct.Key),
+			C:   ch.Format.Format(ct.C),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return fmt.Errorf("SaveChallenge: %v", err)
+	}
+	return nil
+}
+
+// LoadChallenge reads a bundle written by SaveChallenge, checking that every
+// ciphertext refers to a key and lies below its modulus.
+func LoadChallenge(r io.Reader) (*Challenge, error) {
+
+	var bundle challengeJSON
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("LoadChallenge: %v", err)
+	}
+	if bundle.Version < 1 || bundle.Version > challengeVersion {
+		return nil, fmt.Errorf("LoadChallenge: unsupported bundle version %v", bundle.Version)
+	}
+
+	parse := func(what, s string) (*big.Int, error) {
+		x, err := bundle.Format.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("LoadChallenge: %v: %v", what, err)
+		}
+		return x, nil
+	}
+	keys := make([]PublicKey, len(bundle.Keys))
+	for i, key := range bundle.Keys {
+		var err error
+		if keys[i].N, err = parse("key n", key.N); err != nil {
+			return nil, err
+		}
+		if keys[i].E, err = parse("key e", key.E); err != nil {
+			return nil, err
+		}
+	}
+
+	ch := &Challenge{
+		Title:       bundle.Title,
+		Description: bundle.Description,
+		Metadata:    bundle.Metadata,
+		Hints:       bundle.Hints,
+		Format:      bundle.Format,
+	}
+	for i, ct := range bundle.Ciphertexts {
+		if ct.Key < 0 || ct.Key >= len(keys) {
+			return nil, fmt.Errorf("LoadChallenge: ciphertext %v refers to missing key %v", i, ct.Key)
+		}
+		c, err := parse("ciphertext", ct.C)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sign() < 0 || c.Cmp(keys[ct.Key].N) >= 0 {
+			return nil, fmt.Errorf("LoadChallenge: ciphertext %v is out of range [0, n)", i)
+		}
+		ch.Ciphertexts = append(ch.Ciphertexts, Ciphertext{Key: keys[ct.Key], C: c})
+	}
+	return ch, nil
+}
+
+// challengeKeyIndex returns the index of the key equal to key, or -1.
+func challengeKeyIndex(keys []*PublicKey, key *PublicKey) int {
+
+	for i, k := range keys {
+		if k.Equal(key) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,64 @@
+package rsa_test
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestChallengeRoundTrip(t *testing.T) {
+	msg := new(big.Int).SetBytes([]byte("attack at dawn"))
+	scenario, err := rsa.NewBroadcastScenario(nil, msg, 3, 3, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := &rsa.Challenge{
+		Title:       "Broadcast",
+		Description: "The same message went to three recipients.",
+		Ciphertexts: scenario.Ciphertexts,
+		Metadata:    map[string]string{"attack": "hastad"},
+		Hints:       []string{"Look at e.", "Combine the ciphertexts with the CRT."},
+		Format:      rsa.FormatBase64URL,
+	}
+
+	var buf bytes.Buffer
+	if err := rsa.SaveChallenge(&buf, ch); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := rsa.LoadChallenge(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Title != ch.Title || loaded.Metadata["attack"] != "hastad" || len(loaded.Hints) != 2 || len(loaded.Keys()) != 3 {
+		t.Errorf("loaded %+v, expected %+v", loaded, ch)
+	}
+	// Straight into the attack.
+	if m, _, err := rsa.HastadBroadcast(loaded.Ciphertexts); err != nil || m.Cmp(msg) != 0 {
+		t.Errorf("recovered %v (%v) from the loaded challenge, expected %v", m, err, msg)
+	}
+
+	// One key shared by several ciphertexts is stored once.
+	ch.Ciphertexts = []rsa.Ciphertext{scenario.Ciphertexts[0], scenario.Ciphertexts[0]}
+	buf.Reset()
+	rsa.SaveChallenge(&buf, ch)
+	if loaded, err := rsa.LoadChallenge(&buf); err != nil || len(loaded.Keys()) != 1 || len(loaded.Ciphertexts) != 2 {
+		t.Errorf("loaded %v keys and %v ciphertexts (%v), expected 1 and 2", len(loaded.Keys()), len(loaded.Ciphertexts), err)
+	}
+}
+
+func TestLoadChallengeRejects(t *testing.T) {
+	for _, bundle := range []string{
+		`not json`,
+		`{"version": 2, "keys": [], "ciphertexts": []}`,
+		`{"version": 1, "keys": [{"n": "77", "e": "7"}], "ciphertexts": [{"key": 1, "c": "5"}]}`,
+		`{"version": 1, "keys": [{"n": "77", "e": "7"}], "ciphertexts": [{"key": 0, "c": "77"}]}`,
+		`{"version": 1, "format": "hex", "keys": [{"n": "xyz", "e": "7"}], "ciphertexts": []}`,
+	} {
+		if _, err := rsa.LoadChallenge(strings.NewReader(bundle)); err == nil {
+			t.Errorf("expected an error loading %v", bundle)
+		}
+	}
+}
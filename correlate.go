@@ -0,0 +1,181 @@
+package rsa
+
+import (
+	"math/big"
+	"sort"
+)
+
+// maxHastadExponent bounds the public exponents CorrelateCiphertexts
+// tries Håstad's attack for, and so the size of the subsets it combines.
+const maxHastadExponent = 17
+
+// maxHastadSubsets bounds the subsets of e ciphertexts CorrelateCiphertexts
+// combines per exponent e; past it only sliding windows are tried.
+const maxHastadSubsets = 1 << 12
+
+// Names of the attacks CorrelateCiphertexts routes a group to.
+const (
+	AttackNone           = "none"
+	AttackCommonModulus  = "common modulus"
+	AttackHastad         = "Håstad broadcast"
+	AttackReEncryptMatch = "re-encryption"
+)
+
+// PlaintextGroup is a set of ciphertexts found to hide the same plaintext.
+type PlaintextGroup struct {
+	// Indexes of the ciphertexts in the input, ascending.
+	Indexes []int
+	// Attack that exposed the group, AttackNone for plain duplicates.
+	Attack string
+	// Plaintext is the recovered message, nil if only equality is known.
+	Plaintext *big.Int
+}
+
+// CorrelateCiphertexts detects ciphertexts of the same plaintext among
+// ciphertexts under many public keys, which deterministic RSA allows, and
+// routes each group to the attack that recovers the plaintext:
+//   - identical ciphertexts under the same key are duplicates, nothing more;
+//   - the same modulus with coprime exponents yields to CommonModulusAttack;
+//   - e ciphertexts under different moduli with the same small e yield to
+//     HastadBroadcast, trying every subset of e of them while there are at
+//     most maxHastadSubsets, and only the windows of e consecutive ones
+//     otherwise, so many unrelated ciphertexts take linear time.
+//
+// A recovered plaintext is then re-encrypted under every other key to pick
+// up the remaining ciphertexts of the group.
+func CorrelateCiphertexts(cts []Ciphertext) []PlaintextGroup {
+
+	grouped := make([]bool, len(cts))
+	groups := []PlaintextGroup{}
+
+	recovered := func(indexes []int, attack string, m *big.Int) {
+
+		group := PlaintextGroup{Attack: attack, Plaintext: m}
+		for i, ct := range cts {
+			if grouped[i] {
+				continue
+			}
+			if new(big.Int).Exp(m, ct.Key.E, ct.Key.N).Cmp(ct.C) == 0 {
+				group.Indexes = append(group.Indexes, i)
+				grouped[i] = true
+			}
+		}
+		if len(group.Indexes) > len(indexes) {
+			group.Attack += " + " + AttackReEncryptMatch
+		}
+		groups = append(groups, group)
+	}
+
+	// Same modulus, coprime exponents.
+	for i := range cts {
+		for j := i + 1; j < len(cts) && !grouped[i]; j++ {
+			a, b := cts[i], cts[j]
+			if grouped[j] || a.Key.N.Cmp(b.Key.N) != 0 || a.Key.E.Cmp(b.Key.E) == 0 {
+				continue
+			}
+			m, _, err := CommonModulusAttack(a.Key.N, a.Key.E, a.C, b.Key.E, b.C)
+			if err != nil || new(big.Int).Exp(m, a.Key.E, a.Key.N).Cmp(a.C) != 0 ||
+				new(big.Int).Exp(m, b.Key.E, b.Key.N).Cmp(b.C) != 0 {
+				continue
+			}
+			recovered([]int{i, j}, AttackCommonModulus, m)
+		}
+	}
+
+	// Same small exponent, distinct moduli.
+	byE := map[int64][]int{}
+	for i, ct := range cts {
+		if !grouped[i] && ct.Key.E.IsInt64() && ct.Key.E.Int64() <= maxHastadExponent {
+			byE[ct.Key.E.Int64()] = append(byE[ct.Key.E.Int64()], i)
+		}
+	}
+	exponents := []int64{}
+	for e := range byE {
+		exponents = append(exponents, e)
+	}
+	sort.Slice(exponents, func(i, j int) bool { return exponents[i] < exponents[j] })
+	hastad := func(subset []int) bool {
+
+		subsetCts := make([]Ciphertext, len(subset))
+		for k, i := range subset {
+			if grouped[i] {
+				return true
+			}
+			subsetCts[k] = cts[i]
+		}
+		m, _, err := HastadBroadcast(subsetCts)
+		if err != nil {
+			return true
+		}
+		recovered(subset, AttackHastad, m)
+		return true
+	}
+	for _, e := range exponents {
+		candidates, k := byE[e], int(e)
+		if binomialAtMost(len(candidates), k, maxHastadSubsets) {
+			forEachSubset(candidates, k, hastad)
+			continue
+		}
+		for i := 0; i+k <= len(candidates); i++ {
+			hastad(candidates[i : i+k])
+		}
+	}
+
+	// Whatever is left can still be compared for equality under the same key.
+	for i := range cts {
+		if grouped[i] {
+			continue
+		}
+		group := PlaintextGroup{Indexes: []int{i}, Attack: AttackNone}
+		for j := i + 1; j < len(cts); j++ {
+			if !grouped[j] && cts[j].Key.Equal(&cts[i].Key) && cts[j].C.Cmp(cts[i].C) == 0 {
+				group.Indexes = append(group.Indexes, j)
+				grouped[j] = true
+			}
+		}
+		if len(group.Indexes) > 1 {
+			grouped[i] = true
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// forEachSubset calls f with every size k subset of items in lexicographic
+// order until f returns false.
+func forEachSubset(items []int, k int, f func([]int) bool) {
+
+	subset := make([]int, 0, k)
+	var walk func(start int) bool
+	walk = func(start int) bool {
+
+		if len(subset) == k {
+			return f(subset)
+		}
+		for i := start; i <= len(items)-(k-len(subset)); i++ {
+			subset = append(subset, items[i])
+			if !walk(i + 1) {
+				return false
+			}
+			subset = subset[:len(subset)-1]
+		}
+		return true
+	}
+	walk(0)
+}
+
+// binomialAtMost reports whether n choose k is at most limit, without
+// computing it in full when it is not.
+func binomialAtMost(n, k int, limit int64) bool {
+
+	k = min(k, n-k)
+	count := int64(1)
+	for i := 1; i <= k; i++ {
+		// count * (n-k+i) / i stays exact as it is C(n-k+i, i).
+		count = count * int64(n-k+i) / int64(i)
+		if count > limit {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package rsa
+
+import (
+	"math/big"
+	"math/bits"
+	"runtime"
+	"time"
+)
+
+// Cost is the empirical cost of an attack run, to hold against the
+// attack's theoretical complexity.
+type Cost struct {
+	// OracleQueries counts the questions asked to an Oracle.
+	OracleQueries int
+	// ModMuls counts modular multiplications and squarings, an
+	// exponentiation counting as its square and multiply steps.
+	ModMuls int
+	// Duration is the wall time of the run.
+	Duration time.Duration
+	// PeakHeap is the high-water mark of heap bytes in use above the
+	// level at the start, sampled at points of the run, so a lower bound.
+	PeakHeap uint64
+}
+
+// costMeter accumulates a Cost while an attack runs.
+type costMeter struct {
+	cost  Cost
+	start time.Time
+	base  uint64
+}
+
+// newCostMeter starts measuring.
+func newCostMeter() *costMeter {
+
+	meter := &costMeter{start: time.Now(), base: heapInUse()}
+	return meter
+}
+
+// exp accounts for an exponentiation by exp with square and multiply.
+func (m *costMeter) exp(exp *big.Int) {
+
+	m.cost.ModMuls += expMuls(exp)
+}
+
+// expMuls is the number of squarings and multiplications square and
+// multiply needs for exponent exp > 0: 1 less than its bit length and
+// 1 less than its Hamming weight.
+func expMuls(exp *big.Int) int {
+
+	if exp.Sign() == 0 {
+		return 0
+	}
+	ones := 0
+	for _, word := range exp.Bits() {
+		ones += bits.OnesCount(uint(word))
+	}
+	return exp.BitLen() - 1 + ones - 1
+}
+
+// sample records the heap in use for PeakHeap.
+func (m *costMeter) sample() {
+
+	if used := heapInUse(); used > m.base && used-m.base > m.cost.PeakHeap {
+		m.cost.PeakHeap = used - m.base
+	}
+}
+
+// done takes a last heap sample and returns the final Cost.
+func (m *costMeter) done() Cost {
+
+	m.sample()
+	m.cost.Duration = time.Since(m.start)
+	return m.cost
+}
+
+// heapInUse returns the bytes of allocated heap objects.
+func heapInUse() uint64 {
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
@@ -0,0 +1,39 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestAttackCost(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := big.NewInt(123456)
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+
+	// r^e with e = 65537 takes 16 squarings and 1 multiplication,
+	// then blinding and unblinding multiply once each.
+	_, cost, err := rsa.BlindingAttack(&priv.PublicKey, c, rsa.NewDecryptionOracle(priv), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost.OracleQueries != 1 || cost.ModMuls != 19 || cost.Duration <= 0 {
+		t.Errorf("BlindingAttack cost %+v, expected 1 query and 19 multiplications", cost)
+	}
+
+	// 6 = 1 * 6: 1 table exponentiation, then 6 lookups of an
+	// exponentiation and a multiplication each.
+	_, cost, err = rsa.MeetInTheMiddle(new(big.Int).Exp(big.NewInt(6), priv.E, priv.N), &priv.PublicKey, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost.OracleQueries != 0 || cost.ModMuls != 17+6*18 {
+		t.Errorf("MeetInTheMiddle cost %+v, expected %v multiplications", cost, 17+6*18)
+	}
+}
@@ -0,0 +1,95 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// CRTOptions selects how DecryptCRT and SignCRT guard against faults.
+// The zero value verifies every result.
+type CRTOptions struct {
+	// DisableFaultCheck releases the result without re-encrypting it with e,
+	// which is what makes the Bellcore attack possible.
+	DisableFaultCheck bool
+	// Fault, if not nil, is applied to the half result modulo p before
+	// recombination, simulating a glitch in the hardware computing it.
+	Fault func(mp *big.Int)
+}
+
+// DecryptCRT returns c^d mod n computed as c^dp mod p and c^dq mod q
+// recombined with Garner's formula, about 4 times faster than Decrypt.
+// Unless opts disables it, the result is checked against c with the public
+// exponent before it is released, Lenstra's countermeasure against
+// faulty halves leaking the factors.
+// https://en.wikipedia.org/wiki/RSA_(cryptosystem)#Using_the_Chinese_remainder_algorithm
+func DecryptCRT(priv *PrivateKey, c *big.Int, opts *CRTOptions) (*big.Int, error) {
+
+	if c.Sign() < 0 || c.Cmp(priv.N) >= 0 {
+		return nil, fmt.Errorf("DecryptCRT: ciphertext out of range [0, n)")
+	}
+	m, err := crtExp(priv, c, opts)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptCRT: %v", err)
+	}
+	return m, nil
+}
+
+// SignCRT returns the textbook signature m^d mod n computed like DecryptCRT.
+func SignCRT(priv *PrivateKey, m *big.Int, opts *CRTOptions) (*big.Int, error) {
+
+	if m.Sign() < 0 || m.Cmp(priv.N) >= 0 {
+		return nil, fmt.Errorf("SignCRT: message out of range [0, n)")
+	}
+	s, err := crtExp(priv, m, opts)
+	if err != nil {
+		return nil, fmt.Errorf("SignCRT: %v", err)
+	}
+	return s, nil
+}
+
+// BellcoreAttack factors n from a signature s of m computed with a fault in
+// one CRT half: s^e = m holds modulo the intact prime only, so
+// gcd(s^e - m, n) is that prime.
+// https://link.springer.com/chapter/10.1007/3-540-69053-0_4
+func BellcoreAttack(pub *PublicKey, m, s *big.Int) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	diff := new(big.Int).Exp(s, pub.E, pub.N)
+	meter.exp(pub.E)
+	diff.Sub(diff, m)
+	p := GetGcd(new(big.Int), diff.Mod(diff, pub.N), pub.N)
+	if p.Cmp(big.NewInt(1)) == 0 || p.Cmp(pub.N) == 0 {
+		return nil, meter.done(), fmt.Errorf("BellcoreAttack: the signature is not faulty in exactly one half")
+	}
+	return p, meter.done(), nil
+}
+
+// crtExp is x^d mod n by the CRT with the optional fault and check.
+func crtExp(priv *PrivateKey, x *big.Int, opts *CRTOptions) (*big.Int, error) {
+
+	if opts == nil {
+		opts = &CRTOptions{}
+	}
+	if priv.P == nil || priv.Q == nil || priv.Dp == nil || priv.Dq == nil || priv.Qinv == nil {
+		return nil, fmt.Errorf("private key lacks the CRT parameters")
+	}
+
+	mp := new(big.Int).Exp(x, priv.Dp, priv.P)
+	mq := new(big.Int).Exp(x, priv.Dq, priv.Q)
+	if opts.Fault != nil {
+		opts.Fault(mp)
+	}
+
+	// m = mq + q * (qInv * (mp - mq) mod p)
+	h := mp.Sub(mp, mq)
+	h.Mul(h, priv.Qinv)
+	h.Mod(h, priv.P)
+	m := h.Mul(h, priv.Q)
+	m.Add(m, mq)
+	zeroizeInt(mq)
+
+	if !opts.DisableFaultCheck && new(big.Int).Exp(m, priv.E, priv.N).Cmp(x) != 0 {
+		return nil, fmt.Errorf("fault detected, result withheld")
+	}
+	return m, nil
+}
@@ -0,0 +1,76 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestDecryptCRT(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := big.NewInt(888888)
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+
+	for _, opts := range []*rsa.CRTOptions{nil, {DisableFaultCheck: true}} {
+		got, err := rsa.DecryptCRT(priv, c, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(m) != 0 {
+			t.Errorf("%+v: decrypted %v, expected %v", opts, got, m)
+		}
+	}
+
+	for i, drop := range []func(*rsa.PrivateKey){
+		func(k *rsa.PrivateKey) { k.P = nil },
+		func(k *rsa.PrivateKey) { k.Q = nil },
+		func(k *rsa.PrivateKey) { k.Dp = nil },
+		func(k *rsa.PrivateKey) { k.Dq = nil },
+		func(k *rsa.PrivateKey) { k.Qinv = nil },
+	} {
+		partial := *priv
+		drop(&partial)
+		if _, err := rsa.DecryptCRT(&partial, c, nil); err == nil {
+			t.Errorf("expected an error for a key lacking CRT parameter %v", i)
+		}
+	}
+}
+
+func TestSignCRTFault(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := big.NewInt(424242)
+	glitch := func(mp *big.Int) { mp.Add(mp, big.NewInt(1)) }
+
+	if _, err := rsa.SignCRT(priv, m, &rsa.CRTOptions{Fault: glitch}); err == nil {
+		t.Fatal("faulty signature released despite the check")
+	}
+
+	s, err := rsa.SignCRT(priv, m, &rsa.CRTOptions{Fault: glitch, DisableFaultCheck: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, _, err := rsa.BellcoreAttack(&priv.PublicKey, m, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Cmp(priv.Q) != 0 {
+		t.Errorf("BellcoreAttack found %v, expected q = %v", q, priv.Q)
+	}
+
+	good, err := rsa.SignCRT(priv, m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rsa.BellcoreAttack(&priv.PublicKey, m, good); err == nil {
+		t.Error("BellcoreAttack succeeded on a correct signature")
+	}
+}
@@ -0,0 +1,252 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+)
+
+// ExpMode selects the modular exponentiation algorithm of ModExp.
+type ExpMode int
+
+const (
+	// ExpSquareMultiply is textbook left-to-right square and multiply:
+	// it multiplies only for the 1 bits of the exponent, so its running
+	// time reveals the exponent's Hamming weight.
+	ExpSquareMultiply ExpMode = iota
+	// ExpConstantTime is a fixed 4-bit window exponentiation that always
+	// runs the exponent padded to the modulus size, always multiplies,
+	// even by base^0, and selects table entries by scanning all of them.
+	// math/big itself is not constant time, so this shows the algorithmic
+	// countermeasure rather than providing a hardened implementation.
+	ExpConstantTime
+)
+
+// ctWindowBits is the window size of ExpConstantTime.
+const ctWindowBits = 4
+
+// String names the exponentiation mode.
+func (mode ExpMode) String() string {
+
+	switch mode {
+	case ExpSquareMultiply:
+		return "square-and-multiply"
+	case ExpConstantTime:
+		return "constant-time"
+	}
+	return fmt.Sprintf("ExpMode(%d)", int(mode))
+}
+
+// ModExp sets z to base^exp mod modulus for a nonnegative exp with the
+// algorithm selected by mode and returns z.
+func ModExp(z, base, exp, modulus *big.Int, mode ExpMode) *big.Int {
+
+	return z.Set(modExpObserved(base, exp, modulus, mode, nil))
+}
+
+// Operations ModExp reports to an observer.
+const (
+	opSquare   = 'S'
+	opMultiply = 'M'
+)
+
+// expObserver is called after every squaring or multiplication
+// with the reduced intermediate result.
+type expObserver func(op byte, value *big.Int)
+
+// modExpObserved is ModExp reporting every operation to observe, if not nil.
+func modExpObserved(base, exp, modulus *big.Int, mode ExpMode, observe expObserver) *big.Int {
+
+	if observe == nil {
+		observe = func(byte, *big.Int) {}
+	}
+	switch mode {
+	case ExpConstantTime:
+		return expConstantTime(base, exp, modulus, observe)
+	default:
+		return expSquareMultiply(base, exp, modulus, observe)
+	}
+}
+
+// expSquareMultiply is the leaky left-to-right binary method.
+func expSquareMultiply(base, exp, modulus *big.Int, observe expObserver) *big.Int {
+
+	b := new(big.Int).Mod(base, modulus)
+	result := big.NewInt(1)
+	quotient := new(big.Int)
+
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		result.Mul(result, result)
+		quotient.DivMod(result, modulus, result)
+		observe(opSquare, result)
+		if exp.Bit(i) == 1 {
+			result.Mul(result, b)
+			quotient.DivMod(result, modulus, result)
+			observe(opMultiply, result)
+		}
+	}
+	return result.Mod(result, modulus)
+}
+
+// expConstantTime is the fixed window, always multiply method.
+func expConstantTime(base, exp, modulus *big.Int, observe expObserver) *big.Int {
+
+	words := len(modulus.Bits())
+	quotient := new(big.Int)
+
+	// table[i] = base^i mod modulus, stored as fixed length words.
+	table := make([][]big.Word, 1<<ctWindowBits)
+	entry := big.NewInt(1)
+	b := new(big.Int).Mod(base, modulus)
+	for i := range table {
+		table[i] = make([]big.Word, words)
+		copy(table[i], entry.Bits())
+		entry.Mul(entry, b)
+		quotient.DivMod(entry, modulus, entry)
+	}
+
+	// Every exponent is processed with as many windows as the modulus needs.
+	windows := (max(modulus.BitLen(), exp.BitLen()) + ctWindowBits - 1) / ctWindowBits
+	result := big.NewInt(1)
+	selected := make([]big.Word, words)
+	factor := new(big.Int)
+
+	for w := windows - 1; w >= 0; w-- {
+		for i := 0; i < ctWindowBits; i++ {
+			result.Mul(result, result)
+			quotient.DivMod(result, modulus, result)
+			observe(opSquare, result)
+		}
+
+		digit := 0
+		for i := ctWindowBits - 1; i >= 0; i-- {
+			digit = digit<<1 | int(exp.Bit(w*ctWindowBits+i))
+		}
+		// Touch every entry, keeping only the one matching digit.
+		clear(selected)
+		for i, candidate := range table {
+			mask := -big.Word(subtle.ConstantTimeEq(int32(i), int32(digit)))
+			for j := range selected {
+				selected[j] |= candidate[j] & mask
+			}
+		}
+
+		result.Mul(result, factor.SetBits(append([]big.Word(nil), selected...)))
+		quotient.DivMod(result, modulus, result)
+		observe(opMultiply, result)
+	}
+	return result.Mod(result, modulus)
+}
+
+// TimingReport summarizes the running times of one ExpMode over exponents
+// of varying Hamming weight.
+type TimingReport struct {
+	Mode    ExpMode
+	Samples int
+	Mean    time.Duration
+	StdDev  time.Duration
+	// WeightCorrelation is Pearson's correlation between the Hamming
+	// weight of the exponent and the running time: close to 1 when the
+	// time leaks the weight, close to 0 when it does not.
+	WeightCorrelation float64
+}
+
+// MeasureExpTiming times samples exponentiations modulo modulus with the
+// given mode, each with a random base and a random full length exponent
+// of uniformly drawn Hamming weight, and reports how much the running
+// time varies with the weight.
+func MeasureExpTiming(modulus *big.Int, mode ExpMode, samples int, random io.Reader) (TimingReport, error) {
+
+	if samples < 2 {
+		return TimingReport{}, fmt.Errorf("MeasureExpTiming: need at least 2 samples, got %v", samples)
+	}
+
+	bitLen := modulus.BitLen()
+	weights := make([]float64, samples)
+	times := make([]float64, samples)
+	z := new(big.Int)
+
+	for s := 0; s < samples; s++ {
+		base, err := rand.Int(random, modulus)
+		if err != nil {
+			return TimingReport{}, err
+		}
+		weight, err := rand.Int(random, big.NewInt(int64(bitLen)))
+		if err != nil {
+			return TimingReport{}, err
+		}
+		exp, err := randomExpOfWeight(random, bitLen, int(weight.Int64())+1)
+		if err != nil {
+			return TimingReport{}, err
+		}
+
+		start := time.Now()
+		ModExp(z, base, exp, modulus, mode)
+		times[s] = float64(time.Since(start))
+		weights[s] = float64(weight.Int64() + 1)
+	}
+
+	mean, stdDev := meanStdDev(times)
+	return TimingReport{
+		Mode:              mode,
+		Samples:           samples,
+		Mean:              time.Duration(mean),
+		StdDev:            time.Duration(stdDev),
+		WeightCorrelation: correlation(weights, times),
+	}, nil
+}
+
+// randomExpOfWeight returns a bitLen bit exponent with exactly weight 1 bits,
+// the top one always set.
+func randomExpOfWeight(random io.Reader, bitLen, weight int) (*big.Int, error) {
+
+	exp := new(big.Int).SetBit(new(big.Int), bitLen-1, 1)
+	// Partial Fisher-Yates shuffle of the lower bit positions.
+	positions := make([]int, bitLen-1)
+	for i := range positions {
+		positions[i] = i
+	}
+	for i := 0; i < weight-1; i++ {
+		j, err := rand.Int(random, big.NewInt(int64(len(positions)-i)))
+		if err != nil {
+			return nil, err
+		}
+		k := i + int(j.Int64())
+		positions[i], positions[k] = positions[k], positions[i]
+		exp.SetBit(exp, positions[i], 1)
+	}
+	return exp, nil
+}
+
+// meanStdDev returns the mean and the sample standard deviation.
+func meanStdDev(xs []float64) (float64, float64) {
+
+	var sum, sumSq float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+	for _, x := range xs {
+		sumSq += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(sumSq / float64(len(xs)-1))
+}
+
+// correlation is Pearson's correlation coefficient of xs and ys.
+func correlation(xs, ys []float64) float64 {
+
+	meanX, sdX := meanStdDev(xs)
+	meanY, sdY := meanStdDev(ys)
+	if sdX == 0 || sdY == 0 {
+		return 0
+	}
+	var cov float64
+	for i := range xs {
+		cov += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	return cov / float64(len(xs)-1) / (sdX * sdY)
+}
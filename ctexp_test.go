@@ -0,0 +1,49 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestModExpModes(t *testing.T) {
+	modulus, err := rand.Prime(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		base, _ := rand.Int(rand.Reader, modulus)
+		exp, _ := rand.Int(rand.Reader, modulus)
+		if i == 0 {
+			exp.SetInt64(0)
+		}
+		expected := new(big.Int).Exp(base, exp, modulus)
+		for _, mode := range []rsa.ExpMode{rsa.ExpSquareMultiply, rsa.ExpConstantTime} {
+			if got := rsa.ModExp(new(big.Int), base, exp, modulus, mode); got.Cmp(expected) != 0 {
+				t.Errorf("%v: %v ^ %v = %v, expected %v", mode, base, exp, got, expected)
+			}
+		}
+	}
+}
+
+func TestMeasureExpTiming(t *testing.T) {
+	modulus, err := rand.Prime(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Timings are too noisy for assertions, report them for inspection with -v.
+	for _, mode := range []rsa.ExpMode{rsa.ExpSquareMultiply, rsa.ExpConstantTime} {
+		report, err := rsa.MeasureExpTiming(modulus, mode, 50, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Samples != 50 || report.Mean <= 0 {
+			t.Errorf("unexpected report %+v", report)
+		}
+		t.Logf("%v: mean %v, stddev %v, weight correlation %.2f", mode, report.Mean, report.StdDev, report.WeightCorrelation)
+	}
+}
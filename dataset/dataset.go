@@ -0,0 +1,82 @@
+// Package dataset ships a curated set of semiprimes n = p * q of graduated
+// sizes, from 40 to 220 bits in steps of 20, with their known balanced
+// prime factors, so that benchmarks, examples and exercises work on the
+// same reproducible targets.
+package dataset
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Semiprime is a dataset entry: N has exactly Bits bits and is the
+// product of the primes P and Q of about Bits/2 bits each.
+type Semiprime struct {
+	Bits    int
+	N, P, Q *big.Int
+}
+
+// entry is the decimal source form of a Semiprime.
+type entry struct {
+	Bits    int
+	N, P, Q string
+}
+
+// entries were drawn with crypto/rand.Prime and checked by TestDataset.
+var entries = []entry{
+	{Bits: 40, N: "667452697609", P: "822299", Q: "811691"},
+	{Bits: 60, N: "1076388042244307711", P: "1016031869", Q: "1059403819"},
+	{Bits: 80, N: "847677557336931190685293", P: "889313794043", Q: "953181613751"},
+	{Bits: 100, N: "1062836196550865511074233729391", P: "953786829005317", Q: "1114333060836323"},
+	{Bits: 120, N: "772350007699672652543095257895533437", P: "889359346379825887", Q: "868434127154063651"},
+	{Bits: 140, N: "960297138845377081900378465957876583679707", P: "1016977158333583699873", Q: "944266182358429406459"},
+	{Bits: 160, N: "945999434273469206139780146709274156735543070269", P: "916024364426719534860647", Q: "1032723004988529023242427"},
+	{Bits: 180, N: "940683163185797451885061349313507544365936859983016103", P: "934449026655851604225147319", Q: "1006671457032018311358318737"},
+	{Bits: 200, N: "1146493148583224423688280898435396247355195980098943476495483", P: "1117664490360777448432202909047", Q: "1025793660325686113815828584989"},
+	{Bits: 220, N: "1297252133839656827335953499703447610905037824825773351603241959243", P: "1023185464218612303152765756017513", Q: "1267856297030513563057592306328211"},
+}
+
+// Sizes lists the available bit sizes in increasing order.
+func Sizes() []int {
+
+	sizes := make([]int, len(entries))
+	for i, e := range entries {
+		sizes[i] = e.Bits
+	}
+	return sizes
+}
+
+// Get returns the semiprime of exactly bits bits.
+// The numbers are fresh copies the caller may modify.
+func Get(bits int) (Semiprime, error) {
+
+	for _, e := range entries {
+		if e.Bits == bits {
+			return e.semiprime(), nil
+		}
+	}
+	return Semiprime{}, fmt.Errorf("Get: no %v bit semiprime in the dataset, available sizes are %v", bits, Sizes())
+}
+
+// All returns every semiprime in increasing size.
+func All() []Semiprime {
+
+	all := make([]Semiprime, len(entries))
+	for i, e := range entries {
+		all[i] = e.semiprime()
+	}
+	return all
+}
+
+// semiprime parses the decimal entry.
+func (e entry) semiprime() Semiprime {
+
+	parse := func(s string) *big.Int {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			panic(fmt.Sprintf("dataset: bad number %q in the %v bit entry", s, e.Bits))
+		}
+		return n
+	}
+	return Semiprime{Bits: e.Bits, N: parse(e.N), P: parse(e.P), Q: parse(e.Q)}
+}
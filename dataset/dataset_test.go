@@ -0,0 +1,36 @@
+package dataset_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa/dataset"
+)
+
+func TestDataset(t *testing.T) {
+	for _, s := range dataset.All() {
+		if s.N.BitLen() != s.Bits {
+			t.Errorf("%v bit entry has a %v bit n", s.Bits, s.N.BitLen())
+		}
+		if new(big.Int).Mul(s.P, s.Q).Cmp(s.N) != 0 {
+			t.Errorf("%v bit entry: p * q != n", s.Bits)
+		}
+		if !s.P.ProbablyPrime(20) || !s.Q.ProbablyPrime(20) {
+			t.Errorf("%v bit entry: factors are not prime", s.Bits)
+		}
+	}
+
+	if _, err := dataset.Get(41); err == nil {
+		t.Error("expected an error for a size missing from the dataset")
+	}
+}
+
+func ExampleGet() {
+	s, err := dataset.Get(40)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(s.N, "=", s.P, "*", s.Q)
+	// Output: 667452697609 = 822299 * 811691
+}
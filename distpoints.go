@@ -0,0 +1,256 @@
+package rsa
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DistinguishedPoint is a point with its DistinguishedBits low bits 0
+// that a walk of a CollisionSearch reached, with the walk's State there
+// and the Steps it took.
+type DistinguishedPoint struct {
+	Point *big.Int
+	State []byte
+	Steps int64
+}
+
+// PointStore holds the distinguished points of a CollisionSearch, shared
+// by all its workers.
+type PointStore interface {
+	// Add records p, unless a point equal to p.Point is stored already, in
+	// which case it returns that earlier point and true instead.
+	Add(p DistinguishedPoint) (DistinguishedPoint, bool, error)
+}
+
+// memoryPointStore is a PointStore in a map.
+type memoryPointStore struct {
+	mu     sync.Mutex
+	points map[string]DistinguishedPoint
+}
+
+// NewMemoryPointStore returns an empty PointStore held in memory.
+func NewMemoryPointStore() PointStore {
+
+	return &memoryPointStore{points: map[string]DistinguishedPoint{}}
+}
+
+func (s *memoryPointStore) Add(p DistinguishedPoint) (DistinguishedPoint, bool, error) {
+
+	key := string(p.Point.Bytes())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if earlier, ok := s.points[key]; ok {
+		return earlier, true, nil
+	}
+	s.points[key] = p
+	return p, false, nil
+}
+
+// diskPointStore is a PointStore in the files of a directory.
+type diskPointStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewDiskPointStore returns a PointStore keeping its points in dir,
+// created if missing, for searches whose points outgrow memory. Points
+// already in dir are kept, so a search interrupted can be resumed with
+// the same walks; a search with different walks needs a new directory.
+// Each point is a line "point state steps" in hexadecimal, in one of 256
+// files chosen by the first byte of the SHA-256 of the point, so an Add
+// reads only 1/256 of the points.
+func NewDiskPointStore(dir string) (PointStore, error) {
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("NewDiskPointStore: %v", err)
+	}
+	return &diskPointStore{dir: dir}, nil
+}
+
+func (s *diskPointStore) Add(p DistinguishedPoint) (DistinguishedPoint, bool, error) {
+
+	point := p.Point.Text(16)
+	sum := sha256.Sum256([]byte(point))
+	path := filepath.Join(s.dir, fmt.Sprintf("points-%02x", sum[0]))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return DistinguishedPoint{}, false, fmt.Errorf("PointStore: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != point {
+			continue
+		}
+		earlier := DistinguishedPoint{Point: new(big.Int).Set(p.Point)}
+		state, err := hex.DecodeString(fields[1])
+		if err != nil {
+			return DistinguishedPoint{}, false, fmt.Errorf("PointStore: %v: %v", path, err)
+		}
+		earlier.State = state
+		if earlier.Steps, err = strconv.ParseInt(fields[2], 16, 64); err != nil {
+			return DistinguishedPoint{}, false, fmt.Errorf("PointStore: %v: %v", path, err)
+		}
+		return earlier, true, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return DistinguishedPoint{}, false, fmt.Errorf("PointStore: %v", err)
+	}
+	if _, err := fmt.Fprintf(file, "%v %x %x\n", point, p.State, p.Steps); err != nil {
+		return DistinguishedPoint{}, false, fmt.Errorf("PointStore: %v", err)
+	}
+	return p, false, nil
+}
+
+// CollisionWalk is one walk of a CollisionSearch: a deterministic
+// function of the point, so that two walks meeting at a point continue
+// together, carrying state that gives the collision its meaning.
+type CollisionWalk interface {
+	// Point is the current point.
+	Point() *big.Int
+	// Step moves to the next point.
+	Step()
+	// State encodes what the walk knows about the current point.
+	State() []byte
+}
+
+// CollisionSearch finds two walks of a random mapping that meet, by van
+// Oorschot and Wiener's parallel collision search: each worker walks from
+// random starts until a distinguished point, 1 in 2^DistinguishedBits,
+// and stores it. Two walks that meet anywhere reach the same
+// distinguished point after it, so comparing distinguished points alone
+// detects every collision, across any number of workers, about
+// 2^DistinguishedBits steps late. As all workers search the one mapping,
+// w workers find a collision about w times sooner.
+//
+// This needs collisions among the points themselves: discrete logarithm
+// rho qualifies, see DiscreteLogRho. Factoring rho does not, since its
+// walk modulo n collides modulo an unknown p only, visible through a gcd
+// but not by comparing points; parallel runs of it with different
+// constants speed it up by only the square root of the workers.
+// https://people.scs.carleton.ca/~paulv/papers/JoC97.pdf
+type CollisionSearch struct {
+	// DistinguishedBits is the number of low bits that must be 0 for a
+	// point to be distinguished; 0 makes every point distinguished.
+	DistinguishedBits uint
+	// Workers is the number of walks run at once, runtime.NumCPU() if 0.
+	Workers int
+	// Store holds the distinguished points, NewMemoryPointStore() if nil.
+	Store PointStore
+	// MaxSteps bounds the steps of all walks together, unbounded if 0.
+	MaxSteps int64
+}
+
+// Run starts walks from newWalk, called with random or crypto/rand.Reader
+// if nil, until collide returns true for the point stored earlier and the
+// one reached later by another walk, and returns the steps taken. Walks
+// longer than 20 times the expected 2^DistinguishedBits steps are
+// abandoned as trapped in a cycle. collide is called by one worker at a
+// time.
+func (s *CollisionSearch) Run(random io.Reader, newWalk func(random io.Reader) (CollisionWalk, error), collide func(earlier, later DistinguishedPoint) bool) (int64, error) {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	store := s.Store
+	if store == nil {
+		store = NewMemoryPointStore()
+	}
+	maxTrail := int64(20) << s.DistinguishedBits
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), s.DistinguishedBits), big.NewInt(1))
+
+	var (
+		steps    atomic.Int64
+		stop     atomic.Bool
+		found    bool
+		firstErr error
+		mu       sync.Mutex // guards random, collide, found and firstErr
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		stop.Store(true)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+
+			defer wg.Done()
+			low := new(big.Int)
+			for !stop.Load() {
+				mu.Lock()
+				walk, err := newWalk(random)
+				mu.Unlock()
+				if err != nil {
+					fail(err)
+					return
+				}
+
+				trail := int64(0)
+				for ; trail < maxTrail && !stop.Load(); trail++ {
+					if low.And(walk.Point(), mask).Sign() == 0 {
+						break
+					}
+					walk.Step()
+				}
+				if total := steps.Add(trail); s.MaxSteps > 0 && total >= s.MaxSteps {
+					stop.Store(true)
+				}
+				if trail == maxTrail || stop.Load() {
+					continue
+				}
+
+				later := DistinguishedPoint{Point: new(big.Int).Set(walk.Point()), State: walk.State(), Steps: trail}
+				earlier, ok, err := store.Add(later)
+				if err != nil {
+					fail(err)
+					return
+				}
+				if !ok {
+					continue
+				}
+				mu.Lock()
+				if !found && collide(earlier, later) {
+					found = true
+					stop.Store(true)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	switch {
+	case firstErr != nil:
+		return steps.Load(), fmt.Errorf("CollisionSearch: %v", firstErr)
+	case !found:
+		return steps.Load(), fmt.Errorf("CollisionSearch: no collision in %v steps", steps.Load())
+	}
+	return steps.Load(), nil
+}
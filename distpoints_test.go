@@ -0,0 +1,29 @@
+package rsa_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestPointStores(t *testing.T) {
+	disk, err := rsa.NewDiskPointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, store := range map[string]rsa.PointStore{"memory": rsa.NewMemoryPointStore(), "disk": disk} {
+		first := rsa.DistinguishedPoint{Point: big.NewInt(0x1200), State: []byte{1, 2}, Steps: 300}
+		if _, ok, err := store.Add(first); ok || err != nil {
+			t.Errorf("%v: new point reported as stored (%v)", name, err)
+		}
+		if _, ok, _ := store.Add(rsa.DistinguishedPoint{Point: big.NewInt(0x3400), State: []byte{3}}); ok {
+			t.Errorf("%v: another new point reported as stored", name)
+		}
+		earlier, ok, err := store.Add(rsa.DistinguishedPoint{Point: big.NewInt(0x1200), State: []byte{9}, Steps: 7})
+		if !ok || err != nil || earlier.Point.Cmp(first.Point) != 0 || !bytes.Equal(earlier.State, first.State) || earlier.Steps != first.Steps {
+			t.Errorf("%v: returned %+v, %v (%v), expected the first point", name, earlier, ok, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// trialDivisionBound is the largest prime Factorize divides out
+// before switching to Pollard's rho.
+const trialDivisionBound = 1000
+
+// PrimePower is a prime factor together with its multiplicity.
+type PrimePower struct {
+	Prime *big.Int
+	Exp   int
+}
+
+// Factorize returns the prime factorization of n > 0 in increasing order
+// of the primes, 1 having none. Small factors are found by trial division,
+// the rest by Pollard's rho, so n should not hold 2 large prime factors.
+// https://en.wikipedia.org/wiki/Integer_factorization
+func Factorize(n *big.Int) ([]PrimePower, error) {
+
+	if n.Sign() <= 0 {
+		return nil, fmt.Errorf("Factorize: %v is not positive", n)
+	}
+
+	rest := new(big.Int).Set(n)
+	quotient, remainder := new(big.Int), new(big.Int)
+	counts := map[string]*PrimePower{}
+	add := func(p *big.Int) {
+
+		if pp, ok := counts[p.String()]; ok {
+			pp.Exp++
+			return
+		}
+		counts[p.String()] = &PrimePower{Prime: new(big.Int).Set(p), Exp: 1}
+	}
+
+	for _, prime := range smallPrimes(trialDivisionBound) {
+		r := big.NewInt(prime)
+		for {
+			quotient.QuoRem(rest, r, remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			rest.Set(quotient)
+			add(r)
+		}
+	}
+
+	// Split the composite rest until only primes remain.
+	pending := []*big.Int{rest}
+	for len(pending) > 0 {
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if m.Cmp(big.NewInt(1)) == 0 {
+			continue
+		}
+		if m.IsUint64() && IsPrime64(m.Uint64()) || !m.IsUint64() && m.ProbablyPrime(20) {
+			add(m)
+			continue
+		}
+		if root, ok := exactSqrt(m); ok {
+			pending = append(pending, root, root)
+			continue
+		}
+		d, err := rhoFactor(new(big.Int), m)
+		if err != nil {
+			return nil, fmt.Errorf("Factorize: %v", err)
+		}
+		pending = append(pending, d, new(big.Int).Div(m, d))
+	}
+
+	res := make([]PrimePower, 0, len(counts))
+	for _, pp := range counts {
+		res = append(res, *pp)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Prime.Cmp(res[j].Prime) < 0 })
+	return res, nil
+}
+
+// rhoFactor sets z to a nontrivial factor of the odd composite n found by
+// Pollard's rho with Brent's cycle detection and returns z, trying the
+// polynomials x*x + c for c = 1, ..., maxRhoRestarts+1.
+func rhoFactor(z, n *big.Int) (*big.Int, error) {
+
+	one := big.NewInt(1)
+	gcd := fastestGcd(n.BitLen())
+	factor, diff, quotient := new(big.Int), new(big.Int), new(big.Int)
+
+	for c := int64(1); c <= maxRhoRestarts+1; c++ {
+		cBig := big.NewInt(c)
+		x, xFixed := big.NewInt(2), big.NewInt(2)
+		factor.Set(one)
+
+		for cycleSize := 2; factor.Cmp(one) == 0; cycleSize *= 2 {
+			for count := 1; count <= cycleSize && factor.Cmp(one) == 0; count++ {
+				x.Mul(x, x)
+				x.Add(x, cBig)
+				quotient.DivMod(x, n, x)
+				gcd(factor, diff.Abs(diff.Sub(x, xFixed)), n)
+			}
+			xFixed.Set(x)
+		}
+		if factor.Cmp(n) != 0 {
+			return z.Set(factor), nil
+		}
+	}
+	return nil, fmt.Errorf("Pollard's rho found no factor of %v", n)
+}
+
+// Multiplicative evaluates the multiplicative function f, given by its
+// values f(p^k) on prime powers, at the number factorized as factors:
+// f(n) is the product of f(p^k) over the prime powers p^k of n.
+// https://en.wikipedia.org/wiki/Multiplicative_function
+func Multiplicative(factors []PrimePower, f func(p *big.Int, k int) *big.Int) *big.Int {
+
+	res := big.NewInt(1)
+	for _, pp := range factors {
+		res.Mul(res, f(pp.Prime, pp.Exp))
+	}
+	return res
+}
+
+// NumDivisors returns d(n), the number of positive divisors of n > 0,
+// the product of k+1 over the prime powers p^k of n.
+// https://en.wikipedia.org/wiki/Divisor_function
+func NumDivisors(n *big.Int) (*big.Int, error) {
+
+	factors, err := Factorize(n)
+	if err != nil {
+		return nil, err
+	}
+	return Multiplicative(factors, func(_ *big.Int, k int) *big.Int {
+		return big.NewInt(int64(k) + 1)
+	}), nil
+}
+
+// SumDivisors returns σ(n), the sum of the positive divisors of n > 0,
+// the product of (p^(k+1) - 1) / (p - 1) over the prime powers p^k of n.
+func SumDivisors(n *big.Int) (*big.Int, error) {
+
+	factors, err := Factorize(n)
+	if err != nil {
+		return nil, err
+	}
+	return Multiplicative(factors, func(p *big.Int, k int) *big.Int {
+		sum := new(big.Int).Exp(p, big.NewInt(int64(k)+1), nil)
+		sum.Sub(sum, big.NewInt(1))
+		return sum.Div(sum, new(big.Int).Sub(p, big.NewInt(1)))
+	}), nil
+}
+
+// Mobius returns μ(n) for n > 0: 0 if a square divides n, otherwise
+// -1 or 1 for an odd or even number of prime factors.
+// https://en.wikipedia.org/wiki/M%C3%B6bius_function
+func Mobius(n *big.Int) (int, error) {
+
+	factors, err := Factorize(n)
+	if err != nil {
+		return 0, err
+	}
+	mu := Multiplicative(factors, func(_ *big.Int, k int) *big.Int {
+		if k > 1 {
+			return big.NewInt(0)
+		}
+		return big.NewInt(-1)
+	})
+	return int(mu.Int64()), nil
+}
@@ -0,0 +1,64 @@
+package rsa_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/dataset"
+)
+
+func TestFactorize(t *testing.T) {
+	semiprime, err := dataset.Get(60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2^3 * 3^2 * 5 * 1009^2 * p * q
+	n := new(big.Int).Mul(big.NewInt(360*1009*1009), semiprime.N)
+
+	factors, err := rsa.Factorize(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo, hi := semiprime.P, semiprime.Q
+	if lo.Cmp(hi) > 0 {
+		lo, hi = hi, lo
+	}
+	want := fmt.Sprintf("[{2 3} {3 2} {5 1} {1009 2} {%v 1} {%v 1}]", lo, hi)
+	if got := fmt.Sprint(factors); got != want {
+		t.Errorf("Factorize(%v) = %v, expected %v", n, got, want)
+	}
+
+	if factors, _ := rsa.Factorize(big.NewInt(1)); len(factors) != 0 {
+		t.Errorf("Factorize(1) = %v", factors)
+	}
+	if _, err := rsa.Factorize(big.NewInt(0)); err == nil {
+		t.Error("expected an error for 0")
+	}
+}
+
+func TestDivisorFunctions(t *testing.T) {
+	tests := []struct {
+		n, numDivisors, sumDivisors int64
+		mobius                      int
+	}{
+		{1, 1, 1, 1},
+		{12, 6, 28, 0},
+		{30, 8, 72, -1},
+		{360, 24, 1170, 0},
+		{1009 * 1013, 4, 1 + 1009 + 1013 + 1009*1013, 1},
+	}
+	for _, tt := range tests {
+		n := big.NewInt(tt.n)
+		if got, err := rsa.NumDivisors(n); err != nil || got.Int64() != tt.numDivisors {
+			t.Errorf("NumDivisors(%v) = %v, %v, expected %v", tt.n, got, err, tt.numDivisors)
+		}
+		if got, err := rsa.SumDivisors(n); err != nil || got.Int64() != tt.sumDivisors {
+			t.Errorf("SumDivisors(%v) = %v, %v, expected %v", tt.n, got, err, tt.sumDivisors)
+		}
+		if got, err := rsa.Mobius(n); err != nil || got != tt.mobius {
+			t.Errorf("Mobius(%v) = %v, %v, expected %v", tt.n, got, err, tt.mobius)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"sync/atomic"
+)
+
+// dlogPartitions is the number r of multipliers of the r-adding walk of
+// DiscreteLogRho; Teske found r around 20 to behave like a random mapping,
+// and 16 lets the top 4 bits of a hash pick one.
+const dlogPartitions = 16
+
+// DiscreteLogRho returns x with g^x = h mod p, for g of prime order q, by
+// Pollard's rho with the parallel collision search of search, default
+// settings if nil. Every walk multiplies x = g^a * h^b by one of
+// dlogPartitions fixed random M_j = g^α_j * h^β_j chosen by x, so its a and b
+// stay known; two walks meeting at g^a * h^b = g^a' * h^b' give
+// x = (a' - a) / (b - b') mod q in about sqrt(πq/2) steps in all.
+// A nil search uses distinguished points of a quarter of q's bits.
+// https://en.wikipedia.org/wiki/Pollard%27s_rho_algorithm_for_logarithms
+func DiscreteLogRho(random io.Reader, g, h, p, q *big.Int, search *CollisionSearch) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if random == nil {
+		random = rand.Reader
+	}
+	if search == nil {
+		search = &CollisionSearch{DistinguishedBits: uint(q.BitLen() / 4)}
+	}
+	if !q.ProbablyPrime(20) {
+		return nil, meter.done(), fmt.Errorf("DiscreteLogRho: the order %v is not prime", q)
+	}
+	one := big.NewInt(1)
+	for _, x := range []*big.Int{g, h} {
+		meter.exp(q)
+		if x.Sign() <= 0 || x.Cmp(p) >= 0 || new(big.Int).Exp(x, q, p).Cmp(one) != 0 {
+			return nil, meter.done(), fmt.Errorf("DiscreteLogRho: %v is not in the subgroup of order %v", x, q)
+		}
+	}
+	if g.Cmp(one) == 0 {
+		return nil, meter.done(), fmt.Errorf("DiscreteLogRho: g = 1 does not generate the subgroup")
+	}
+
+	walk := &dlogWalk{g: g, h: h, p: p, q: q, width: (q.BitLen() + 7) / 8}
+	for j := range walk.alpha {
+		var err error
+		if walk.alpha[j], walk.beta[j], walk.mult[j], err = walk.random(random); err != nil {
+			return nil, meter.done(), fmt.Errorf("DiscreteLogRho: %v", err)
+		}
+		meter.exp(q)
+		meter.exp(q)
+	}
+
+	var starts atomic.Int64
+	newWalk := func(random io.Reader) (CollisionWalk, error) {
+
+		w := *walk
+		var err error
+		if w.a, w.b, w.x, err = walk.random(random); err != nil {
+			return nil, err
+		}
+		starts.Add(1)
+		return &w, nil
+	}
+	var x *big.Int
+	collide := func(earlier, later DistinguishedPoint) bool {
+
+		a1, b1 := walk.decode(earlier.State)
+		a2, b2 := walk.decode(later.State)
+		denominator := new(big.Int).Sub(b1, b2)
+		if denominator.Mod(denominator, q).Sign() == 0 {
+			return false // the same walk twice, or no information
+		}
+		candidate := new(big.Int).Sub(a2, a1)
+		candidate.Mul(candidate, denominator.ModInverse(denominator, q))
+		candidate.Mod(candidate, q)
+		if new(big.Int).Exp(g, candidate, p).Cmp(h) != 0 {
+			return false // a stale point of another search
+		}
+		x = candidate
+		return true
+	}
+
+	steps, err := search.Run(random, newWalk, collide)
+	meter.cost.ModMuls += int(steps) + int(starts.Load())*2*expMuls(q)
+	if err != nil {
+		return nil, meter.done(), fmt.Errorf("DiscreteLogRho: %v", err)
+	}
+	return x, meter.done(), nil
+}
+
+// dlogWalk is a walk of DiscreteLogRho at x = g^a * h^b mod p.
+type dlogWalk struct {
+	g, h, p, q  *big.Int
+	alpha, beta [dlogPartitions]*big.Int
+	mult        [dlogPartitions]*big.Int
+	width       int // bytes of a and b in State
+	a, b, x     *big.Int
+}
+
+// random returns random exponents a and b below q and g^a * h^b mod p.
+func (w *dlogWalk) random(random io.Reader) (a, b, x *big.Int, err error) {
+
+	if a, err = rand.Int(random, w.q); err != nil {
+		return nil, nil, nil, err
+	}
+	if b, err = rand.Int(random, w.q); err != nil {
+		return nil, nil, nil, err
+	}
+	x = new(big.Int).Exp(w.g, a, w.p)
+	x.Mul(x, new(big.Int).Exp(w.h, b, w.p))
+	return a, b, x.Mod(x, w.p), nil
+}
+
+func (w *dlogWalk) Point() *big.Int {
+
+	return w.x
+}
+
+// Step multiplies by the M_j picked by a hash of the low word of x,
+// rather than its low bits, which are 0 at every distinguished point.
+func (w *dlogWalk) Step() {
+
+	j := uint64(w.x.Bits()[0]) * 0x9e3779b97f4a7c15 >> 60
+	w.x.Mul(w.x, w.mult[j])
+	w.x.Mod(w.x, w.p)
+	w.a.Add(w.a, w.alpha[j])
+	if w.a.Cmp(w.q) >= 0 {
+		w.a.Sub(w.a, w.q)
+	}
+	w.b.Add(w.b, w.beta[j])
+	if w.b.Cmp(w.q) >= 0 {
+		w.b.Sub(w.b, w.q)
+	}
+}
+
+// State is a and b, each in width big-endian bytes.
+func (w *dlogWalk) State() []byte {
+
+	state := make([]byte, 2*w.width)
+	w.a.FillBytes(state[:w.width])
+	w.b.FillBytes(state[w.width:])
+	return state
+}
+
+// decode splits a State into a and b.
+func (w *dlogWalk) decode(state []byte) (a, b *big.Int) {
+
+	return new(big.Int).SetBytes(state[:len(state)/2]), new(big.Int).SetBytes(state[len(state)/2:])
+}
@@ -0,0 +1,40 @@
+package rsa_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestDiscreteLogRho(t *testing.T) {
+	// p = 2q + 1 is a safe prime, and 4, a square, generates the subgroup
+	// of prime order q.
+	p, q := big.NewInt(4294992683), big.NewInt(2147496341)
+	g, secret := big.NewInt(4), big.NewInt(1234567891)
+	h := new(big.Int).Exp(g, secret, p)
+
+	x, cost, err := rsa.DiscreteLogRho(nil, g, h, p, q, nil)
+	if err != nil || x.Cmp(secret) != 0 {
+		t.Fatalf("found %v (%v), expected %v", x, err, secret)
+	}
+	t.Logf("%v modular multiplications in %v", cost.ModMuls, cost.Duration)
+
+	// Shared through the files of a disk store by 2 workers.
+	store, err := rsa.NewDiskPointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	search := &rsa.CollisionSearch{DistinguishedBits: 10, Workers: 2, Store: store}
+	if x, _, err := rsa.DiscreteLogRho(nil, g, h, p, q, search); err != nil || x.Cmp(secret) != 0 {
+		t.Errorf("found %v (%v) with a disk store, expected %v", x, err, secret)
+	}
+
+	search = &rsa.CollisionSearch{DistinguishedBits: 8, MaxSteps: 1000}
+	if _, _, err := rsa.DiscreteLogRho(nil, g, h, p, q, search); err == nil {
+		t.Error("expected no collision within 1000 steps")
+	}
+	if _, _, err := rsa.DiscreteLogRho(nil, big.NewInt(2), h, p, q, nil); err == nil {
+		t.Error("expected an error for a g outside the subgroup")
+	}
+}
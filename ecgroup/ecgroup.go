@@ -0,0 +1,198 @@
+// Package ecgroup implements the group law of Montgomery curves
+// B*y^2 = x^3 + A*x^2 + x modulo n in projective XZ coordinates, the
+// arithmetic of Lenstra's elliptic curve factorization method (ECM),
+// so that the group can be explored directly.
+// n need not be prime. Modulo a composite n the curve is one curve modulo
+// every prime p of n at once, and a point that is the identity modulo p
+// only has a Z coordinate divisible by p: the inversion of Z that affine
+// coordinates need fails, and the gcd it fails with is a factor of n,
+// reported as a *FactorError.
+// https://en.wikipedia.org/wiki/Montgomery_curve
+// https://en.wikipedia.org/wiki/Lenstra_elliptic-curve_factorization
+package ecgroup
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// FactorError reports a failed inversion modulo n whose gcd with n is the
+// proper factor Factor.
+type FactorError struct {
+	Factor *big.Int
+}
+
+func (err *FactorError) Error() string {
+
+	return fmt.Sprintf("ecgroup: found the factor %v of the modulus", err.Factor)
+}
+
+// Curve is the Montgomery curve of coefficient A modulo N. XZ arithmetic
+// only needs A24 = (A + 2) / 4; the coefficient B plays no part.
+type Curve struct {
+	N, A, A24 *big.Int
+}
+
+// Point is (X : Z) with affine x = X / Z, the pair standing for the 2
+// points (x, ±y); Z = 0 is the identity.
+type Point struct {
+	X, Z *big.Int
+}
+
+// Identity returns the point at infinity (1 : 0).
+func Identity() Point {
+
+	return Point{X: big.NewInt(1), Z: big.NewInt(0)}
+}
+
+// IsIdentity reports whether p is the identity modulo n, Z = 0.
+func (c *Curve) IsIdentity(p Point) bool {
+
+	return new(big.Int).Mod(p.Z, c.N).Sign() == 0
+}
+
+// NewCurve returns the curve of coefficient a modulo the odd n > 1, which
+// needs A^2 - 4 invertible modulo n.
+func NewCurve(a, n *big.Int) (*Curve, error) {
+
+	if n.Cmp(big.NewInt(1)) <= 0 || n.Bit(0) == 0 {
+		return nil, fmt.Errorf("NewCurve: modulus %v must be odd and > 1", n)
+	}
+	c := &Curve{N: new(big.Int).Set(n), A: new(big.Int).Mod(a, n)}
+	discriminant := new(big.Int).Mul(c.A, c.A)
+	discriminant.Sub(discriminant, big.NewInt(4))
+	if _, err := c.inverse(discriminant); err != nil {
+		return nil, fmt.Errorf("NewCurve: A^2 - 4 is not invertible: %w", err)
+	}
+	four, _ := c.inverse(big.NewInt(4))
+	c.A24 = c.mod(four.Mul(four, new(big.Int).Add(c.A, big.NewInt(2))))
+	return c, nil
+}
+
+// RandomCurve returns a random curve modulo n by Suyama's parametrization
+// and its point of x = u^3 / v^3, u = σ^2 - 5, v = 4σ, σ drawn from random,
+// crypto/rand.Reader if nil. The group order of these curves modulo every
+// prime is divisible by 12, which makes it likelier to be smooth.
+// The parametrization itself inverts a number modulo n and may already
+// fail with a *FactorError.
+func RandomCurve(random io.Reader, n *big.Int) (*Curve, Point, error) {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	if n.Cmp(big.NewInt(7)) <= 0 || n.Bit(0) == 0 {
+		return nil, Point{}, fmt.Errorf("RandomCurve: modulus %v must be odd and > 7", n)
+	}
+	c := &Curve{N: new(big.Int).Set(n)}
+	for {
+		// σ in [6, n-1] avoids the degenerate σ = 0, ±1, ±3, ±5.
+		sigma, err := rand.Int(random, new(big.Int).Sub(n, big.NewInt(6)))
+		if err != nil {
+			return nil, Point{}, fmt.Errorf("RandomCurve: %v", err)
+		}
+		sigma.Add(sigma, big.NewInt(6))
+		u := c.mod(new(big.Int).Sub(new(big.Int).Mul(sigma, sigma), big.NewInt(5)))
+		v := c.mod(new(big.Int).Lsh(sigma, 2))
+		u3 := c.mod(new(big.Int).Exp(u, big.NewInt(3), n))
+		v3 := c.mod(new(big.Int).Exp(v, big.NewInt(3), n))
+
+		// A + 2 = (v - u)^3 (3u + v) / (4 u^3 v), so A24 = that / 4.
+		denominator := c.mod(new(big.Int).Mul(new(big.Int).Lsh(u3, 4), v))
+		inverse, err := c.inverse(denominator)
+		if err != nil {
+			if _, ok := err.(*FactorError); ok {
+				return nil, Point{}, fmt.Errorf("RandomCurve: %w", err)
+			}
+			continue // σ made the denominator 0 modulo n
+		}
+		vu := new(big.Int).Sub(v, u)
+		numerator := new(big.Int).Exp(vu, big.NewInt(3), n)
+		numerator.Mul(numerator, new(big.Int).Add(new(big.Int).Lsh(u, 1), new(big.Int).Add(u, v)))
+		c.A24 = c.mod(numerator.Mul(numerator, inverse))
+		c.A = c.mod(new(big.Int).Sub(new(big.Int).Lsh(c.A24, 2), big.NewInt(2)))
+		return c, Point{X: u3, Z: v3}, nil
+	}
+}
+
+// Double returns 2p:
+// X = (X+Z)^2 (X-Z)^2, Z = 4XZ ((X-Z)^2 + A24 * 4XZ).
+func (c *Curve) Double(p Point) Point {
+
+	sum := new(big.Int).Add(p.X, p.Z)
+	sum = c.mod(sum.Mul(sum, sum))
+	diff := new(big.Int).Sub(p.X, p.Z)
+	diff = c.mod(diff.Mul(diff, diff))
+	fourXZ := new(big.Int).Sub(sum, diff)
+	z := new(big.Int).Mul(c.A24, fourXZ)
+	z.Add(z, diff)
+	return Point{X: c.mod(sum.Mul(sum, diff)), Z: c.mod(z.Mul(z, fourXZ))}
+}
+
+// DiffAdd returns p + q given their difference diff = p - q, which XZ
+// coordinates cannot do without, as x alone does not tell ±y apart.
+// diff must not be the identity; use Double for p = q.
+func (c *Curve) DiffAdd(p, q, diff Point) Point {
+
+	u := new(big.Int).Mul(new(big.Int).Sub(p.X, p.Z), new(big.Int).Add(q.X, q.Z))
+	v := new(big.Int).Mul(new(big.Int).Add(p.X, p.Z), new(big.Int).Sub(q.X, q.Z))
+	x := new(big.Int).Add(u, v)
+	x = c.mod(x.Mul(x, x))
+	z := new(big.Int).Sub(u, v)
+	z = c.mod(z.Mul(z, z))
+	return Point{X: c.mod(x.Mul(x, diff.Z)), Z: c.mod(z.Mul(z, diff.X))}
+}
+
+// ScalarMult returns kp, k >= 0, by the Montgomery ladder, which keeps
+// R1 - R0 = p so every addition knows its difference. Its steps do not
+// depend on the bits of k beyond their number.
+// https://en.wikipedia.org/wiki/Elliptic_curve_point_multiplication#Montgomery_ladder
+func (c *Curve) ScalarMult(k *big.Int, p Point) Point {
+
+	if k.Sign() == 0 {
+		return Identity()
+	}
+	r0, r1 := p, c.Double(p)
+	for i := k.BitLen() - 2; i >= 0; i-- {
+		if k.Bit(i) == 1 {
+			r0, r1 = c.DiffAdd(r1, r0, p), c.Double(r1)
+		} else {
+			r0, r1 = c.Double(r0), c.DiffAdd(r1, r0, p)
+		}
+	}
+	return r0
+}
+
+// Affine returns x = X / Z mod n. It fails for the identity modulo n and
+// with a *FactorError for a point that is the identity modulo some prime
+// factors of n only.
+func (c *Curve) Affine(p Point) (*big.Int, error) {
+
+	inverse, err := c.inverse(p.Z)
+	if err != nil {
+		return nil, fmt.Errorf("Affine: %w", err)
+	}
+	return c.mod(inverse.Mul(inverse, p.X)), nil
+}
+
+// inverse returns x^-1 mod n, a *FactorError if gcd(x, n) is a proper
+// factor of n, and another error if it is n.
+func (c *Curve) inverse(x *big.Int) (*big.Int, error) {
+
+	reduced := c.mod(new(big.Int).Set(x))
+	gcd := new(big.Int).GCD(nil, nil, reduced, c.N)
+	switch {
+	case gcd.Cmp(c.N) == 0:
+		return nil, fmt.Errorf("%v is 0 modulo %v", x, c.N)
+	case gcd.Cmp(big.NewInt(1)) != 0:
+		return nil, &FactorError{Factor: gcd}
+	}
+	return reduced.ModInverse(reduced, c.N), nil
+}
+
+// mod reduces x modulo n in place and returns it.
+func (c *Curve) mod(x *big.Int) *big.Int {
+
+	return x.Mod(x, c.N)
+}
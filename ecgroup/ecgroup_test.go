@@ -0,0 +1,105 @@
+package ecgroup_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/ecgroup"
+)
+
+func TestGroupLaw(t *testing.T) {
+	p := big.NewInt(1000003)
+	curve, point, err := ecgroup.RandomCurve(rand.Reader, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := func(q ecgroup.Point) string {
+
+		if curve.IsIdentity(q) {
+			return "O"
+		}
+		ax, err := curve.Affine(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ax.String()
+	}
+
+	double := curve.Double(point)
+	if x(double) != x(curve.ScalarMult(big.NewInt(2), point)) {
+		t.Error("Double(P) != [2]P")
+	}
+	if x(curve.DiffAdd(double, point, point)) != x(curve.ScalarMult(big.NewInt(3), point)) {
+		t.Error("2P + P != [3]P")
+	}
+	a, b := big.NewInt(1234), big.NewInt(5678)
+	ab := curve.ScalarMult(new(big.Int).Mul(a, b), point)
+	if x(curve.ScalarMult(a, curve.ScalarMult(b, point))) != x(ab) || x(curve.ScalarMult(b, curve.ScalarMult(a, point))) != x(ab) {
+		t.Error("[a][b]P, [b][a]P and [ab]P differ")
+	}
+	// Hasse: the order is within p + 1 ± 2 sqrt(p), and a multiple of 12
+	// for Suyama curves, so [k]P = O for some such k.
+	found := false
+	for k := int64(1000003 + 1 - 2000); k <= 1000003+1+2000; k++ {
+		if k%12 == 0 && curve.IsIdentity(curve.ScalarMult(big.NewInt(k), point)) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("no multiple of 12 in the Hasse interval annihilates P")
+	}
+	if _, err := curve.Affine(ecgroup.Identity()); err == nil {
+		t.Error("the identity has an affine x")
+	}
+}
+
+func TestFailureAsFactor(t *testing.T) {
+	p, q := big.NewInt(1000003), big.NewInt(1<<61-1)
+	n := new(big.Int).Mul(p, q)
+	// ECM stage 1: k is the product of the prime powers up to 2000.
+	k := big.NewInt(1)
+	for prime := range rsa.Primes(2000) {
+		for power := prime; power <= 2000; power *= prime {
+			k.Mul(k, new(big.Int).SetUint64(prime))
+		}
+	}
+	for range 500 {
+		curve, point, err := ecgroup.RandomCurve(rand.Reader, n)
+		if err == nil {
+			_, err = curve.Affine(curve.ScalarMult(k, point))
+		}
+		var factor *ecgroup.FactorError
+		if errors.As(err, &factor) {
+			if factor.Factor.Cmp(p) != 0 {
+				t.Errorf("found %v, expected %v", factor.Factor, p)
+			}
+			return
+		}
+	}
+	t.Error("no curve revealed a factor")
+}
+
+func TestNewCurve(t *testing.T) {
+	if _, err := ecgroup.NewCurve(big.NewInt(2), big.NewInt(101)); err == nil {
+		t.Error("accepted the singular A = 2")
+	}
+	if _, err := ecgroup.NewCurve(big.NewInt(3), big.NewInt(100)); err == nil {
+		t.Error("accepted an even modulus")
+	}
+	// A^2 - 4 = 3 * 7 shares the factor 7 with 7 * 11.
+	var factor *ecgroup.FactorError
+	if _, err := ecgroup.NewCurve(big.NewInt(5), big.NewInt(77)); !errors.As(err, &factor) || factor.Factor.Int64() != 7 {
+		t.Errorf("expected the factor 7, got %v", err)
+	}
+	curve, err := ecgroup.NewCurve(big.NewInt(6), big.NewInt(101))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if curve.A24.Int64() != 2 {
+		t.Errorf("A24 = %v, expected (6 + 2) / 4 = 2", curve.A24)
+	}
+}
@@ -0,0 +1,120 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Alphabet maps message symbols to the digits of a positional number
+// system so that strings can be turned into integers RSA can encrypt.
+// Symbol i of the alphabet is the digit i + offset in base radix.
+type Alphabet struct {
+	symbols []rune // nil for raw bytes
+	index   map[rune]int64
+	radix   int64
+	offset  int64
+}
+
+var (
+	// ClassroomAlphabet is the textbook A=01, B=02, ..., Z=26 scheme where
+	// every letter becomes 2 decimal digits, e.g. "HI" encodes to 809.
+	ClassroomAlphabet = mustAlphabet("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 100, 1)
+
+	// Base62Alphabet reads a string of 0-9, A-Z and a-z as a base 62 number.
+	// Like decimal numbers, leading '0' symbols do not survive a round trip.
+	Base62Alphabet = mustAlphabet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 62, 0)
+
+	// Base256Alphabet reads the raw bytes of a string as a big-endian
+	// base 256 number. Leading zero bytes do not survive a round trip.
+	Base256Alphabet = &Alphabet{radix: 256}
+)
+
+// NewAlphabet creates an alphabet of distinct symbols encoded as the
+// digits offset, offset+1, ... in base radix >= 2.
+func NewAlphabet(symbols string, radix, offset int64) (*Alphabet, error) {
+
+	runes := []rune(symbols)
+	if len(runes) == 0 {
+		return nil, fmt.Errorf("NewAlphabet: no symbols")
+	}
+	if radix < 2 {
+		return nil, fmt.Errorf("NewAlphabet: radix %v must be at least 2", radix)
+	}
+	if offset < 0 || radix < int64(len(runes))+offset {
+		return nil, fmt.Errorf("NewAlphabet: radix %v cannot hold %v symbols from digit %v", radix, len(runes), offset)
+	}
+
+	index := make(map[rune]int64, len(runes))
+	for i, r := range runes {
+		if _, ok := index[r]; ok {
+			return nil, fmt.Errorf("NewAlphabet: duplicate symbol %q", r)
+		}
+		index[r] = int64(i)
+	}
+
+	return &Alphabet{symbols: runes, index: index, radix: radix, offset: offset}, nil
+}
+
+// mustAlphabet is NewAlphabet for the predefined alphabets.
+func mustAlphabet(symbols string, radix, offset int64) *Alphabet {
+
+	alphabet, err := NewAlphabet(symbols, radix, offset)
+	if err != nil {
+		panic(err)
+	}
+	return alphabet
+}
+
+// Encode converts msg to the integer whose base radix digits are
+// the alphabet's digits of msg's symbols, most significant first.
+func Encode(msg string, alphabet *Alphabet) (*big.Int, error) {
+
+	res := new(big.Int)
+	radix := big.NewInt(alphabet.radix)
+	digit := new(big.Int)
+
+	if alphabet.symbols == nil {
+		return res.SetBytes([]byte(msg)), nil
+	}
+
+	for _, r := range msg {
+		i, ok := alphabet.index[r]
+		if !ok {
+			return nil, fmt.Errorf("Encode: symbol %q is not in the alphabet", r)
+		}
+		res.Mul(res, radix)
+		res.Add(res, digit.SetInt64(i+alphabet.offset))
+	}
+	return res, nil
+}
+
+// Decode converts an integer produced by Encode back to its message.
+func Decode(m *big.Int, alphabet *Alphabet) (string, error) {
+
+	if m.Sign() < 0 {
+		return "", fmt.Errorf("Decode: negative number %v", m)
+	}
+	if alphabet.symbols == nil {
+		return string(m.Bytes()), nil
+	}
+
+	rest := new(big.Int).Set(m)
+	radix := big.NewInt(alphabet.radix)
+	digit := new(big.Int)
+	reversed := []rune{}
+
+	for rest.Sign() > 0 {
+		rest.QuoRem(rest, radix, digit)
+		i := digit.Int64() - alphabet.offset
+		if i < 0 || i >= int64(len(alphabet.symbols)) {
+			return "", fmt.Errorf("Decode: digit %v of %v does not map to a symbol", digit, m)
+		}
+		reversed = append(reversed, alphabet.symbols[i])
+	}
+
+	msg := make([]rune, len(reversed))
+	for i, r := range reversed {
+		msg[len(reversed)-1-i] = r
+	}
+	return string(msg), nil
+}
@@ -0,0 +1,61 @@
+package rsa_test
+
+import (
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		alphabet *rsa.Alphabet
+		msg      string
+	}{
+		{rsa.ClassroomAlphabet, "HELLO"},
+		{rsa.Base62Alphabet, "Rsa2048"},
+		{rsa.Base256Alphabet, "héllo, world"},
+	}
+
+	for _, test := range tests {
+		m, err := rsa.Encode(test.msg, test.alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := rsa.Decode(m, test.alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded != test.msg {
+			t.Errorf("%q encoded to %v decoded to %q", test.msg, m, decoded)
+		}
+	}
+
+	if m, _ := rsa.Encode("HI", rsa.ClassroomAlphabet); m.Int64() != 809 {
+		t.Errorf("HI encoded to %v, expected 809", m)
+	}
+	if _, err := rsa.Encode("hi", rsa.ClassroomAlphabet); err == nil {
+		t.Error("expected an error for symbols outside the alphabet")
+	}
+	if _, err := rsa.NewAlphabet("AB", 2, 1); err == nil {
+		t.Error("expected an error for a radix too small for the symbols")
+	}
+	if _, err := rsa.NewAlphabet("a", 1, 0); err == nil {
+		t.Error("expected an error for radix 1, which Decode cannot shrink by")
+	}
+}
+
+func TestEncryptWord(t *testing.T) {
+	var n, e int64 = 937513, 638471
+
+	m, err := rsa.Encode("HI", rsa.ClassroomAlphabet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher := rsa.GetEncOrDecMsg(m.Int64(), e, n)
+	mPrime := rsa.DecryptCipher(cipher, n, e)
+
+	m.SetInt64(mPrime)
+	if word, err := rsa.Decode(m, rsa.ClassroomAlphabet); err != nil || word != "HI" {
+		t.Errorf("decrypted word %q (%v), expected HI", word, err)
+	}
+}
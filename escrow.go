@@ -0,0 +1,107 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/nethatix/rsa/asn1edu"
+)
+
+// EscrowEnvelope holds a private key wrapped under a master public key.
+// Only the prime p is wrapped: with the escrowed public key it determines
+// every other field. Whoever holds the master private key can open every
+// envelope ever made for it, which is both the purpose and the risk of
+// escrow: one key compromise exposes all users at once, silently.
+// https://en.wikipedia.org/wiki/Key_escrow
+type EscrowEnvelope struct {
+	// Master is the modulus of the master key the envelope is sealed for.
+	Master *big.Int
+	// Subject is the escrowed public key.
+	Subject PublicKey
+	// WrappedP is p^e mod n under the master key. Textbook RSA is fine
+	// here only because p is a large random secret, not a guessable message.
+	WrappedP *big.Int
+}
+
+// Escrow seals priv for the holder of master's private key.
+// The master modulus must exceed priv's prime p.
+func Escrow(priv *PrivateKey, master *PublicKey) (*EscrowEnvelope, error) {
+
+	if priv.P.Cmp(master.N) >= 0 {
+		return nil, fmt.Errorf("Escrow: master modulus is too small to wrap p")
+	}
+	return &EscrowEnvelope{
+		Master:   new(big.Int).Set(master.N),
+		Subject:  PublicKey{N: new(big.Int).Set(priv.N), E: new(big.Int).Set(priv.E)},
+		WrappedP: new(big.Int).Exp(priv.P, master.E, master.N),
+	}, nil
+}
+
+// Recover opens the envelope with the master private key and rebuilds
+// the escrowed private key, checking that the unwrapped p divides n.
+func (env *EscrowEnvelope) Recover(master *PrivateKey) (*PrivateKey, error) {
+
+	if master.N.Cmp(env.Master) != 0 {
+		return nil, fmt.Errorf("Recover: envelope is sealed for another master key")
+	}
+
+	p := new(big.Int).Exp(env.WrappedP, master.D, master.N)
+	if p.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("Recover: unwrapped value does not divide the escrowed modulus")
+	}
+	q, rem := new(big.Int).QuoRem(env.Subject.N, p, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, fmt.Errorf("Recover: unwrapped value does not divide the escrowed modulus")
+	}
+	return NewPrivateKey(p, q, env.Subject.E)
+}
+
+// MarshalDER encodes the envelope as
+//
+//	EscrowEnvelope ::= SEQUENCE {
+//	    master     INTEGER,  -- master modulus
+//	    modulus    INTEGER,  -- escrowed n
+//	    exponent   INTEGER,  -- escrowed e
+//	    wrappedP   INTEGER   -- p^e mod master modulus
+//	}
+func (env *EscrowEnvelope) MarshalDER() ([]byte, error) {
+
+	fields := []*big.Int{env.Master, env.Subject.N, env.Subject.E, env.WrappedP}
+	elements := make([][]byte, len(fields))
+	for i, n := range fields {
+		element, err := asn1edu.EncodeInteger(n)
+		if err != nil {
+			return nil, fmt.Errorf("MarshalDER: %v", err)
+		}
+		elements[i] = element
+	}
+	return asn1edu.EncodeSequence(elements...), nil
+}
+
+// ParseEscrowEnvelope decodes an envelope encoded by MarshalDER. It
+// rejects a wrapped p outside [1, master modulus).
+func ParseEscrowEnvelope(der []byte) (*EscrowEnvelope, error) {
+
+	seq, err := asn1edu.Parse(der)
+	if err != nil {
+		return nil, fmt.Errorf("ParseEscrowEnvelope: %v", err)
+	}
+	if seq.Tag != asn1edu.TagSequence || len(seq.Children) != 4 {
+		return nil, fmt.Errorf("ParseEscrowEnvelope: expected a SEQUENCE of 4 INTEGERs")
+	}
+
+	ints := make([]*big.Int, len(seq.Children))
+	for i, child := range seq.Children {
+		if ints[i], err = child.Integer(); err != nil {
+			return nil, fmt.Errorf("ParseEscrowEnvelope: %v", err)
+		}
+	}
+	if ints[3].Sign() <= 0 || ints[3].Cmp(ints[0]) >= 0 {
+		return nil, fmt.Errorf("ParseEscrowEnvelope: wrapped p is not in [1, master modulus)")
+	}
+	return &EscrowEnvelope{
+		Master:   ints[0],
+		Subject:  PublicKey{N: ints[1], E: ints[2]},
+		WrappedP: ints[3],
+	}, nil
+}
@@ -0,0 +1,88 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/asn1edu"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestEscrow(t *testing.T) {
+	master, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := rsa.Escrow(user, &master.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := env.MarshalDER()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := rsa.ParseEscrowEnvelope(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := parsed.Recover(master)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recovered.Equal(user) {
+		t.Errorf("recovered key differs from the escrowed one")
+	}
+
+	if _, err := parsed.Recover(user); err == nil {
+		t.Error("envelope opened with the wrong master key")
+	}
+	small, err := rsatest.RandomKeyPair(rand.Reader, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rsa.Escrow(user, &small.PublicKey); err == nil {
+		t.Error("expected an error for a master modulus smaller than p")
+	}
+}
+
+func TestEscrowTampered(t *testing.T) {
+	master, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, wrapped := range []*big.Int{big.NewInt(0), master.N} {
+		var elements [][]byte
+		for _, n := range []*big.Int{master.N, user.N, user.E, wrapped} {
+			element, err := asn1edu.EncodeInteger(n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			elements = append(elements, element)
+		}
+		if _, err := rsa.ParseEscrowEnvelope(asn1edu.EncodeSequence(elements...)); err == nil {
+			t.Errorf("wrapped p %v: expected a parse error", wrapped)
+		}
+	}
+
+	// Envelopes built in code skip ParseEscrowEnvelope: Recover itself must
+	// reject a wrapped p that unwraps to 0 or 1.
+	for _, wrapped := range []*big.Int{big.NewInt(0), big.NewInt(1), master.N} {
+		env := &rsa.EscrowEnvelope{Master: master.N, Subject: user.PublicKey, WrappedP: wrapped}
+		if _, err := env.Recover(master); err == nil {
+			t.Errorf("wrapped p %v: expected a recovery error", wrapped)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package rsa
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+// FDHHash maps msg onto Z_n* as the Full Domain Hash scheme requires.
+// It is FDHHashWith SHA-256.
+func FDHHash(msg []byte, n *big.Int) *big.Int {
+
+	return FDHHashWith(SHA256, msg, n)
+}
+
+// FDHHashWith maps msg onto Z_n* with the hash h.
+// The hash is expanded in counter mode (like MGF1) to 64 bits more than n
+// so that reducing modulo n leaves a negligible bias. The rare value
+// sharing a factor with n, or 0, is replaced by rehashing with the next
+// attempt number prefixed to the input. It panics unless n > 1, as no
+// value qualifies modulo 1.
+// https://en.wikipedia.org/wiki/Full_Domain_Hash
+func FDHHashWith(h Hash, msg []byte, n *big.Int) *big.Int {
+
+	if n.Cmp(big.NewInt(1)) <= 0 {
+		panic(fmt.Sprintf("FDHHashWith: modulus %v must be > 1", n))
+	}
+	outLen := (n.BitLen() + 64 + 7) / 8
+	x := new(big.Int)
+	gcd := new(big.Int)
+	one := big.NewInt(1)
+
+	for attempt := uint32(0); ; attempt++ {
+		var expanded []byte
+		for counter := uint32(0); len(expanded) < outLen; counter++ {
+			var prefix [8]byte
+			binary.BigEndian.PutUint32(prefix[:4], attempt)
+			binary.BigEndian.PutUint32(prefix[4:], counter)
+			digest := h.New()
+			digest.Write(prefix[:])
+			digest.Write(msg)
+			expanded = digest.Sum(expanded)
+		}
+
+		x.SetBytes(expanded[:outLen])
+		x.Mod(x, n)
+		if x.Sign() != 0 && gcd.GCD(nil, nil, x, n).Cmp(one) == 0 {
+			return x
+		}
+	}
+}
+
+// SignFDH returns the deterministic Full Domain Hash signature
+// s = FDHHash(msg)^d mod n.
+func SignFDH(priv *PrivateKey, msg []byte) (*big.Int, error) {
+
+	return SignFDHWith(SHA256, priv, msg)
+}
+
+// SignFDHWith is SignFDH with the hash h.
+func SignFDHWith(h Hash, priv *PrivateKey, msg []byte) (*big.Int, error) {
+
+	if priv.N == nil || priv.D == nil {
+		return nil, fmt.Errorf("SignFDHWith: private key is missing n or d")
+	}
+	if priv.N.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("SignFDHWith: modulus %v must be > 1", priv.N)
+	}
+	x := FDHHashWith(h, msg, priv.N)
+	return x.Exp(x, priv.D, priv.N), nil
+}
+
+// VerifyFDH checks that sig^e mod n equals FDHHash(msg).
+func VerifyFDH(pub *PublicKey, msg []byte, sig *big.Int) error {
+
+	return VerifyFDHWith(SHA256, pub, msg, sig)
+}
+
+// VerifyFDHWith is VerifyFDH with the hash h.
+func VerifyFDHWith(h Hash, pub *PublicKey, msg []byte, sig *big.Int) error {
+
+	if pub.N.Cmp(big.NewInt(1)) <= 0 {
+		return fmt.Errorf("VerifyFDHWith: modulus %v must be > 1", pub.N)
+	}
+	if sig.Sign() <= 0 || sig.Cmp(pub.N) >= 0 {
+		return fmt.Errorf("VerifyFDHWith: signature out of range")
+	}
+	if new(big.Int).Exp(sig, pub.E, pub.N).Cmp(FDHHashWith(h, msg, pub.N)) != 0 {
+		return fmt.Errorf("VerifyFDHWith: signature does not match the message")
+	}
+	return nil
+}
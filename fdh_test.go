@@ -0,0 +1,47 @@
+package rsa_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestSignVerifyFDH(t *testing.T) {
+	// Mersenne primes 2^127 - 1 and 2^89 - 1.
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	q := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 89), big.NewInt(1))
+	priv, err := rsa.NewPrivateKey(p, q, big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("attack at dawn")
+	sig, err := rsa.SignFDH(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyFDH(&priv.PublicKey, msg, sig); err != nil {
+		t.Error(err)
+	}
+
+	// Deterministic: signing again gives the same signature.
+	if again, _ := rsa.SignFDH(priv, msg); again.Cmp(sig) != 0 {
+		t.Error("FDH signatures are expected to be deterministic")
+	}
+	if err := rsa.VerifyFDH(&priv.PublicKey, []byte("attack at dusk"), sig); err == nil {
+		t.Error("expected the signature to fail for another message")
+	}
+}
+
+func TestFDHRejectsTinyModulus(t *testing.T) {
+	for _, n := range []int64{0, 1} {
+		priv := &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: big.NewInt(n), E: big.NewInt(3)}, D: big.NewInt(1)}
+		if _, err := rsa.SignFDH(priv, []byte("msg")); err == nil {
+			t.Errorf("signed with n = %v", n)
+		}
+		if err := rsa.VerifyFDH(&priv.PublicKey, []byte("msg"), big.NewInt(1)); err == nil {
+			t.Errorf("verified with n = %v", n)
+		}
+	}
+}
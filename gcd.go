@@ -0,0 +1,265 @@
+package rsa
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"math/bits"
+)
+
+// lehmerDigitBits is the size of the leading "digit" LehmerGCD simulates
+// the Euclidean steps on. Kept below 63 bits so the cosequence arithmetic
+// cannot overflow an int64.
+const lehmerDigitBits = 60
+
+// fastestGcd picks the gcd implementation for operands of up to bitLen bits
+// according to BenchmarkGcd: Stein's algorithm wins while the operands fit
+// a machine word, Lehmer's algorithm once they are multi-word.
+func fastestGcd(bitLen int) func(z, n1, n2 *big.Int) *big.Int {
+
+	if bitLen <= 64 {
+		return BinaryGCD
+	}
+	return LehmerGCD
+}
+
+// BinaryGCD sets z to the greatest common divisor of 2 numbers
+// calculated with Stein's algorithm and returns z.
+// It replaces divisions with shifts and subtractions: common factors
+// of 2 are pulled out first, then the odd difference is halved
+// until one of the operands reaches 0.
+// https://en.wikipedia.org/wiki/Binary_GCD_algorithm
+func BinaryGCD(z, n1, n2 *big.Int) *big.Int {
+
+	// Word sized operands, the Rho hot path, need no temporaries at all.
+	if n1.IsInt64() && n2.IsInt64() {
+		return z.SetUint64(binaryGCD64(absUint64(n1.Int64()), absUint64(n2.Int64())))
+	}
+
+	u := new(big.Int).Abs(n1)
+	v := new(big.Int).Abs(n2)
+	if u.Sign() == 0 {
+		return z.Set(v)
+	}
+	if v.Sign() == 0 {
+		return z.Set(u)
+	}
+
+	shift := min(u.TrailingZeroBits(), v.TrailingZeroBits())
+	u.Rsh(u, u.TrailingZeroBits())
+	for v.Sign() != 0 {
+		v.Rsh(v, v.TrailingZeroBits())
+		if u.Cmp(v) > 0 {
+			u, v = v, u
+		}
+		v.Sub(v, u) // both odd, so the difference is even
+	}
+	return z.Lsh(u, shift)
+}
+
+// absUint64 returns |n|, also for math.MinInt64.
+func absUint64(n int64) uint64 {
+
+	if n < 0 {
+		return -uint64(n)
+	}
+	return uint64(n)
+}
+
+// binaryGCD64 is Stein's algorithm on machine words.
+func binaryGCD64(u, v uint64) uint64 {
+
+	if u == 0 {
+		return v
+	}
+	if v == 0 {
+		return u
+	}
+
+	shift := min(bits.TrailingZeros64(u), bits.TrailingZeros64(v))
+	u >>= bits.TrailingZeros64(u)
+	for v != 0 {
+		v >>= bits.TrailingZeros64(v)
+		if u > v {
+			u, v = v, u
+		}
+		v -= u
+	}
+	return u << shift
+}
+
+// LehmerGCD sets z to the greatest common divisor of 2 numbers
+// calculated with Lehmer's algorithm and returns z.
+// While the operands are multi-word it runs the Euclidean steps on
+// their leading 60 bits only, accumulating the cosequence (a, b, c, d)
+// in int64s, and applies the combined steps to the full numbers at once.
+// Knuth, TAOCP Vol. 2, 4.5.2, Algorithm L.
+// https://en.wikipedia.org/wiki/Lehmer%27s_GCD_algorithm
+func LehmerGCD(z, n1, n2 *big.Int) *big.Int {
+
+	u := new(big.Int).Abs(n1)
+	v := new(big.Int).Abs(n2)
+	if u.Cmp(v) < 0 {
+		u, v = v, u
+	}
+
+	t := new(big.Int)
+	w := new(big.Int)
+	uHatBig := new(big.Int)
+	vHatBig := new(big.Int)
+	cosequence := new(big.Int)
+	for v.BitLen() > lehmerDigitBits {
+		shift := uint(u.BitLen() - lehmerDigitBits)
+		uHat := uHatBig.Rsh(u, shift).Int64()
+		vHat := vHatBig.Rsh(v, shift).Int64()
+
+		var a, b, c, d int64 = 1, 0, 0, 1
+		for vHat+c != 0 && vHat+d != 0 {
+			q := (uHat + a) / (vHat + c)
+			if q != (uHat+b)/(vHat+d) {
+				break
+			}
+			a, c = c, a-q*c
+			b, d = d, b-q*d
+			uHat, vHat = vHat, uHat-q*vHat
+		}
+
+		if b == 0 {
+			// No step could be simulated, fall back to one full precision step.
+			t.Mod(u, v)
+			u, v, t = v, t, u
+			continue
+		}
+		// u, v = a*u + b*v, c*u + d*v
+		t.Mul(u, cosequence.SetInt64(a))
+		t.Add(t, w.Mul(v, cosequence.SetInt64(b)))
+		w.Mul(u, cosequence.SetInt64(c))
+		u.Mul(v, cosequence.SetInt64(d))
+		v.Add(w, u)
+		u, t = t, u
+	}
+
+	for v.Sign() != 0 {
+		t.Mod(u, v)
+		u, v, t = v, t, u
+	}
+	return z.Set(u)
+}
+
+// GetExtBinaryGCD is the extended variant of Stein's algorithm: it sets z
+// to the gcd of positive a and b and, unless nil, x and y to the Bézout
+// coefficients such that a * x + b * y == z, returning z like big.Int's GCD.
+// Unlike BinaryGCD it keeps the coefficients in step with every halving,
+// which works for an even b too, e.g. a power of two.
+// Menezes et al., Handbook of Applied Cryptography, Algorithm 14.61.
+func GetExtBinaryGCD(z, x, y, a, b *big.Int) (*big.Int, error) {
+
+	if a.Sign() <= 0 || b.Sign() <= 0 {
+		return nil, fmt.Errorf("GetExtBinaryGCD: operands must be positive, got %v and %v", a, b)
+	}
+
+	aOdd := new(big.Int).Set(a)
+	bOdd := new(big.Int).Set(b)
+
+	// Common factors of 2 are put back into the gcd at the end.
+	shift := min(aOdd.TrailingZeroBits(), bOdd.TrailingZeroBits())
+	aOdd.Rsh(aOdd, shift)
+	bOdd.Rsh(bOdd, shift)
+
+	u := new(big.Int).Set(aOdd)
+	v := new(big.Int).Set(bOdd)
+	coefA, coefB := big.NewInt(1), big.NewInt(0) // u = coefA*aOdd + coefB*bOdd
+	coefC, coefD := big.NewInt(0), big.NewInt(1) // v = coefC*aOdd + coefD*bOdd
+
+	// halve divides r = s*aOdd + t*bOdd by 2 keeping the relation intact.
+	halve := func(r, s, t *big.Int) {
+		for r.Bit(0) == 0 {
+			r.Rsh(r, 1)
+			if s.Bit(0) != 0 || t.Bit(0) != 0 {
+				s.Add(s, bOdd)
+				t.Sub(t, aOdd)
+			}
+			s.Rsh(s, 1) // Rsh floors, exact here as s is even
+			t.Rsh(t, 1)
+		}
+	}
+
+	for u.Sign() != 0 {
+		halve(u, coefA, coefB)
+		halve(v, coefC, coefD)
+		if u.Cmp(v) >= 0 {
+			u.Sub(u, v)
+			coefA.Sub(coefA, coefC)
+			coefB.Sub(coefB, coefD)
+		} else {
+			v.Sub(v, u)
+			coefC.Sub(coefC, coefA)
+			coefD.Sub(coefD, coefB)
+		}
+	}
+
+	// Operands were copied, so the outputs may alias them.
+	if x != nil {
+		x.Set(coefC)
+	}
+	if y != nil {
+		y.Set(coefD)
+	}
+	return z.Lsh(v, shift), nil
+}
+
+// Convergents yields the convergents h/k of the continued fraction of a/b
+// for b != 0, from the integer part to a/b itself, each closer to a/b than
+// any fraction with a smaller denominator. They come from the quotients of
+// the Euclidean algorithm by h(i) = q*h(i-1) + h(i-2), likewise k. Wiener's
+// attack looks among the convergents of e/n for k/d.
+// https://en.wikipedia.org/wiki/Continued_fraction#Infinite_continued_fractions_and_convergents
+func Convergents(a, b *big.Int) iter.Seq[*big.Rat] {
+
+	return func(yield func(*big.Rat) bool) {
+
+		if b.Sign() == 0 {
+			return
+		}
+		num, den := new(big.Int).Set(a), new(big.Int).Set(b)
+		if den.Sign() < 0 {
+			num.Neg(num)
+			den.Neg(den)
+		}
+		h, hPrev := big.NewInt(1), big.NewInt(0)
+		k, kPrev := big.NewInt(0), big.NewInt(1)
+		q, r, t := new(big.Int), new(big.Int), new(big.Int)
+		for den.Sign() != 0 {
+			q.DivMod(num, den, r) // Euclidean: 0 <= r < den
+			h, hPrev = hPrev.Add(hPrev, t.Mul(q, h)), h
+			k, kPrev = kPrev.Add(kPrev, t.Mul(q, k)), k
+			if !yield(new(big.Rat).SetFrac(h, k)) {
+				return
+			}
+			num, den, r = den, r, num
+		}
+	}
+}
+
+// GetNegInverseModPow2 sets z to -n^-1 mod 2^k for an odd n, the constant
+// Montgomery reduction needs with R = 2^k, computed with GetExtBinaryGCD,
+// and returns z.
+// https://en.wikipedia.org/wiki/Montgomery_modular_multiplication
+func GetNegInverseModPow2(z, n *big.Int, k uint) (*big.Int, error) {
+
+	if n.Bit(0) == 0 {
+		return nil, fmt.Errorf("GetNegInverseModPow2: n (%v) must be odd to be invertible modulo 2^%v", n, k)
+	}
+	if k == 0 {
+		return z.SetInt64(0), nil
+	}
+
+	pow2 := new(big.Int).Lsh(big.NewInt(1), k)
+	nMod := new(big.Int).Mod(n, pow2)
+	x := new(big.Int)
+	if _, err := GetExtBinaryGCD(new(big.Int), x, nil, nMod, pow2); err != nil {
+		return nil, err
+	}
+	x.Neg(x)
+	return ModEuclid(z, x, pow2), nil
+}
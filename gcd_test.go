@@ -0,0 +1,195 @@
+package rsa_test
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+// gcdFuncs are the gcd implementations compared by the tests and benchmarks.
+var gcdFuncs = []struct {
+	name string
+	gcd  func(z, n1, n2 *big.Int) *big.Int
+}{
+	{"GetGcd", rsa.GetGcd},
+	{"BinaryGCD", rsa.BinaryGCD},
+	{"LehmerGCD", rsa.LehmerGCD},
+}
+
+// randomGcdOperands returns 2 random operands of the given bit size
+// sharing a random common factor of about a quarter of that size.
+func randomGcdOperands(rnd *rand.Rand, bitSize int) (*big.Int, *big.Int) {
+
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bitSize))
+	common := new(big.Int).Rand(rnd, new(big.Int).Rsh(limit, uint(bitSize*3/4)))
+	common.Add(common, big.NewInt(1))
+
+	n1 := new(big.Int).Rand(rnd, limit)
+	n2 := new(big.Int).Rand(rnd, limit)
+	n1.Mul(n1, common)
+	n2.Mul(n2, common)
+	return n1, n2
+}
+
+func TestGcdImplementations(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for _, bitSize := range []int{8, 64, 200, 1024} {
+		for i := 0; i < 50; i++ {
+			n1, n2 := randomGcdOperands(rnd, bitSize)
+			expected := new(big.Int).GCD(nil, nil, n1, n2)
+			for _, f := range gcdFuncs {
+				if got := f.gcd(new(big.Int), n1, n2); got.Cmp(expected) != 0 {
+					t.Errorf("%v(%v, %v) = %v, expected %v", f.name, n1, n2, got, expected)
+				}
+				// The destination may alias an operand.
+				n1Copy := new(big.Int).Set(n1)
+				if got := f.gcd(n1Copy, n1Copy, n2); got.Cmp(expected) != 0 {
+					t.Errorf("%v with aliased destination = %v, expected %v", f.name, got, expected)
+				}
+			}
+		}
+	}
+
+	// Zero and negative operands.
+	for _, f := range []func(z, n1, n2 *big.Int) *big.Int{rsa.BinaryGCD, rsa.LehmerGCD} {
+		if got := f(new(big.Int), big.NewInt(0), big.NewInt(12)); got.Int64() != 12 {
+			t.Errorf("gcd(0, 12) = %v, expected 12", got)
+		}
+		if got := f(new(big.Int), big.NewInt(-18), big.NewInt(12)); got.Int64() != 6 {
+			t.Errorf("gcd(-18, 12) = %v, expected 6", got)
+		}
+	}
+}
+
+func BenchmarkGcd(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+
+	for _, bitSize := range []int{48, 256, 1024, 4096} {
+		n1, n2 := randomGcdOperands(rnd, bitSize)
+		for _, f := range gcdFuncs {
+			b.Run(fmt.Sprintf("%v/%d", f.name, bitSize), func(b *testing.B) {
+				z := new(big.Int)
+				for i := 0; i < b.N; i++ {
+					f.gcd(z, n1, n2)
+				}
+			})
+		}
+	}
+}
+
+func TestGetExtBinaryGCD(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 100; i++ {
+		a, b := randomGcdOperands(rnd, 128)
+		a.Add(a, big.NewInt(1))
+		b.Add(b, big.NewInt(1))
+
+		x, y := new(big.Int), new(big.Int)
+		gcd, err := rsa.GetExtBinaryGCD(new(big.Int), x, y, a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := new(big.Int).GCD(nil, nil, a, b); gcd.Cmp(expected) != 0 {
+			t.Errorf("gcd(%v, %v) = %v, expected %v", a, b, gcd, expected)
+		}
+		bezout := new(big.Int).Mul(a, x)
+		bezout.Add(bezout, new(big.Int).Mul(b, y))
+		if bezout.Cmp(gcd) != 0 {
+			t.Errorf("%v * %v + %v * %v = %v, expected %v", a, x, b, y, bezout, gcd)
+		}
+	}
+
+	if _, err := rsa.GetExtBinaryGCD(new(big.Int), nil, nil, big.NewInt(0), big.NewInt(5)); err == nil {
+		t.Error("expected an error for a zero operand")
+	}
+}
+
+func TestGetNegInverseModPow2(t *testing.T) {
+	n := big.NewInt(937513)
+
+	for _, k := range []uint{1, 8, 32, 64, 256} {
+		nPrime, err := rsa.GetNegInverseModPow2(new(big.Int), n, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// n * n' == -1 mod 2^k
+		pow2 := new(big.Int).Lsh(big.NewInt(1), k)
+		check := new(big.Int).Mul(n, nPrime)
+		check.Add(check, big.NewInt(1))
+		if check.Mod(check, pow2).Sign() != 0 {
+			t.Errorf("n' = %v is not -n^-1 mod 2^%v", nPrime, k)
+		}
+	}
+
+	if _, err := rsa.GetNegInverseModPow2(new(big.Int), big.NewInt(10), 8); err == nil {
+		t.Error("expected an error for an even n")
+	}
+}
+
+// valueGetGcd is GetGcd as it was before the destination redesign:
+// value parameters copied on every call and a new big.Int per modulus.
+func valueGetGcd(n1, n2 big.Int) *big.Int {
+
+	n1Copy := new(big.Int).Set(&n1)
+	n2Copy := new(big.Int).Set(&n2)
+	valueMod := func(a, b big.Int) big.Int {
+		return *new(big.Int).Mod(&a, &b)
+	}
+
+	for n1n2Mod := valueMod(*n1Copy, *n2Copy); n1n2Mod.Sign() != 0; {
+		n1Copy.Set(n2Copy)
+		n2Copy.Set(&n1n2Mod)
+		n1n2Mod = valueMod(*n1Copy, *n2Copy)
+	}
+	return n2Copy
+}
+
+// BenchmarkGetGcdAllocs shows the allocations saved by the destination
+// parameter, run with -benchmem.
+func BenchmarkGetGcdAllocs(b *testing.B) {
+	n1, n2 := randomGcdOperands(rand.New(rand.NewSource(1)), 1024)
+
+	b.Run("values", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			valueGetGcd(*n1, *n2)
+		}
+	})
+	b.Run("destination", func(b *testing.B) {
+		b.ReportAllocs()
+		z := new(big.Int)
+		for i := 0; i < b.N; i++ {
+			rsa.GetGcd(z, n1, n2)
+		}
+	})
+}
+
+func TestConvergents(t *testing.T) {
+	// 415/93 = [4; 2, 6, 7].
+	want := []string{"4/1", "9/2", "58/13", "415/93"}
+	var got []string
+	for r := range rsa.Convergents(big.NewInt(415), big.NewInt(93)) {
+		got = append(got, r.String())
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("convergents of 415/93 are %v, expected %v", got, want)
+	}
+
+	// The negative denominator is moved to the numerator, floors included.
+	got = got[:0]
+	for r := range rsa.Convergents(big.NewInt(7), big.NewInt(-2)) {
+		got = append(got, r.String())
+	}
+	if fmt.Sprint(got) != "[-4/1 -7/2]" {
+		t.Errorf("convergents of 7/-2 are %v, expected [-4/1 -7/2]", got)
+	}
+
+	for r := range rsa.Convergents(big.NewInt(1), new(big.Int)) {
+		t.Errorf("yielded %v for a zero denominator", r)
+	}
+}
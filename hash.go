@@ -0,0 +1,118 @@
+package rsa
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+	"sync"
+
+	"github.com/nethatix/rsa/asn1edu"
+)
+
+// Hash is a named hash function the padding, signature and fingerprint
+// functions accept interchangeably.
+type Hash struct {
+	Name string
+	New  func() hash.Hash
+}
+
+// Hashes registered by default. BLAKE2 lives outside the standard
+// library, in golang.org/x/crypto/blake2b; register it by wrapping
+// blake2b.New256 with RegisterHash.
+var (
+	SHA256   = Hash{Name: "sha256", New: sha256.New}
+	SHA3_256 = Hash{Name: "sha3-256", New: func() hash.Hash { return sha3.New256() }}
+	// ToyHash is a deliberately broken 16 bit hash for forgery exercises:
+	// it XORs the bytes at even and at odd positions together, so any
+	// message whose bytes are permuted within the same parity, or has two
+	// equal bytes of the same parity added, collides with the original,
+	// and a signature of one verifies for the other. Never sign with it.
+	ToyHash = Hash{Name: "toy", New: newToyHash}
+)
+
+var (
+	hashesMu sync.RWMutex
+	hashes   = map[string]Hash{}
+)
+
+func init() {
+
+	for _, h := range []Hash{SHA256, SHA3_256, ToyHash} {
+		RegisterHash(h)
+	}
+}
+
+// RegisterHash makes h available to LookupHash under its name,
+// replacing a hash registered earlier under the same name.
+func RegisterHash(h Hash) {
+
+	hashesMu.Lock()
+	defer hashesMu.Unlock()
+	hashes[h.Name] = h
+}
+
+// LookupHash returns the hash registered under name.
+func LookupHash(name string) (Hash, error) {
+
+	hashesMu.RLock()
+	defer hashesMu.RUnlock()
+	h, ok := hashes[name]
+	if !ok {
+		return Hash{}, fmt.Errorf("LookupHash: no hash registered as %q", name)
+	}
+	return h, nil
+}
+
+// HashNames returns the names of the registered hashes, sorted.
+func HashNames() []string {
+
+	hashesMu.RLock()
+	defer hashesMu.RUnlock()
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Fingerprint returns the hexadecimal hash h of pub's PKCS#1 DER encoding,
+// a short name to compare keys by.
+func Fingerprint(pub *PublicKey, h Hash) (string, error) {
+
+	der, err := (&asn1edu.RSAPublicKey{N: pub.N, E: pub.E}).MarshalDER()
+	if err != nil {
+		return "", fmt.Errorf("Fingerprint: %v", err)
+	}
+	digest := h.New()
+	digest.Write(der)
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// toyHash is the hash.Hash of ToyHash.
+type toyHash struct {
+	sum [2]byte
+	n   int
+}
+
+func newToyHash() hash.Hash {
+
+	return &toyHash{}
+}
+
+func (h *toyHash) Write(p []byte) (int, error) {
+
+	for _, b := range p {
+		h.sum[h.n%2] ^= b
+		h.n++
+	}
+	return len(p), nil
+}
+
+func (h *toyHash) Sum(b []byte) []byte { return append(b, h.sum[:]...) }
+func (h *toyHash) Reset()              { *h = toyHash{} }
+func (h *toyHash) Size() int           { return len(h.sum) }
+func (h *toyHash) BlockSize() int      { return 1 }
@@ -0,0 +1,70 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"slices"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestHashRegistry(t *testing.T) {
+	for _, name := range []string{"sha256", "sha3-256", "toy"} {
+		if !slices.Contains(rsa.HashNames(), name) {
+			t.Errorf("%v is not registered", name)
+		}
+	}
+	if _, err := rsa.LookupHash("md4"); err == nil {
+		t.Error("expected an error for an unregistered hash")
+	}
+
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("attack at dawn")
+	fingerprints := map[string]bool{}
+	for _, name := range rsa.HashNames() {
+		h, err := rsa.LookupHash(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := rsa.SignFDHWith(h, priv, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rsa.VerifyFDHWith(h, &priv.PublicKey, msg, sig); err != nil {
+			t.Errorf("%v: %v", name, err)
+		}
+		fingerprint, err := rsa.Fingerprint(&priv.PublicKey, h)
+		if err != nil || len(fingerprint) != 2*h.New().Size() {
+			t.Errorf("%v: fingerprint %v (%v)", name, fingerprint, err)
+		}
+		fingerprints[fingerprint] = true
+	}
+	if len(fingerprints) != len(rsa.HashNames()) {
+		t.Error("expected distinct fingerprints for distinct hashes")
+	}
+}
+
+func TestToyHashForgery(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := []byte("pay 100 to bob")
+	sig, err := rsa.SignFDHWith(rsa.ToyHash, priv, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Swapping bytes 4 and 6 keeps the XOR of the even positions.
+	forged := []byte("pay 001 to bob")
+	if err := rsa.VerifyFDHWith(rsa.ToyHash, &priv.PublicKey, forged, sig); err != nil {
+		t.Errorf("expected the toy hash signature to verify for %q: %v", forged, err)
+	}
+	if err := rsa.VerifyFDHWith(rsa.SHA256, &priv.PublicKey, forged, sig); err == nil {
+		t.Error("expected the forgery to fail with SHA-256")
+	}
+}
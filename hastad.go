@@ -0,0 +1,129 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Ciphertext is an intercepted textbook RSA ciphertext with the public key
+// it was encrypted under.
+type Ciphertext struct {
+	Key PublicKey
+	C   *big.Int
+}
+
+// CRT returns the x in [0, M) with x = residues[i] mod moduli[i] for all i,
+// and M, the product of the pairwise coprime moduli, by Gauss's formula
+// x = sum of residues[i] * M_i * (M_i^-1 mod moduli[i]) with M_i = M / moduli[i].
+// https://en.wikipedia.org/wiki/Chinese_remainder_theorem
+func CRT(residues, moduli []*big.Int) (*big.Int, *big.Int, error) {
+
+	if len(residues) != len(moduli) || len(moduli) == 0 {
+		return nil, nil, fmt.Errorf("CRT: need as many residues as moduli, got %v and %v", len(residues), len(moduli))
+	}
+
+	product := big.NewInt(1)
+	for _, m := range moduli {
+		product.Mul(product, m)
+	}
+
+	x := new(big.Int)
+	mi, inv, term := new(big.Int), new(big.Int), new(big.Int)
+	for i, m := range moduli {
+		mi.Div(product, m)
+		if inv.ModInverse(mi, m) == nil {
+			return nil, nil, fmt.Errorf("CRT: modulus %v is not coprime to the others", m)
+		}
+		term.Mul(residues[i], mi)
+		term.Mul(term, inv)
+		x.Add(x, term)
+	}
+	return x.Mod(x, product), product, nil
+}
+
+// HastadBroadcast recovers a plaintext m encrypted without padding under
+// e different keys sharing the small public exponent e. The CRT turns
+// the e ciphertexts into m^e modulo the product of the moduli, and since
+// m is below every modulus, m^e is below their product: that residue is
+// m^e itself, whose ordinary integer e-th root is m.
+// Only the first e ciphertexts are used.
+// https://en.wikipedia.org/wiki/Coppersmith%27s_attack#H%C3%A5stad%27s_broadcast_attack
+func HastadBroadcast(cts []Ciphertext) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if len(cts) == 0 || !cts[0].Key.E.IsInt64() || cts[0].Key.E.Int64() > int64(len(cts)) {
+		return nil, meter.done(), fmt.Errorf("HastadBroadcast: need at least e ciphertexts")
+	}
+	e := cts[0].Key.E
+	k := int(e.Int64())
+
+	residues, moduli := make([]*big.Int, k), make([]*big.Int, k)
+	for i, ct := range cts[:k] {
+		if ct.Key.E.Cmp(e) != 0 {
+			return nil, meter.done(), fmt.Errorf("HastadBroadcast: ciphertext %v has e = %v, expected %v", i, ct.Key.E, e)
+		}
+		residues[i], moduli[i] = ct.C, ct.Key.N
+	}
+
+	power, _, err := CRT(residues, moduli)
+	meter.cost.ModMuls += 2 * k
+	if err != nil {
+		return nil, meter.done(), fmt.Errorf("HastadBroadcast: %v", err)
+	}
+	m := nthRoot(new(big.Int), power, uint(k))
+	meter.exp(e)
+	if new(big.Int).Exp(m, e, nil).Cmp(power) != 0 {
+		return nil, meter.done(), fmt.Errorf("HastadBroadcast: CRT result is not a perfect %v-th power, the plaintexts differ", k)
+	}
+	return m, meter.done(), nil
+}
+
+// CommonModulusAttack recovers a plaintext encrypted under the same modulus
+// n with 2 coprime exponents: with Bezout's a*e1 + b*e2 = 1,
+// c1^a * c2^b = m^(a*e1 + b*e2) = m mod n.
+// https://crypto.stackexchange.com/questions/16283/how-to-use-common-modulus-attack
+func CommonModulusAttack(n, e1, c1, e2, c2 *big.Int) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, e1, e2).Cmp(big.NewInt(1)) != 0 {
+		return nil, meter.done(), fmt.Errorf("CommonModulusAttack: exponents %v and %v are not coprime", e1, e2)
+	}
+
+	// A negative Bezout coefficient raises the inverse of its ciphertext.
+	x := new(big.Int).Exp(c1, a, n)
+	y := new(big.Int).Exp(c2, b, n)
+	meter.exp(new(big.Int).Abs(a))
+	meter.exp(new(big.Int).Abs(b))
+	if x == nil || y == nil {
+		return nil, meter.done(), fmt.Errorf("CommonModulusAttack: a ciphertext is not invertible modulo n")
+	}
+	x.Mul(x, y)
+	meter.cost.ModMuls++
+	return x.Mod(x, n), meter.done(), nil
+}
+
+// nthRoot sets z to the integer k-th root floor(x^(1/k)) of x >= 0 by
+// Newton's method and returns z.
+func nthRoot(z, x *big.Int, k uint) *big.Int {
+
+	if x.Sign() == 0 {
+		return z.SetInt64(0)
+	}
+
+	kBig := big.NewInt(int64(k))
+	kMinus1 := big.NewInt(int64(k) - 1)
+	// 2^ceil(bits/k) is above the root, Newton's iterates then decrease to it.
+	r := new(big.Int).Lsh(big.NewInt(1), (uint(x.BitLen())+k-1)/k)
+	next, t := new(big.Int), new(big.Int)
+	for {
+		t.Exp(r, kMinus1, nil)
+		next.Div(x, t)
+		next.Add(next, t.Mul(r, kMinus1))
+		next.Div(next, kBig)
+		if next.Cmp(r) >= 0 {
+			return z.Set(r)
+		}
+		r.Set(next)
+	}
+}
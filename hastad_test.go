@@ -0,0 +1,143 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/nethatix/rsa"
+)
+
+// smallExponentKeys returns count keys of bits bits with public exponent e.
+func smallExponentKeys(t *testing.T, count, bits int, e int64) []*rsa.PrivateKey {
+
+	keys := []*rsa.PrivateKey{}
+	for len(keys) < count {
+		priv, err := rsa.GenerateKeyPair(rand.Reader, bits, big.NewInt(e))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, priv)
+	}
+	return keys
+}
+
+// encrypt returns m encrypted under priv's public key.
+func encrypt(priv *rsa.PrivateKey, m *big.Int) rsa.Ciphertext {
+
+	return rsa.Ciphertext{Key: priv.PublicKey, C: new(big.Int).Exp(m, priv.E, priv.N)}
+}
+
+func TestCRT(t *testing.T) {
+	// Sunzi's x = 2 mod 3, 3 mod 5, 2 mod 7.
+	x, m, err := rsa.CRT([]*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(2)},
+		[]*big.Int{big.NewInt(3), big.NewInt(5), big.NewInt(7)})
+	if err != nil || x.Int64() != 23 || m.Int64() != 105 {
+		t.Errorf("CRT = %v, %v, %v, expected 23, 105", x, m, err)
+	}
+	if _, _, err := rsa.CRT([]*big.Int{big.NewInt(1), big.NewInt(1)},
+		[]*big.Int{big.NewInt(4), big.NewInt(6)}); err == nil {
+		t.Error("expected an error for moduli sharing a factor")
+	}
+}
+
+func TestHastadBroadcast(t *testing.T) {
+	keys := smallExponentKeys(t, 3, 256, 3)
+	m, _ := rsa.Encode("broadcast", rsa.Base256Alphabet)
+
+	cts := []rsa.Ciphertext{}
+	for _, priv := range keys {
+		cts = append(cts, encrypt(priv, m))
+	}
+	got, _, err := rsa.HastadBroadcast(cts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(m) != 0 {
+		t.Errorf("recovered %v, expected %v", got, m)
+	}
+
+	cts[2] = encrypt(keys[2], big.NewInt(42))
+	if _, _, err := rsa.HastadBroadcast(cts); err == nil {
+		t.Error("expected an error for different plaintexts")
+	}
+	if _, _, err := rsa.HastadBroadcast(cts[:2]); err == nil {
+		t.Error("expected an error for fewer than e ciphertexts")
+	}
+}
+
+func TestCommonModulusAttack(t *testing.T) {
+	keys := smallExponentKeys(t, 1, 256, 17)
+	priv := keys[0]
+	m := big.NewInt(123456789)
+	c1 := new(big.Int).Exp(m, priv.E, priv.N)
+	e2 := big.NewInt(65537)
+	c2 := new(big.Int).Exp(m, e2, priv.N)
+
+	got, _, err := rsa.CommonModulusAttack(priv.N, priv.E, c1, e2, c2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(m) != 0 {
+		t.Errorf("recovered %v, expected %v", got, m)
+	}
+}
+
+func TestCorrelateCiphertexts(t *testing.T) {
+	keys := smallExponentKeys(t, 4, 256, 3)
+	broadcast := big.NewInt(31337)
+	other := big.NewInt(271828)
+	sameN := rsa.PublicKey{N: keys[0].N, E: big.NewInt(5)}
+	shared := big.NewInt(161803)
+
+	cts := []rsa.Ciphertext{
+		encrypt(keys[0], broadcast),
+		encrypt(keys[1], other),
+		encrypt(keys[1], broadcast),
+		encrypt(keys[2], broadcast),
+		encrypt(keys[3], broadcast),
+		encrypt(keys[0], shared),
+		{Key: sameN, C: new(big.Int).Exp(shared, sameN.E, sameN.N)},
+		encrypt(keys[1], other),
+	}
+
+	groups := rsa.CorrelateCiphertexts(cts)
+	if len(groups) != 3 {
+		t.Fatalf("found %v groups: %+v", len(groups), groups)
+	}
+	want := []struct {
+		indexes   string
+		attack    string
+		plaintext *big.Int
+	}{
+		{"[5 6]", rsa.AttackCommonModulus, shared},
+		{"[0 2 3 4]", rsa.AttackHastad + " + " + rsa.AttackReEncryptMatch, broadcast},
+		{"[1 7]", rsa.AttackNone, nil},
+	}
+	for i, w := range want {
+		g := groups[i]
+		if got := fmt.Sprint(g.Indexes); got != w.indexes || g.Attack != w.attack ||
+			(w.plaintext == nil) != (g.Plaintext == nil) || (w.plaintext != nil && g.Plaintext.Cmp(w.plaintext) != 0) {
+			t.Errorf("group %v = %v %q %v, expected %v %q %v", i, g.Indexes, g.Attack, g.Plaintext, w.indexes, w.attack, w.plaintext)
+		}
+	}
+}
+
+func TestCorrelateManyCiphertexts(t *testing.T) {
+	keys := smallExponentKeys(t, 1000, 64, 3)
+	cts := make([]rsa.Ciphertext, len(keys))
+	for i, key := range keys {
+		cts[i] = encrypt(key, big.NewInt(int64(1000+i)))
+	}
+
+	start := time.Now()
+	groups := rsa.CorrelateCiphertexts(cts)
+	if len(groups) != 0 {
+		t.Errorf("found groups among unrelated plaintexts: %+v", groups)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("correlating %v ciphertexts took %v", len(cts), elapsed)
+	}
+}
@@ -0,0 +1,133 @@
+package rsa
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CiphertextPlaceholder is replaced by the queried ciphertext, in lowercase
+// hexadecimal, in the URL and body template of an HTTPOracle.
+const CiphertextPlaceholder = "{{ciphertext}}"
+
+// HTTPOracle is an Oracle answered by a remote server, so that the oracle
+// attacks can be pointed at a deliberately vulnerable test service.
+// Only use it against servers you are authorized to test.
+type HTTPOracle struct {
+	// Client sends the requests, http.DefaultClient if nil.
+	Client *http.Client
+	// Method is the HTTP method, POST if empty.
+	Method string
+	// URL and Body are request templates containing CiphertextPlaceholder.
+	URL  string
+	Body string
+	// Header is added to every request.
+	Header http.Header
+	// Match turns a response into the oracle's answer, e.g. the parity bit.
+	Match func(status int, body []byte) (*big.Int, error)
+	// Interval is the minimum time between 2 requests, 0 for no limit.
+	Interval time.Duration
+	// Retries is how often a request failing in transport or with a 5xx
+	// status is repeated, waiting Interval, or 100ms if 0, in between.
+	Retries int
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// StatusMatcher answers 1 when the response status is status and 0
+// otherwise, for servers whose error code reveals the oracle bit.
+func StatusMatcher(status int) func(int, []byte) (*big.Int, error) {
+
+	return func(got int, _ []byte) (*big.Int, error) {
+		if got == status {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	}
+}
+
+// BodyMatcher answers 1 when the response body contains yes and 0 when it
+// contains no; any other body is an error.
+func BodyMatcher(yes, no string) func(int, []byte) (*big.Int, error) {
+
+	return func(_ int, body []byte) (*big.Int, error) {
+		switch {
+		case strings.Contains(string(body), yes):
+			return big.NewInt(1), nil
+		case strings.Contains(string(body), no):
+			return big.NewInt(0), nil
+		}
+		return nil, fmt.Errorf("response matches neither %q nor %q", yes, no)
+	}
+}
+
+// Query sends c to the server and returns Match's reading of the answer.
+func (o *HTTPOracle) Query(c *big.Int) (*big.Int, error) {
+
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	method := o.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	hex := c.Text(16)
+	url := strings.ReplaceAll(o.URL, CiphertextPlaceholder, hex)
+	body := strings.ReplaceAll(o.Body, CiphertextPlaceholder, hex)
+
+	var lastErr error
+	for attempt := 0; attempt <= o.Retries; attempt++ {
+		if attempt > 0 && o.Interval == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		o.wait()
+
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("HTTPOracle: %v", err)
+		}
+		for key, values := range o.Header {
+			req.Header[key] = values
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("server error %v", resp.Status)
+			continue
+		}
+
+		answer, err := o.Match(resp.StatusCode, respBody)
+		if err != nil {
+			return nil, fmt.Errorf("HTTPOracle: %v", err)
+		}
+		return answer, nil
+	}
+	return nil, fmt.Errorf("HTTPOracle: giving up after %v attempts: %v", o.Retries+1, lastErr)
+}
+
+// wait blocks until Interval has passed since the previous request.
+func (o *HTTPOracle) wait() {
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if next := o.last.Add(o.Interval); time.Now().Before(next) {
+		time.Sleep(time.Until(next))
+	}
+	o.last = time.Now()
+}
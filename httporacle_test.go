@@ -0,0 +1,75 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestHTTPOracle(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parity := rsa.NewParityOracle(priv)
+
+	// A vulnerable server telling whether the decryption is even,
+	// failing every 10th request to exercise the retries.
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1)%10 == 0 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var hex string
+		fmt.Sscanf(string(body), "c=%s", &hex)
+		c, ok := new(big.Int).SetString(hex, 16)
+		if !ok {
+			http.Error(w, "bad ciphertext", http.StatusBadRequest)
+			return
+		}
+		bit, err := parity.Query(c)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if bit.Sign() == 0 {
+			fmt.Fprint(w, "even")
+		} else {
+			fmt.Fprint(w, "odd")
+		}
+	}))
+	defer server.Close()
+
+	oracle := &rsa.HTTPOracle{
+		URL:      server.URL + "/decrypt",
+		Body:     "c=" + rsa.CiphertextPlaceholder,
+		Match:    rsa.BodyMatcher("odd", "even"),
+		Interval: time.Millisecond,
+		Retries:  1,
+	}
+	m := big.NewInt(987654321)
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+
+	got, _, err := rsa.ParityAttack(&priv.PublicKey, c, oracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(m) != 0 {
+		t.Errorf("recovered %v, expected %v", got, m)
+	}
+
+	if _, err := oracle.Query(priv.N); err == nil {
+		t.Error("expected the server's rejection to surface")
+	}
+}
@@ -0,0 +1,78 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// KDF2 derives length bytes from the shared secret z as ISO 18033-2's KDF2
+// with SHA-256: the concatenation of SHA-256(z || counter) for a 32 bit
+// big-endian counter starting at 1. It panics if length is negative.
+// https://www.shoup.net/iso/std6.pdf
+func KDF2(z []byte, length int) []byte {
+
+	if length < 0 {
+		panic(fmt.Sprintf("KDF2: negative length %v", length))
+	}
+	out := make([]byte, 0, length+sha256.Size)
+	for counter := uint32(1); len(out) < length; counter++ {
+		var suffix [4]byte
+		binary.BigEndian.PutUint32(suffix[:], counter)
+		digest := sha256.New()
+		digest.Write(z)
+		digest.Write(suffix[:])
+		out = digest.Sum(out)
+	}
+	return out[:length]
+}
+
+// EncapsulateKEM is RSA-KEM's sender side: it draws a uniform z in [0, n)
+// from random, derives a keyLen byte key from z and returns the key with
+// the ciphertext z^e mod n. No padding is needed because z, unlike a
+// message, is uniformly random over the whole of Z_n; the key then
+// encrypts the actual data with a symmetric cipher.
+// https://datatracker.ietf.org/doc/html/rfc5990
+func EncapsulateKEM(random io.Reader, pub *PublicKey, keyLen int) (key []byte, c *big.Int, err error) {
+
+	if keyLen < 0 {
+		return nil, nil, fmt.Errorf("EncapsulateKEM: negative key length %v", keyLen)
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	z, err := rand.Int(random, pub.N)
+	if err != nil {
+		return nil, nil, fmt.Errorf("EncapsulateKEM: %v", err)
+	}
+	defer zeroizeInt(z)
+	secret := kemSecret(z, pub.N)
+	defer clear(secret)
+	return KDF2(secret, keyLen), new(big.Int).Exp(z, pub.E, pub.N), nil
+}
+
+// DecapsulateKEM recovers z = c^d mod n and derives the same key as
+// EncapsulateKEM. It is deterministic: equal ciphertexts give equal keys.
+func DecapsulateKEM(priv *PrivateKey, c *big.Int, keyLen int) ([]byte, error) {
+
+	if keyLen < 0 {
+		return nil, fmt.Errorf("DecapsulateKEM: negative key length %v", keyLen)
+	}
+	if c.Sign() < 0 || c.Cmp(priv.N) >= 0 {
+		return nil, fmt.Errorf("DecapsulateKEM: ciphertext out of range [0, n)")
+	}
+	z := new(big.Int).Exp(c, priv.D, priv.N)
+	defer zeroizeInt(z)
+	secret := kemSecret(z, priv.N)
+	defer clear(secret)
+	return KDF2(secret, keyLen), nil
+}
+
+// kemSecret is z as a big-endian byte string as long as n.
+func kemSecret(z, n *big.Int) []byte {
+
+	return z.FillBytes(make([]byte, (n.BitLen()+7)/8))
+}
@@ -0,0 +1,59 @@
+package rsa_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestKDF2(t *testing.T) {
+	z := []byte("shared secret")
+	first := sha256.Sum256(append(append([]byte{}, z...), 0, 0, 0, 1))
+
+	long := rsa.KDF2(z, 48)
+	if len(long) != 48 || !bytes.Equal(long[:32], first[:]) {
+		t.Errorf("KDF2 first block = %x, expected %x", long[:32], first)
+	}
+	if short := rsa.KDF2(z, 16); !bytes.Equal(short, long[:16]) {
+		t.Errorf("KDF2(z, 16) = %x is not a prefix of KDF2(z, 48)", short)
+	}
+}
+
+func TestKEM(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, c, err := rsa.EncapsulateKEM(rand.Reader, &priv.PublicKey, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rsa.DecapsulateKEM(priv, c, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("decapsulated %x, expected %x", got, key)
+	}
+
+	other, err := rsa.DecapsulateKEM(priv, new(big.Int).Add(c, big.NewInt(1)), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(other, key) {
+		t.Error("a different ciphertext gave the same key")
+	}
+
+	if _, _, err := rsa.EncapsulateKEM(nil, &priv.PublicKey, -1); err == nil {
+		t.Error("expected an error encapsulating a negative key length")
+	}
+	if _, err := rsa.DecapsulateKEM(priv, c, -1); err == nil {
+		t.Error("expected an error decapsulating a negative key length")
+	}
+}
@@ -0,0 +1,166 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// GenerateKeyPair returns a canonical key pair with a modulus of exactly
+// bits bits and public exponent e, e.g. 3, 17 or 65537. A prime p for
+// which e is not coprime to p-1 is redrawn, so that gcd(e, λ(n)) = 1.
+// A nil e is replaced by a random odd exponent coprime to λ(n), the way
+// the first RSA paper picked it.
+// https://en.wikipedia.org/wiki/RSA_(cryptosystem)#Key_generation
+func GenerateKeyPair(random io.Reader, bits int, e *big.Int) (*PrivateKey, error) {
+
+	if bits < 16 {
+		return nil, fmt.Errorf("GenerateKeyPair: %v bits is too small", bits)
+	}
+	if e != nil && (e.Cmp(big.NewInt(3)) < 0 || e.Bit(0) == 0) {
+		return nil, fmt.Errorf("GenerateKeyPair: e (%v) must be odd and at least 3", e)
+	}
+
+	prime := func(bits int) (*big.Int, error) {
+
+		for {
+			p, err := randomPrime(random, bits)
+			if err != nil {
+				return nil, err
+			}
+			pMinus1 := new(big.Int).Sub(p, big.NewInt(1))
+			coprime := e == nil || new(big.Int).GCD(nil, nil, e, pMinus1).Cmp(big.NewInt(1)) == 0
+			zeroizeInt(pMinus1)
+			if coprime {
+				return p, nil
+			}
+			zeroizeInt(p)
+		}
+	}
+
+	for {
+		p, err := prime(bits - bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := prime(bits / 2)
+		if err != nil {
+			zeroizeInt(p)
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			zeroizeInt(p)
+			zeroizeInt(q)
+			continue
+		}
+
+		pubE := e
+		if pubE == nil {
+			lambda := carmichael(new(big.Int), p, q)
+			pubE, err = randomExponent(random, lambda)
+			zeroizeInt(lambda)
+			if err != nil {
+				zeroizeInt(p)
+				zeroizeInt(q)
+				return nil, err
+			}
+		}
+		// NewPrivateKey keeps copies, the candidates are wiped either way.
+		priv, err := NewPrivateKey(p, q, pubE)
+		zeroizeInt(p)
+		zeroizeInt(q)
+		return priv, err
+	}
+}
+
+// RandomPrime returns a prime of exactly bits bits, drawing from random
+// or crypto/rand.Reader if nil. Unlike crypto/rand.Prime, the same random
+// bytes always give the same prime, so seeded generation is reproducible.
+func RandomPrime(random io.Reader, bits int) (*big.Int, error) {
+
+	if bits < 2 {
+		return nil, fmt.Errorf("RandomPrime: %v bits is too small for a prime", bits)
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	p, err := randomPrime(random, bits)
+	if err != nil {
+		return nil, fmt.Errorf("RandomPrime: %v", err)
+	}
+	return p, nil
+}
+
+// randomPrime is crypto/rand.Prime drawing from random itself: since
+// Go 1.26 Prime ignores a custom reader, which would make key generation
+// impossible to reproduce from a recorded Session.
+func randomPrime(random io.Reader, bits int) (*big.Int, error) {
+
+	buf := make([]byte, (bits+7)/8)
+	defer clear(buf)
+	top := uint(bits % 8)
+	if top == 0 {
+		top = 8
+	}
+	p := new(big.Int)
+	for {
+		if _, err := io.ReadFull(random, buf); err != nil {
+			zeroizeInt(p)
+			return nil, err
+		}
+		// Keep bits bits and set the top 2 so a product of 2 primes
+		// is never a bit short, then make the candidate odd.
+		buf[0] &= byte(1<<top - 1)
+		p.SetBytes(buf)
+		p.SetBit(p, bits-1, 1)
+		p.SetBit(p, bits-2, 1)
+		p.SetBit(p, 0, 1)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
+// randomExponent returns a random odd e in [3, lambda) coprime to lambda.
+func randomExponent(random io.Reader, lambda *big.Int) (*big.Int, error) {
+
+	limit := new(big.Int).Sub(lambda, big.NewInt(3))
+	gcd := new(big.Int)
+	for {
+		e, err := rand.Int(random, limit)
+		if err != nil {
+			return nil, err
+		}
+		e.Add(e, big.NewInt(3))
+		if e.Bit(0) == 1 && gcd.GCD(nil, nil, e, lambda).Cmp(big.NewInt(1)) == 0 {
+			return e, nil
+		}
+	}
+}
+
+// ExplainExponent describes the tradeoffs of public exponent e.
+func ExplainExponent(e *big.Int) string {
+
+	switch {
+	case e.Cmp(big.NewInt(3)) < 0 || e.Bit(0) == 0:
+		return fmt.Sprintf("e = %v is invalid: e must be odd and at least 3 to be invertible modulo the even λ(n).", e)
+	case e.Cmp(big.NewInt(17)) <= 0:
+		return fmt.Sprintf("e = %v makes encryption and verification a few multiplications, but without padding "+
+			"a message m with m^e < n is recovered by an integer e-th root, and the same message sent to e "+
+			"recipients falls to Håstad's broadcast attack.", e)
+	case e.Cmp(big.NewInt(65537)) < 0:
+		return fmt.Sprintf("e = %v is cheap to encrypt with but still small: without padding a short "+
+			"message m with m^e < n is recovered by an integer e-th root, and the same message sent to e "+
+			"recipients falls to Håstad's broadcast attack, only needing more of them.", e)
+	case e.Cmp(big.NewInt(65537)) == 0:
+		return "e = 65537 = 2^16 + 1 is the common default: 17 multiplications to encrypt, " +
+			"prime, and large enough to rule out the small exponent attacks."
+	case e.Cmp(big.NewInt(65537)) > 0 && e.BitLen() <= 32:
+		return fmt.Sprintf("e = %v is a small exponent: cheap to encrypt with and, being above 65537, "+
+			"out of reach of the small exponent attacks.", e)
+	}
+	return fmt.Sprintf("e = %v is a large exponent: encryption costs as much as decryption without CRT, and "+
+		"nothing is gained, security rests on d, which stays large for any e. A random e does keep "+
+		"d from being chosen small, which Wiener's attack would break.", e)
+}
@@ -0,0 +1,67 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestGenerateKeyPair(t *testing.T) {
+	for _, e := range []*big.Int{big.NewInt(3), big.NewInt(17), big.NewInt(65537), nil} {
+		priv, err := rsa.GenerateKeyPair(rand.Reader, 255, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv.N.BitLen() != 255 {
+			t.Errorf("e = %v: modulus of %v bits", e, priv.N.BitLen())
+		}
+		if e != nil && priv.E.Cmp(e) != 0 {
+			t.Errorf("e = %v: key has e = %v", e, priv.E)
+		}
+		if err := rsatest.CheckKeyPair(priv); err != nil {
+			t.Errorf("e = %v: %v", e, err)
+		}
+	}
+
+	for _, e := range []int64{1, 2, 65536} {
+		if _, err := rsa.GenerateKeyPair(rand.Reader, 256, big.NewInt(e)); err == nil {
+			t.Errorf("e = %v accepted", e)
+		}
+	}
+}
+
+func TestRandomPrime(t *testing.T) {
+	for _, bits := range []int{2, 17, 64} {
+		p, err := rsa.RandomPrime(mathrand.NewChaCha8([32]byte{2}), bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := rsa.RandomPrime(mathrand.NewChaCha8([32]byte{2}), bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.BitLen() != bits || !p.ProbablyPrime(20) || p.Cmp(q) != 0 {
+			t.Errorf("%v bits: got %v and %v from the same seed", bits, p, q)
+		}
+	}
+	if _, err := rsa.RandomPrime(nil, 1); err == nil {
+		t.Error("expected an error for 1 bit")
+	}
+}
+
+func TestExplainExponent(t *testing.T) {
+	for e, want := range map[int64]string{2: "invalid", 3: "Håstad", 257: "still small", 65537: "default", 65539: "above 65537", 1<<20 + 1: "small", 1 << 62: "invalid"} {
+		if got := rsa.ExplainExponent(big.NewInt(e)); !strings.Contains(got, want) {
+			t.Errorf("ExplainExponent(%v) = %q, expected it to mention %q", e, got, want)
+		}
+	}
+	large := new(big.Int).Lsh(big.NewInt(1), 100)
+	if got := rsa.ExplainExponent(large.Add(large, big.NewInt(1))); !strings.Contains(got, "large") {
+		t.Errorf("ExplainExponent(2^100 + 1) = %q", got)
+	}
+}
@@ -0,0 +1,125 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// PublicKey is the disclosed RSA key (n, e).
+type PublicKey struct {
+	N *big.Int
+	E *big.Int
+}
+
+// PrivateKey is the RSA key (n, d) together with the prime factors of n
+// and the Chinese Remainder Theorem parameters derived from them:
+// Dp = d mod (p-1), Dq = d mod (q-1) and Qinv = q^-1 mod p.
+type PrivateKey struct {
+	PublicKey
+	D            *big.Int
+	P, Q         *big.Int
+	Dp, Dq, Qinv *big.Int
+}
+
+// NewPrivateKey builds the canonical private key of primes p, q
+// and public exponent e.
+func NewPrivateKey(p, q, e *big.Int) (*PrivateKey, error) {
+
+	priv := &PrivateKey{
+		PublicKey: PublicKey{E: new(big.Int).Set(e)},
+		P:         new(big.Int).Set(p),
+		Q:         new(big.Int).Set(q),
+	}
+	if err := priv.Canonicalize(); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// Equal reports whether both public keys have the same n and e.
+func (pub *PublicKey) Equal(other *PublicKey) bool {
+
+	return other != nil && bigEqual(pub.N, other.N) && bigEqual(pub.E, other.E)
+}
+
+// Equal reports whether every field of both private keys is identical.
+// Keys recovered by different attacks may hold p and q swapped or
+// a d differing by a multiple of λ(n), Canonicalize both first to
+// compare them as keys rather than as representations.
+func (priv *PrivateKey) Equal(other *PrivateKey) bool {
+
+	return other != nil && priv.PublicKey.Equal(&other.PublicKey) &&
+		bigEqual(priv.D, other.D) &&
+		bigEqual(priv.P, other.P) && bigEqual(priv.Q, other.Q) &&
+		bigEqual(priv.Dp, other.Dp) && bigEqual(priv.Dq, other.Dq) &&
+		bigEqual(priv.Qinv, other.Qinv)
+}
+
+// Canonicalize recomputes every derived field from p, q and e:
+// it orders the primes so that p > q, sets n = p*q, the smallest
+// private exponent d = e^-1 mod λ(n), where λ(n) = lcm(p-1, q-1)
+// is Carmichael's totient, and the CRT parameters.
+// https://en.wikipedia.org/wiki/RSA_(cryptosystem)#Key_generation
+func (priv *PrivateKey) Canonicalize() error {
+
+	if priv.P == nil || priv.Q == nil || priv.E == nil {
+		return fmt.Errorf("Canonicalize: p, q and e are required")
+	}
+	one := big.NewInt(1)
+	if priv.P.Cmp(one) <= 0 || priv.Q.Cmp(one) <= 0 || priv.P.Cmp(priv.Q) == 0 {
+		return fmt.Errorf("Canonicalize: p and q must be distinct primes")
+	}
+	if priv.P.Cmp(priv.Q) < 0 {
+		priv.P, priv.Q = priv.Q, priv.P
+	}
+
+	pMinus1 := new(big.Int).Sub(priv.P, one)
+	qMinus1 := new(big.Int).Sub(priv.Q, one)
+	lambda := carmichael(new(big.Int), priv.P, priv.Q)
+	// λ(n), like p-1 and q-1, factors n.
+	defer zeroizeInt(pMinus1)
+	defer zeroizeInt(qMinus1)
+	defer zeroizeInt(lambda)
+
+	d := new(big.Int).ModInverse(priv.E, lambda)
+	if d == nil {
+		return fmt.Errorf("Canonicalize: e (%v) is not invertible modulo λ(n)", priv.E)
+	}
+	qInv := new(big.Int).ModInverse(priv.Q, priv.P)
+	if qInv == nil {
+		return fmt.Errorf("Canonicalize: q is not invertible modulo p")
+	}
+
+	priv.N = new(big.Int).Mul(priv.P, priv.Q)
+	priv.D = d
+	priv.Dp = new(big.Int).Mod(d, pMinus1)
+	priv.Dq = new(big.Int).Mod(d, qMinus1)
+	priv.Qinv = qInv
+
+	return nil
+}
+
+// carmichael sets z to λ(p*q) = lcm(p-1, q-1) for primes p, q and returns z.
+func carmichael(z, p, q *big.Int) *big.Int {
+
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(p, one)
+	qMinus1 := new(big.Int).Sub(q, one)
+	gcd := new(big.Int).GCD(nil, nil, pMinus1, qMinus1)
+
+	z.Mul(pMinus1, qMinus1)
+	z.Div(z, gcd)
+	zeroizeInt(pMinus1)
+	zeroizeInt(qMinus1)
+	zeroizeInt(gcd)
+	return z
+}
+
+// bigEqual compares 2 possibly nil numbers.
+func bigEqual(a, b *big.Int) bool {
+
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
@@ -0,0 +1,49 @@
+package rsa_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestCanonicalizeRecoveredKeys(t *testing.T) {
+	p, q, e := big.NewInt(877), big.NewInt(1069), big.NewInt(638471)
+
+	canonical, err := rsa.NewPrivateKey(p, q, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canonical.N.Int64() != 937513 || canonical.P.Cmp(canonical.Q) <= 0 {
+		t.Errorf("unexpected canonical key %+v", canonical)
+	}
+
+	// As a factoring attack would recover it: primes swapped and d mod φ(n).
+	phi := rsa.GetPhi(new(big.Int), p, q)
+	recovered := &rsa.PrivateKey{
+		PublicKey: rsa.PublicKey{N: big.NewInt(937513), E: e},
+		D:         new(big.Int).ModInverse(e, phi),
+		P:         q,
+		Q:         p,
+	}
+	if recovered.Equal(canonical) {
+		t.Fatal("expected differing representations before canonicalization")
+	}
+	if !recovered.PublicKey.Equal(&canonical.PublicKey) {
+		t.Error("expected equal public keys")
+	}
+	if err := recovered.Canonicalize(); err != nil {
+		t.Fatal(err)
+	}
+	if !recovered.Equal(canonical) {
+		t.Errorf("canonicalized keys differ:\n%+v\n%+v", recovered, canonical)
+	}
+
+	// λ(n) = lcm(876, 1068) factors n, so no error may show it.
+	if _, err := rsa.NewPrivateKey(p, q, big.NewInt(3)); err == nil {
+		t.Error("expected an error for e = 3 sharing a factor with λ(n)")
+	} else if strings.Contains(err.Error(), "77964") {
+		t.Errorf("the error reveals λ(n): %v", err)
+	}
+}
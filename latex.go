@@ -0,0 +1,81 @@
+package rsa
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EuclidStep is one row of the Extended Euclidean algorithm tableau:
+// Remainder = a*S + b*T holds on every row and Quotient is the
+// q(i) = r(i-1) / r(i) dividing by this row's remainder (0 on the first
+// and last rows).
+type EuclidStep struct {
+	Quotient, Remainder, S, T int64
+}
+
+// GetExtEuclideanSteps runs the same iteration as GetExtEuclideanAlgorithm
+// but records every row of the tableau, starting with the (a, 1, 0) and
+// (b, 0, 1) rows and ending with the zero remainder row.
+// The gcd and Bézout coefficients are on the next to last row.
+func GetExtEuclideanSteps(a, b int64) []EuclidStep {
+
+	steps := []EuclidStep{
+		{Quotient: 0, Remainder: a, S: 1, T: 0},
+		{Quotient: 0, Remainder: b, S: 0, T: 1},
+	}
+
+	for prv, cur := steps[0], steps[1]; cur.Remainder != 0; prv, cur = cur, steps[len(steps)-1] {
+		quotient := prv.Remainder / cur.Remainder
+		steps = append(steps, EuclidStep{
+			Quotient:  quotient,
+			Remainder: prv.Remainder - quotient*cur.Remainder,
+			S:         prv.S - quotient*cur.S,
+			T:         prv.T - quotient*cur.T,
+		})
+	}
+	// Quotients belong to the row they divide by in the usual table layout.
+	for i := 2; i < len(steps); i++ {
+		steps[i-1].Quotient = steps[i].Quotient
+	}
+	steps[len(steps)-1].Quotient = 0
+
+	return steps
+}
+
+// EuclidStepsLaTeX renders a GetExtEuclideanSteps tableau as a LaTeX array
+// followed by the resulting Bézout identity, ready to be placed in a
+// math environment of lecture notes.
+func EuclidStepsLaTeX(steps []EuclidStep) string {
+
+	var sb strings.Builder
+	if len(steps) < 2 {
+		return ""
+	}
+
+	sb.WriteString("\\begin{array}{r|rrrr}\n")
+	sb.WriteString("i & q_i & r_i & s_i & t_i \\\\ \\hline\n")
+	for i, step := range steps {
+		q := ""
+		if i > 0 && i < len(steps)-1 {
+			q = fmt.Sprint(step.Quotient)
+		}
+		fmt.Fprintf(&sb, "%d & %s & %d & %d & %d \\\\\n", i, q, step.Remainder, step.S, step.T)
+	}
+	sb.WriteString("\\end{array}\n")
+
+	a, b := steps[0].Remainder, steps[1].Remainder
+	last := steps[len(steps)-2]
+	fmt.Fprintf(&sb, "\\gcd(%d, %d) = %d = %d \\cdot %s + %d \\cdot %s\n",
+		a, b, last.Remainder, a, latexFactor(last.S), b, latexFactor(last.T))
+
+	return sb.String()
+}
+
+// latexFactor wraps negative numbers in parentheses for products.
+func latexFactor(n int64) string {
+
+	if n < 0 {
+		return fmt.Sprintf("(%d)", n)
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,30 @@
+package rsa_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestEuclidStepsLaTeX(t *testing.T) {
+	var a, b int64 = 240, 46
+
+	steps := rsa.GetExtEuclideanSteps(a, b)
+	gcd, x, y := rsa.GetExtEuclideanAlgorithm(a, b)
+	last := steps[len(steps)-2]
+	if last.Remainder != gcd || last.S != x || last.T != y {
+		t.Errorf("tableau ends with %+v, expected gcd %v, x %v, y %v", last, gcd, x, y)
+	}
+	for _, step := range steps {
+		if a*step.S+b*step.T != step.Remainder {
+			t.Errorf("row %+v breaks r = a*s + b*t", step)
+		}
+	}
+
+	latex := rsa.EuclidStepsLaTeX(steps)
+	expected := "\\gcd(240, 46) = 2 = 240 \\cdot (-9) + 46 \\cdot 47"
+	if !strings.Contains(latex, "\\begin{array}") || !strings.Contains(latex, expected) {
+		t.Errorf("unexpected LaTeX output:\n%v", latex)
+	}
+}
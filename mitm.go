@@ -0,0 +1,52 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MeetInTheMiddle recovers an unpadded plaintext m = m1 * m2 with
+// 1 <= m1 <= bound1 and 1 <= m2 <= bound2 from c = m^e mod n. It tabulates
+// m1^e for every m1, then looks up c / m2^e for every m2: as RSA is
+// multiplicative a hit means c = (m1*m2)^e. The work is bound1 + bound2
+// exponentiations instead of bound1 * bound2, so a 64 bit session key,
+// which splits into 2 factors of 32 bits about a fifth of the time, costs
+// about 2^33 rather than 2^64.
+// https://link.springer.com/chapter/10.1007/3-540-44448-3_3
+func MeetInTheMiddle(c *big.Int, pub *PublicKey, bound1, bound2 int64) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if bound1 < 1 || bound2 < 1 {
+		return nil, meter.done(), fmt.Errorf("MeetInTheMiddle: bounds must be positive")
+	}
+
+	table := make(map[string]int64, bound1)
+	x, m := new(big.Int), new(big.Int)
+	for m1 := int64(1); m1 <= bound1; m1++ {
+		x.Exp(m.SetInt64(m1), pub.E, pub.N)
+		meter.exp(pub.E)
+		if m1%1024 == 0 {
+			meter.sample()
+		}
+		if _, ok := table[string(x.Bytes())]; !ok {
+			table[string(x.Bytes())] = m1
+		}
+	}
+
+	inv := new(big.Int)
+	for m2 := int64(1); m2 <= bound2; m2++ {
+		x.Exp(m.SetInt64(m2), pub.E, pub.N)
+		meter.exp(pub.E)
+		meter.cost.ModMuls++
+		if inv.ModInverse(x, pub.N) == nil {
+			// m2 shares a factor with n: n is factored, but that is another story.
+			continue
+		}
+		x.Mul(c, inv)
+		x.Mod(x, pub.N)
+		if m1, ok := table[string(x.Bytes())]; ok {
+			return m.Mul(big.NewInt(m1), big.NewInt(m2)), meter.done(), nil
+		}
+	}
+	return nil, meter.done(), fmt.Errorf("MeetInTheMiddle: plaintext is not a product of factors up to %v and %v", bound1, bound2)
+}
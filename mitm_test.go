@@ -0,0 +1,34 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestMeetInTheMiddle(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := &priv.PublicKey
+
+	m := big.NewInt(3001 * 3989)
+	c := new(big.Int).Exp(m, pub.E, pub.N)
+	got, _, err := rsa.MeetInTheMiddle(c, pub, 1<<12, 1<<12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(m) != 0 {
+		t.Errorf("recovered %v, expected %v", got, m)
+	}
+
+	// A prime above both bounds does not split.
+	c.Exp(big.NewInt(1000003), pub.E, pub.N)
+	if _, _, err := rsa.MeetInTheMiddle(c, pub, 1<<10, 1<<10); err == nil {
+		t.Error("expected no split for a large prime plaintext")
+	}
+}
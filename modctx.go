@@ -0,0 +1,271 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Reduction selects how a ModContext reduces its results modulo N.
+type Reduction int
+
+const (
+	// ReducePlain reduces with big.Int's division based Mod.
+	ReducePlain Reduction = iota
+	// ReduceBarrett replaces the division with 2 multiplications by
+	// a precomputed reciprocal of N.
+	// https://en.wikipedia.org/wiki/Barrett_reduction
+	ReduceBarrett
+	// ReduceMontgomery multiplies numbers in Montgomery form a*R mod N,
+	// replacing the division with shifts by R = 2^k. N must be odd.
+	// https://en.wikipedia.org/wiki/Montgomery_modular_multiplication
+	ReduceMontgomery
+	// ReduceFixed is experimental Montgomery reduction on fixed size
+	// arrays of 64 bit limbs instead of big.Int, avoiding math/big's
+	// normalization and allocations. N must be odd.
+	ReduceFixed
+)
+
+// String names the reduction backend.
+func (r Reduction) String() string {
+
+	switch r {
+	case ReducePlain:
+		return "plain"
+	case ReduceBarrett:
+		return "Barrett"
+	case ReduceMontgomery:
+		return "Montgomery"
+	case ReduceFixed:
+		return "fixed-limb"
+	}
+	return fmt.Sprintf("Reduction(%d)", int(r))
+}
+
+// ModContext carries a modulus N and the reduction backend chosen for it
+// so modular arithmetic reads as ctx.Mul(ctx.Add(a, b), c) instead of
+// threading N through every call.
+// All methods leave their arguments untouched and return new numbers
+// in [0, N). A ModContext is safe for concurrent use.
+type ModContext struct {
+	N         *big.Int
+	reduction Reduction
+
+	// Barrett: mu = floor(4^k / N) with k the bit length of N.
+	k  uint
+	mu *big.Int
+
+	// Montgomery: R = 2^k, nPrime = -N^-1 mod R, rr = R^2 mod N.
+	mask   *big.Int
+	nPrime *big.Int
+	rr     *big.Int
+
+	// Fixed: limb arithmetic constants.
+	fixed *fixedMont
+}
+
+// NewModContext prepares the constants of the chosen reduction
+// backend for modulus n > 1.
+func NewModContext(n *big.Int, reduction Reduction) (*ModContext, error) {
+
+	if n.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("NewModContext: modulus must be greater than 1, got %v", n)
+	}
+
+	ctx := &ModContext{
+		N:         new(big.Int).Set(n),
+		reduction: reduction,
+		k:         uint(n.BitLen()),
+	}
+
+	switch reduction {
+	case ReducePlain:
+	case ReduceBarrett:
+		ctx.mu = new(big.Int).Lsh(big.NewInt(1), 2*ctx.k)
+		ctx.mu.Div(ctx.mu, ctx.N)
+	case ReduceMontgomery:
+		nPrime, err := GetNegInverseModPow2(new(big.Int), n, ctx.k)
+		if err != nil {
+			return nil, fmt.Errorf("NewModContext: Montgomery reduction needs an odd modulus: %v", err)
+		}
+		ctx.nPrime = nPrime
+		ctx.mask = new(big.Int).Lsh(big.NewInt(1), ctx.k)
+		ctx.mask.Sub(ctx.mask, big.NewInt(1))
+		ctx.rr = new(big.Int).Lsh(big.NewInt(1), 2*ctx.k)
+		ctx.rr.Mod(ctx.rr, ctx.N)
+	case ReduceFixed:
+		if n.Bit(0) == 0 {
+			return nil, fmt.Errorf("NewModContext: fixed-limb reduction needs an odd modulus, got %v", n)
+		}
+		ctx.fixed = newFixedMont(n)
+	default:
+		return nil, fmt.Errorf("NewModContext: unknown reduction %v", reduction)
+	}
+
+	return ctx, nil
+}
+
+// Reduction returns the backend the context reduces with.
+func (ctx *ModContext) Reduction() Reduction {
+
+	return ctx.reduction
+}
+
+// Add returns (a + b) mod N.
+func (ctx *ModContext) Add(a, b *big.Int) *big.Int {
+
+	res := new(big.Int).Add(a, b)
+	return res.Mod(res, ctx.N)
+}
+
+// Sub returns (a - b) mod N as a nonnegative representative.
+func (ctx *ModContext) Sub(a, b *big.Int) *big.Int {
+
+	res := new(big.Int).Sub(a, b)
+	return res.Mod(res, ctx.N)
+}
+
+// Mul returns (a * b) mod N reduced with the context's backend.
+func (ctx *ModContext) Mul(a, b *big.Int) *big.Int {
+
+	aRed, bRed := ctx.canonical(a), ctx.canonical(b)
+	if ctx.reduction == ReduceFixed {
+		return ctx.fixed.mulBig(aRed, bRed)
+	}
+	res := new(big.Int).Mul(aRed, bRed)
+
+	switch ctx.reduction {
+	case ReduceBarrett:
+		return ctx.barrett(res)
+	case ReduceMontgomery:
+		// redc(a*b) = a*b/R, multiplying by R^2 brings it back to a*b.
+		res = ctx.redc(res)
+		return ctx.redc(res.Mul(res, ctx.rr))
+	}
+	return res.Mod(res, ctx.N)
+}
+
+// Exp returns base^exp mod N by left-to-right square and multiply,
+// every product reduced with the context's backend.
+// A negative exp raises the inverse of base and fails if there is none.
+func (ctx *ModContext) Exp(base, exp *big.Int) (*big.Int, error) {
+
+	b := ctx.canonical(base)
+	if exp.Sign() < 0 {
+		inv, err := ctx.Inv(b)
+		if err != nil {
+			return nil, err
+		}
+		b = inv
+		exp = new(big.Int).Neg(exp)
+	}
+	if ctx.reduction == ReduceFixed {
+		return ctx.fixed.exp(b, exp), nil
+	}
+
+	mul := ctx.Mul
+	result := big.NewInt(1)
+	if ctx.reduction == ReduceMontgomery {
+		// Work in Montgomery form for the whole ladder and convert once.
+		mul = func(x, y *big.Int) *big.Int {
+			return ctx.redc(new(big.Int).Mul(x, y))
+		}
+		b = ctx.toMontgomery(b)
+		result = ctx.toMontgomery(result)
+	}
+
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		result = mul(result, result)
+		if exp.Bit(i) == 1 {
+			result = mul(result, b)
+		}
+	}
+
+	if ctx.reduction == ReduceMontgomery {
+		result = ctx.redc(result)
+	}
+	return result, nil
+}
+
+// Inv returns the multiplicative inverse of a modulo N.
+func (ctx *ModContext) Inv(a *big.Int) (*big.Int, error) {
+
+	aRed := ctx.canonical(a)
+	if aRed.Sign() == 0 {
+		return nil, fmt.Errorf("ModContext.Inv: 0 has no inverse modulo %v", ctx.N)
+	}
+
+	x := new(big.Int)
+	gcd, err := GetExtBinaryGCD(new(big.Int), x, nil, aRed, ctx.N)
+	if err != nil {
+		return nil, err
+	}
+	if gcd.Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("ModContext.Inv: no inverse of %v because gcd with %v is %v", a, ctx.N, gcd)
+	}
+	return x.Mod(x, ctx.N), nil
+}
+
+// Sqrt returns a square root of a modulo N, which must be an odd prime.
+func (ctx *ModContext) Sqrt(a *big.Int) (*big.Int, error) {
+
+	if ctx.N.Bit(0) == 0 || !ctx.N.ProbablyPrime(20) {
+		return nil, fmt.Errorf("ModContext.Sqrt: modulus %v is not an odd prime", ctx.N)
+	}
+
+	res := new(big.Int).ModSqrt(ctx.canonical(a), ctx.N)
+	if res == nil {
+		return nil, fmt.Errorf("ModContext.Sqrt: %v is not a quadratic residue modulo %v", a, ctx.N)
+	}
+	return res, nil
+}
+
+// canonical returns a in [0, N), copying only when a reduction is needed.
+func (ctx *ModContext) canonical(a *big.Int) *big.Int {
+
+	if a.Sign() >= 0 && a.Cmp(ctx.N) < 0 {
+		return a
+	}
+	return new(big.Int).Mod(a, ctx.N)
+}
+
+// barrett reduces 0 <= x < N^2 modulo N in place.
+func (ctx *ModContext) barrett(x *big.Int) *big.Int {
+
+	q := new(big.Int).Rsh(x, ctx.k-1)
+	q.Mul(q, ctx.mu)
+	q.Rsh(q, ctx.k+1)
+	x.Sub(x, q.Mul(q, ctx.N))
+	for x.Cmp(ctx.N) >= 0 {
+		x.Sub(x, ctx.N)
+	}
+	return x
+}
+
+// redc is Montgomery's REDC: for 0 <= t < N*R it returns t/R mod N in place.
+func (ctx *ModContext) redc(t *big.Int) *big.Int {
+
+	t, _ = ctx.redcExtra(t)
+	return t
+}
+
+// redcExtra is redc also reporting whether the final extra subtraction of
+// N was needed, the data dependent step timing attacks on Montgomery
+// multiplication exploit.
+func (ctx *ModContext) redcExtra(t *big.Int) (*big.Int, bool) {
+
+	m := new(big.Int).And(t, ctx.mask)
+	m.Mul(m, ctx.nPrime)
+	m.And(m, ctx.mask)
+	t.Add(t, m.Mul(m, ctx.N))
+	t.Rsh(t, ctx.k)
+	if t.Cmp(ctx.N) >= 0 {
+		return t.Sub(t, ctx.N), true
+	}
+	return t, false
+}
+
+// toMontgomery returns a*R mod N for a in [0, N).
+func (ctx *ModContext) toMontgomery(a *big.Int) *big.Int {
+
+	return ctx.redc(new(big.Int).Mul(a, ctx.rr))
+}
@@ -0,0 +1,130 @@
+package rsa_test
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+var reductions = []rsa.Reduction{rsa.ReducePlain, rsa.ReduceBarrett, rsa.ReduceMontgomery, rsa.ReduceFixed}
+
+func TestModContext(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	// 2^127 - 1 is prime, so Sqrt and Inv are defined for all nonzero values.
+	n := new(big.Int).Lsh(big.NewInt(1), 127)
+	n.Sub(n, big.NewInt(1))
+
+	for _, reduction := range reductions {
+		ctx, err := rsa.NewModContext(n, reduction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			a := new(big.Int).Rand(rnd, n)
+			b := new(big.Int).Rand(rnd, n)
+			a.Add(a, big.NewInt(1))
+			b.Neg(b) // out of range operands are reduced first
+
+			expected := new(big.Int).Mul(a, b)
+			if got := ctx.Mul(a, b); got.Cmp(expected.Mod(expected, n)) != 0 {
+				t.Errorf("%v: %v * %v = %v, expected %v", reduction, a, b, got, expected)
+			}
+			expected = new(big.Int).Sub(a, b)
+			if got := ctx.Sub(a, b); got.Cmp(expected.Mod(expected, n)) != 0 {
+				t.Errorf("%v: %v - %v = %v, expected %v", reduction, a, b, got, expected)
+			}
+
+			exp := new(big.Int).Rand(rnd, n)
+			expected = new(big.Int).Exp(a, exp, n)
+			if got, err := ctx.Exp(a, exp); err != nil || got.Cmp(expected) != 0 {
+				t.Errorf("%v: %v ^ %v = %v (%v), expected %v", reduction, a, exp, got, err, expected)
+			}
+
+			inv, err := ctx.Inv(a)
+			if err != nil || ctx.Mul(a, inv).Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("%v: %v is not the inverse of %v (%v)", reduction, inv, a, err)
+			}
+
+			square := ctx.Mul(a, a)
+			root, err := ctx.Sqrt(square)
+			if err != nil || ctx.Mul(root, root).Cmp(square) != 0 {
+				t.Errorf("%v: %v is not a square root of %v (%v)", reduction, root, square, err)
+			}
+		}
+	}
+}
+
+func TestModContextErrors(t *testing.T) {
+	for _, reduction := range []rsa.Reduction{rsa.ReduceMontgomery, rsa.ReduceFixed} {
+		if _, err := rsa.NewModContext(big.NewInt(100), reduction); err == nil {
+			t.Errorf("expected an error for %v reduction with an even modulus", reduction)
+		}
+	}
+
+	ctx, err := rsa.NewModContext(big.NewInt(937513), rsa.ReducePlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctx.Inv(big.NewInt(877)); err == nil {
+		t.Error("expected an error inverting a factor of the modulus")
+	}
+	if _, err := ctx.Sqrt(big.NewInt(4)); err == nil {
+		t.Error("expected an error for a square root modulo a composite")
+	}
+}
+
+func TestModContextFixedSizes(t *testing.T) {
+	rnd := rand.New(rand.NewSource(4))
+	for _, bits := range []int{64, 65, 1024, 2048} {
+		n := new(big.Int).Rand(rnd, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		n.SetBit(n, bits-1, 1)
+		n.SetBit(n, 0, 1)
+		ctx, err := rsa.NewModContext(n, rsa.ReduceFixed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 5; i++ {
+			a := new(big.Int).Rand(rnd, n)
+			b := new(big.Int).Sub(n, big.NewInt(int64(i+1))) // near the top of the range
+			expected := new(big.Int).Mul(a, b)
+			if got := ctx.Mul(a, b); got.Cmp(expected.Mod(expected, n)) != 0 {
+				t.Errorf("%v bits: %v * %v = %v, expected %v", bits, a, b, got, expected)
+			}
+			if got, _ := ctx.Exp(b, a); got.Cmp(new(big.Int).Exp(b, a, n)) != 0 {
+				t.Errorf("%v bits: %v ^ %v = %v", bits, b, a, got)
+			}
+		}
+	}
+}
+
+func BenchmarkModContextExp(b *testing.B) {
+	rnd := rand.New(rand.NewSource(5))
+	for _, bits := range []int{1024, 2048} {
+		n := new(big.Int).Rand(rnd, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		n.SetBit(n, bits-1, 1)
+		n.SetBit(n, 0, 1)
+		base := new(big.Int).Rand(rnd, n)
+		exp := new(big.Int).Rand(rnd, n)
+
+		b.Run(fmt.Sprintf("%v/math-big", bits), func(b *testing.B) {
+			z := new(big.Int)
+			for i := 0; i < b.N; i++ {
+				z.Exp(base, exp, n)
+			}
+		})
+		for _, reduction := range reductions {
+			ctx, err := rsa.NewModContext(n, reduction)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(fmt.Sprintf("%v/%v", bits, reduction), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					ctx.Exp(base, exp)
+				}
+			})
+		}
+	}
+}
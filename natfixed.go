@@ -0,0 +1,145 @@
+package rsa
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// fixedMont is Montgomery arithmetic on numbers stored as a fixed number
+// of little-endian 64 bit limbs, the size of the modulus, in the spirit
+// of crypto/internal/bigmod: no normalization, no allocation inside an
+// exponentiation and the same sequence of limb operations for every value.
+// It is read-only after creation, callers bring their own scratch space.
+// https://en.wikipedia.org/wiki/Montgomery_modular_multiplication
+type fixedMont struct {
+	n    []uint64
+	nInv uint64   // -n^-1 mod 2^64
+	rr   []uint64 // R^2 mod n for R = 2^(64*limbs)
+	one  []uint64 // R mod n, 1 in Montgomery form
+}
+
+// newFixedMont prepares the constants for the odd modulus n.
+func newFixedMont(n *big.Int) *fixedMont {
+
+	limbs := (n.BitLen() + 63) / 64
+	m := &fixedMont{n: make([]uint64, limbs)}
+	m.n = m.fromBig(n)
+
+	// Newton's iteration doubles the correct low bits of n0^-1 each step.
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - m.n[0]*inv
+	}
+	m.nInv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*limbs))
+	m.one = m.fromBig(new(big.Int).Mod(r, n))
+	m.rr = m.fromBig(r.Mod(r.Mul(r, r), n))
+	return m
+}
+
+// fromBig returns x in [0, n) as limbs.
+func (m *fixedMont) fromBig(x *big.Int) []uint64 {
+
+	limbs := len(m.n)
+	buf := x.FillBytes(make([]byte, 8*limbs))
+	z := make([]uint64, limbs)
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(buf[8*(limbs-1-i):])
+	}
+	return z
+}
+
+// toBig returns the limbs as a big.Int.
+func (m *fixedMont) toBig(x []uint64) *big.Int {
+
+	buf := make([]byte, 8*len(x))
+	for i, limb := range x {
+		binary.BigEndian.PutUint64(buf[8*(len(x)-1-i):], limb)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// mul sets z to x*y/R mod n for x, y in [0, n) by coarsely integrated
+// operand scanning (CIOS), using t of len(n)+2 limbs as scratch.
+// z may alias x or y.
+func (m *fixedMont) mul(z, x, y, t []uint64) {
+
+	s := len(m.n)
+	clear(t)
+
+	for i := 0; i < s; i++ {
+		// t += x * y[i]
+		var carry uint64
+		for j := 0; j < s; j++ {
+			t[j], carry = mulAddWWW(x[j], y[i], t[j], carry)
+		}
+		var c uint64
+		t[s], c = bits.Add64(t[s], carry, 0)
+		t[s+1] = c
+
+		// t = (t + q*n) / 2^64 with q chosen to clear the low limb.
+		q := t[0] * m.nInv
+		_, carry = mulAddWWW(q, m.n[0], t[0], 0)
+		for j := 1; j < s; j++ {
+			t[j-1], carry = mulAddWWW(q, m.n[j], t[j], carry)
+		}
+		t[s-1], c = bits.Add64(t[s], carry, 0)
+		t[s] = t[s+1] + c
+	}
+
+	// t < 2n, subtract n unless that borrows, selecting without a branch.
+	var borrow uint64
+	for j := 0; j < s; j++ {
+		z[j], borrow = bits.Sub64(t[j], m.n[j], borrow)
+	}
+	_, borrow = bits.Sub64(t[s], 0, borrow)
+	keep := -borrow // all ones if t < n
+	for j := 0; j < s; j++ {
+		z[j] = z[j]&^keep | t[j]&keep
+	}
+}
+
+// mulBig returns a*b mod n for a, b in [0, n).
+func (m *fixedMont) mulBig(a, b *big.Int) *big.Int {
+
+	t := make([]uint64, len(m.n)+2)
+	x, y := m.fromBig(a), m.fromBig(b)
+	m.mul(x, x, y, t)    // a*b/R
+	m.mul(x, x, m.rr, t) // a*b
+	return m.toBig(x)
+}
+
+// exp returns base^exp mod n for base in [0, n), scanning every bit of exp.
+func (m *fixedMont) exp(base, exp *big.Int) *big.Int {
+
+	t := make([]uint64, len(m.n)+2)
+	b := m.fromBig(base)
+	m.mul(b, b, m.rr, t)
+	result := append([]uint64(nil), m.one...)
+
+	for i := exp.BitLen() - 1; i >= 0; i-- {
+		m.mul(result, result, result, t)
+		if exp.Bit(i) == 1 {
+			m.mul(result, result, b, t)
+		}
+	}
+
+	one := make([]uint64, len(m.n))
+	one[0] = 1
+	m.mul(result, result, one, t)
+	return m.toBig(result)
+}
+
+// mulAddWWW returns the low and high limbs of x*y + a + b.
+func mulAddWWW(x, y, a, b uint64) (lo, hi uint64) {
+
+	hi, lo = bits.Mul64(x, y)
+	var c uint64
+	lo, c = bits.Add64(lo, a, 0)
+	hi += c
+	lo, c = bits.Add64(lo, b, 0)
+	hi += c
+	return lo, hi
+}
@@ -0,0 +1,140 @@
+package rsa
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Number is an integer kept in an int64 while it fits and promoted to
+// a big.Int once an operation would overflow, so that the int64
+// convenience APIs never hand back a silently wrapped result.
+// The zero value is 0.
+type Number struct {
+	small int64
+	large *big.Int // set only once promoted
+}
+
+// NewNumber returns n as a Number.
+func NewNumber(n int64) Number {
+
+	return Number{small: n}
+}
+
+// NewBigNumber returns a copy of n as a Number, kept as an int64 if it fits.
+func NewBigNumber(n *big.Int) Number {
+
+	if n.IsInt64() {
+		return Number{small: n.Int64()}
+	}
+	return Number{large: new(big.Int).Set(n)}
+}
+
+// IsBig reports whether the number has been promoted to a big.Int.
+func (n Number) IsBig() bool {
+
+	return n.large != nil
+}
+
+// Int64 returns the number and true if it fits an int64.
+func (n Number) Int64() (int64, bool) {
+
+	if n.large != nil {
+		return 0, false
+	}
+	return n.small, true
+}
+
+// Big returns the number as a new big.Int.
+func (n Number) Big() *big.Int {
+
+	if n.large != nil {
+		return new(big.Int).Set(n.large)
+	}
+	return big.NewInt(n.small)
+}
+
+// String formats the number in decimal.
+func (n Number) String() string {
+
+	return n.Big().String()
+}
+
+// Cmp compares n and m returning -1, 0 or +1 like big.Int's Cmp.
+func (n Number) Cmp(m Number) int {
+
+	if n.large == nil && m.large == nil {
+		switch {
+		case n.small < m.small:
+			return -1
+		case n.small > m.small:
+			return 1
+		}
+		return 0
+	}
+	return n.Big().Cmp(m.Big())
+}
+
+// Add returns n + m, promoted when the operands are too long for int64.
+func (n Number) Add(m Number) Number {
+
+	if n.fitsBits(m, max(n.bitLen(), m.bitLen())+1) {
+		return Number{small: n.small + m.small}
+	}
+	return NewBigNumber(new(big.Int).Add(n.Big(), m.Big()))
+}
+
+// Sub returns n - m, promoted when the operands are too long for int64.
+func (n Number) Sub(m Number) Number {
+
+	if n.fitsBits(m, max(n.bitLen(), m.bitLen())+1) {
+		return Number{small: n.small - m.small}
+	}
+	return NewBigNumber(new(big.Int).Sub(n.Big(), m.Big()))
+}
+
+// Mul returns n * m, promoted when the operands' bit lengths add up
+// to more than an int64 holds.
+func (n Number) Mul(m Number) Number {
+
+	if n.fitsBits(m, n.bitLen()+m.bitLen()) {
+		return Number{small: n.small * m.small}
+	}
+	return NewBigNumber(new(big.Int).Mul(n.Big(), m.Big()))
+}
+
+// ExpMod returns n ** exp % modulus like GetEncOrDecMsg, squaring in
+// int64 while (modulus-1)^2 fits and in big.Int otherwise.
+// A negative exponent yields 1 as in GetEncOrDecMsg. A negative base is
+// reduced first, so the result is in [0, modulus) either way.
+func (n Number) ExpMod(exp, modulus Number) Number {
+
+	if exp.Cmp(Number{}) < 0 {
+		return NewNumber(1)
+	}
+	if n.large == nil && exp.large == nil && modulus.large == nil && 2*modulus.bitLen() <= 63 {
+		return NewNumber(expMod64(EuclideanMod(n.small, modulus.small), exp.small, modulus.small))
+	}
+
+	base := new(big.Int).Mod(n.Big(), modulus.Big())
+	return NewBigNumber(base.Exp(base, exp.Big(), modulus.Big()))
+}
+
+// fitsBits reports whether both numbers are int64s and an operation
+// on them needing resultBits bits, sign excluded, cannot overflow.
+func (n Number) fitsBits(m Number, resultBits int) bool {
+
+	return n.large == nil && m.large == nil && resultBits <= 63
+}
+
+// bitLen is the bit length of the absolute value.
+func (n Number) bitLen() int {
+
+	if n.large != nil {
+		return n.large.BitLen()
+	}
+	abs := uint64(n.small)
+	if n.small < 0 {
+		abs = -abs
+	}
+	return bits.Len64(abs)
+}
@@ -0,0 +1,50 @@
+package rsa_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestNumberPromotion(t *testing.T) {
+	a := rsa.NewNumber(math.MaxInt64)
+	b := rsa.NewNumber(2)
+
+	sum := a.Add(b)
+	if !sum.IsBig() || sum.String() != "9223372036854775809" {
+		t.Errorf("MaxInt64 + 2 = %v, expected a promoted 9223372036854775809", sum)
+	}
+	product := a.Mul(b)
+	if !product.IsBig() || product.Big().Cmp(new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(2))) != 0 {
+		t.Errorf("MaxInt64 * 2 = %v", product)
+	}
+	if small := rsa.NewNumber(3).Mul(rsa.NewNumber(-4)); small.IsBig() || small.Cmp(rsa.NewNumber(-12)) != 0 {
+		t.Errorf("3 * -4 = %v", small)
+	}
+	// Results fitting again are demoted.
+	if back := sum.Sub(b); back.IsBig() || back.Cmp(a) != 0 {
+		t.Errorf("(MaxInt64 + 2) - 2 = %v", back)
+	}
+}
+
+func TestGetEncOrDecMsgLargeModulus(t *testing.T) {
+	// 4611686014132420609 = (2^31 - 1)^2 overflows the int64 squaring.
+	var base, exp, modulus int64 = 123456789123, 65537, 4611686014132420609
+
+	expected := new(big.Int).Exp(big.NewInt(base), big.NewInt(exp), big.NewInt(modulus))
+	if got := rsa.GetEncOrDecMsg(base, exp, modulus); got != expected.Int64() {
+		t.Errorf("%v ^ %v %% %v = %v, expected %v", base, exp, modulus, got, expected)
+	}
+}
+
+func TestNumberExpModNegativeBase(t *testing.T) {
+	for _, modulus := range []rsa.Number{rsa.NewNumber(1000003), rsa.NewBigNumber(new(big.Int).Lsh(big.NewInt(1), 80))} {
+		got := rsa.NewNumber(-7).ExpMod(rsa.NewNumber(3), modulus)
+		expected := new(big.Int).Exp(big.NewInt(-7), big.NewInt(3), modulus.Big())
+		if got.Big().Cmp(expected) != 0 || got.Big().Sign() < 0 {
+			t.Errorf("-7 ^ 3 %% %v = %v, expected %v", modulus, got, expected)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package rsa
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// NumberFormat selects how numeric results are written, to match the
+// textbook or specification being followed. The zero value is decimal.
+type NumberFormat int
+
+const (
+	// FormatDecimal writes numbers in base 10, as textbooks do.
+	FormatDecimal NumberFormat = iota
+	// FormatHex writes lowercase base 16 without a prefix, as test
+	// vectors and most specifications do.
+	FormatHex
+	// FormatBase64URL writes the unpadded base64url of the big-endian
+	// bytes, the encoding of RSA parameters in JWK (RFC 7518 section 6.3).
+	// Like JWK it has no sign: negative numbers are written as their
+	// absolute value.
+	FormatBase64URL
+	// FormatBinary writes numbers in base 2.
+	FormatBinary
+)
+
+var numberFormatNames = [...]string{
+	FormatDecimal:   "decimal",
+	FormatHex:       "hex",
+	FormatBase64URL: "base64url",
+	FormatBinary:    "binary",
+}
+
+// ParseNumberFormat returns the format named decimal, hex, base64url or
+// binary.
+func ParseNumberFormat(name string) (NumberFormat, error) {
+
+	for f, fname := range numberFormatNames {
+		if strings.EqualFold(name, fname) {
+			return NumberFormat(f), nil
+		}
+	}
+	return 0, fmt.Errorf("ParseNumberFormat: unknown number format %q", name)
+}
+
+// String returns the name of the format.
+func (f NumberFormat) String() string {
+
+	if f < 0 || int(f) >= len(numberFormatNames) {
+		return fmt.Sprintf("NumberFormat(%d)", int(f))
+	}
+	return numberFormatNames[f]
+}
+
+// Format returns x written in format f.
+func (f NumberFormat) Format(x *big.Int) string {
+
+	switch f {
+	case FormatHex:
+		return x.Text(16)
+	case FormatBase64URL:
+		b := x.Bytes()
+		if len(b) == 0 {
+			b = []byte{0}
+		}
+		return base64.RawURLEncoding.EncodeToString(b)
+	case FormatBinary:
+		return x.Text(2)
+	default:
+		return x.Text(10)
+	}
+}
+
+// Parse reads a number written in format f.
+func (f NumberFormat) Parse(s string) (*big.Int, error) {
+
+	if f == FormatBase64URL {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("NumberFormat.Parse: %v", err)
+		}
+		return new(big.Int).SetBytes(b), nil
+	}
+
+	base := map[NumberFormat]int{FormatDecimal: 10, FormatHex: 16, FormatBinary: 2}[f]
+	if base == 0 {
+		return nil, fmt.Errorf("NumberFormat.Parse: unknown format %v", f)
+	}
+	x, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, fmt.Errorf("NumberFormat.Parse: %q is not a %v number", s, f)
+	}
+	return x, nil
+}
+
+// MarshalText encodes the format as its name, for JSON and flags.
+func (f NumberFormat) MarshalText() ([]byte, error) {
+
+	if f < 0 || int(f) >= len(numberFormatNames) {
+		return nil, fmt.Errorf("NumberFormat.MarshalText: unknown format %d", int(f))
+	}
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText decodes a format name.
+func (f *NumberFormat) UnmarshalText(text []byte) error {
+
+	parsed, err := ParseNumberFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
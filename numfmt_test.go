@@ -0,0 +1,46 @@
+package rsa_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestNumberFormat(t *testing.T) {
+	x := big.NewInt(65537)
+	for _, test := range []struct {
+		format rsa.NumberFormat
+		text   string
+	}{
+		{rsa.FormatDecimal, "65537"},
+		{rsa.FormatHex, "10001"},
+		{rsa.FormatBase64URL, "AQAB"},
+		{rsa.FormatBinary, "10000000000000001"},
+	} {
+		if got := test.format.Format(x); got != test.text {
+			t.Errorf("%v: formatted %v, expected %v", test.format, got, test.text)
+		}
+		if y, err := test.format.Parse(test.text); err != nil || y.Cmp(x) != 0 {
+			t.Errorf("%v: parsed %v (%v), expected %v", test.format, y, err, x)
+		}
+		if f, err := rsa.ParseNumberFormat(test.format.String()); err != nil || f != test.format {
+			t.Errorf("ParseNumberFormat(%v) = %v, %v", test.format, f, err)
+		}
+	}
+	if got := rsa.FormatBase64URL.Format(new(big.Int)); got != "AA" {
+		t.Errorf("formatted 0 as %q, expected AA", got)
+	}
+
+	var opts struct{ Format rsa.NumberFormat }
+	if err := json.Unmarshal([]byte(`{"Format":"base64url"}`), &opts); err != nil || opts.Format != rsa.FormatBase64URL {
+		t.Errorf("unmarshaled %v (%v)", opts.Format, err)
+	}
+	if _, err := rsa.ParseNumberFormat("octal"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if _, err := rsa.FormatHex.Parse("xyz"); err == nil {
+		t.Error("expected an error for an invalid hex number")
+	}
+}
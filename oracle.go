@@ -0,0 +1,159 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Oracle answers a question about the decryption of a chosen ciphertext.
+// It is all the access to the private key an oracle attack gets, e.g.
+// the parity bit of the plaintext or a full decryption of anything but
+// the target ciphertext.
+type Oracle interface {
+	Query(c *big.Int) (*big.Int, error)
+}
+
+// ParityOracle is a deliberately vulnerable Oracle answering 0 or 1,
+// the least significant bit of the decryption of a ciphertext, as a
+// server leaking "even/odd" through its error messages would.
+type ParityOracle struct {
+	priv *PrivateKey
+}
+
+// NewParityOracle returns a ParityOracle decrypting with priv.
+func NewParityOracle(priv *PrivateKey) *ParityOracle {
+
+	return &ParityOracle{priv: priv}
+}
+
+// Query returns the parity of c^d mod n.
+func (o *ParityOracle) Query(c *big.Int) (*big.Int, error) {
+
+	if c.Sign() < 0 || c.Cmp(o.priv.N) >= 0 {
+		return nil, fmt.Errorf("ParityOracle: ciphertext out of range")
+	}
+	m := new(big.Int).Exp(c, o.priv.D, o.priv.N)
+	return big.NewInt(int64(m.Bit(0))), nil
+}
+
+// ParityAttack recovers the plaintext of c = m^e mod n from an oracle
+// answering the parity of decryptions, with one query per bit of n.
+// Multiplying c by 2^e doubles the plaintext: 2m mod n is odd exactly
+// when 2m wrapped around the odd n, i.e. when m > n/2. Every answer
+// thus halves the interval known to contain m, a binary search that
+// pins m down after log2(n) queries.
+// https://crypto.stackexchange.com/questions/11053/rsa-least-significant-bit-oracle-attack
+func ParityAttack(pub *PublicKey, c *big.Int, oracle Oracle) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if pub.N.Bit(0) == 0 {
+		return nil, meter.done(), fmt.Errorf("ParityAttack: modulus must be odd")
+	}
+
+	k := pub.N.BitLen()
+	twoE := new(big.Int).Exp(big.NewInt(2), pub.E, pub.N)
+	meter.exp(pub.E)
+	cur := new(big.Int).Set(c)
+	// After i queries m lies in [n*a/2^i, n*(a+1)/2^i).
+	a := new(big.Int)
+
+	for i := 0; i < k; i++ {
+		cur.Mul(cur, twoE)
+		cur.Mod(cur, pub.N)
+		meter.cost.ModMuls++
+		parity, err := oracle.Query(cur)
+		meter.cost.OracleQueries++
+		if err != nil {
+			return nil, meter.done(), fmt.Errorf("ParityAttack: query %v: %v", i, err)
+		}
+		a.Lsh(a, 1)
+		a.Add(a, parity)
+	}
+
+	// The final interval is narrower than 1, its only integer is m = ceil(n*a / 2^k).
+	m := a.Mul(a, pub.N)
+	pow2 := new(big.Int).Lsh(big.NewInt(1), uint(k))
+	m.Add(m, pow2.Sub(pow2, big.NewInt(1)))
+	m.Rsh(m, uint(k))
+
+	meter.exp(pub.E)
+	if new(big.Int).Exp(m, pub.E, pub.N).Cmp(c) != 0 {
+		return nil, meter.done(), fmt.Errorf("ParityAttack: recovered value does not encrypt to the ciphertext, is the oracle honest?")
+	}
+	return m, meter.done(), nil
+}
+
+// DecryptionOracle is a deliberately vulnerable Oracle returning the raw
+// decryption c^d mod n of any ciphertext except the ones it was told to
+// refuse, as a service that only blacklists the secret ciphertext would.
+type DecryptionOracle struct {
+	priv    *PrivateKey
+	refused []*big.Int
+}
+
+// NewDecryptionOracle returns a DecryptionOracle decrypting with priv
+// that refuses to decrypt the given ciphertexts.
+func NewDecryptionOracle(priv *PrivateKey, refused ...*big.Int) *DecryptionOracle {
+
+	return &DecryptionOracle{priv: priv, refused: refused}
+}
+
+// Query returns c^d mod n unless c is refused.
+func (o *DecryptionOracle) Query(c *big.Int) (*big.Int, error) {
+
+	if c.Sign() < 0 || c.Cmp(o.priv.N) >= 0 {
+		return nil, fmt.Errorf("DecryptionOracle: ciphertext out of range")
+	}
+	for _, r := range o.refused {
+		if c.Cmp(r) == 0 {
+			return nil, fmt.Errorf("DecryptionOracle: refusing to decrypt %v", c)
+		}
+	}
+	return new(big.Int).Exp(c, o.priv.D, o.priv.N), nil
+}
+
+// BlindingAttack recovers the plaintext of c from an oracle decrypting
+// anything else: it asks for the decryption m*r of the unrelated looking
+// c' = c*r^e mod n, RSA being multiplicative, and divides r back out,
+// drawing r from random or crypto/rand.Reader if nil.
+// https://en.wikipedia.org/wiki/Blinding_(cryptography)
+func BlindingAttack(pub *PublicKey, c *big.Int, oracle Oracle, random io.Reader) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if random == nil {
+		random = rand.Reader
+	}
+	var r *big.Int
+	rInv := new(big.Int)
+	// r unblinds the oracle's answer, whoever learns it learns m.
+	defer func() {
+		zeroizeInt(r)
+		zeroizeInt(rInv)
+	}()
+	for {
+		var err error
+		if r, err = rand.Int(random, pub.N); err != nil {
+			return nil, meter.done(), fmt.Errorf("BlindingAttack: %v", err)
+		}
+		if r.Cmp(big.NewInt(1)) > 0 && rInv.ModInverse(r, pub.N) != nil {
+			break
+		}
+	}
+
+	blinded := new(big.Int).Exp(r, pub.E, pub.N)
+	blinded.Mul(blinded, c)
+	blinded.Mod(blinded, pub.N)
+	meter.exp(pub.E)
+	meter.cost.ModMuls++
+
+	m, err := oracle.Query(blinded)
+	meter.cost.OracleQueries++
+	if err != nil {
+		return nil, meter.done(), fmt.Errorf("BlindingAttack: %v", err)
+	}
+	m = new(big.Int).Mul(m, rInv)
+	meter.cost.ModMuls++
+	return m.Mod(m, pub.N), meter.done(), nil
+}
@@ -0,0 +1,57 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestParityAttack(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle := rsa.NewParityOracle(priv)
+
+	for _, msg := range []string{"attack at dawn", "x"} {
+		m, err := rsa.Encode(msg, rsa.Base256Alphabet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := rsa.ModExp(m, m, priv.E, priv.N, rsa.ExpSquareMultiply)
+
+		recovered, cost, err := rsa.ParityAttack(&priv.PublicKey, c, oracle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost.OracleQueries != priv.N.BitLen() {
+			t.Errorf("%v oracle queries, expected one per bit of n", cost.OracleQueries)
+		}
+		if got, _ := rsa.Decode(recovered, rsa.Base256Alphabet); got != msg {
+			t.Errorf("recovered %q, expected %q", got, msg)
+		}
+	}
+}
+
+func TestBlindingAttack(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := rsa.Encode("the secret", rsa.Base256Alphabet)
+	c := rsa.ModExp(m, m, priv.E, priv.N, rsa.ExpSquareMultiply)
+	oracle := rsa.NewDecryptionOracle(priv, c)
+
+	if _, err := oracle.Query(c); err == nil {
+		t.Fatal("oracle decrypted the refused ciphertext")
+	}
+	recovered, _, err := rsa.BlindingAttack(&priv.PublicKey, c, oracle, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := rsa.Decode(recovered, rsa.Base256Alphabet); got != "the secret" {
+		t.Errorf("recovered %q", got)
+	}
+}
@@ -0,0 +1,210 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// PartialBits is a number of which only the bits set in Mask are known,
+// e.g. a key read back from decaying memory after a cold boot.
+type PartialBits struct {
+	Value *big.Int
+	Mask  *big.Int
+}
+
+// known reports whether bit i is known and its value.
+func (pb *PartialBits) known(i int) (bool, uint) {
+
+	if pb == nil || pb.Mask.Bit(i) == 0 {
+		return false, 0
+	}
+	return true, pb.Value.Bit(i)
+}
+
+// PartialKey is a public key together with whatever bits of its private
+// key survived. A nil field is entirely unknown. D must be the textbook
+// exponent e^-1 mod φ(n); for a d reduced modulo λ(n), as NewPrivateKey
+// computes it, leave D nil and rely on the other fields.
+type PartialKey struct {
+	PublicKey
+	P, Q, D, Dp, Dq *PartialBits
+}
+
+// partialCandidate is p and q known modulo 2^i.
+type partialCandidate struct {
+	p, q *big.Int
+}
+
+// BranchAndPrune recovers the private key from random known bits of
+// p, q, d, dp and dq. It builds p and q bit by bit from the least
+// significant end: each of the 4 guesses for the next bits of p and q
+// is kept only if p*q still agrees with n and the d, dp and dq implied by
+// e*d = 1 + k*φ(n), e*dp = 1 + kp*(p-1) and e*dq = 1 + kq*(q-1) agree
+// with their known bits. With enough known bits the wrong branches die
+// out quickly; maxCandidates bounds the width of the search.
+// The multipliers k, kp and kq are below e, k is read off the high
+// bits of d when known, and kp, kq then follow from it.
+// https://eprint.iacr.org/2008/510
+func BranchAndPrune(pk *PartialKey, maxCandidates int) (*PrivateKey, Cost, error) {
+
+	meter := newCostMeter()
+	if pk.N.Bit(0) == 0 {
+		return nil, meter.done(), fmt.Errorf("BranchAndPrune: modulus must be odd")
+	}
+	if pk.D == nil && pk.Dp == nil && pk.Dq == nil {
+		priv, err := branchAndPrune(pk, nil, nil, nil, maxCandidates, meter)
+		return priv, meter.done(), err
+	}
+
+	ks, err := partialKeyMultipliers(pk)
+	if err != nil {
+		return nil, meter.done(), err
+	}
+	for _, k := range ks {
+		// The roots do not tell which multiplier belongs to p.
+		for _, swap := range []bool{false, true} {
+			kp, kq := k[1], k[2]
+			if swap {
+				kp, kq = kq, kp
+			}
+			priv, err := branchAndPrune(pk, k[0], kp, kq, maxCandidates, meter)
+			if err == nil {
+				return priv, meter.done(), nil
+			}
+		}
+	}
+	return nil, meter.done(), fmt.Errorf("BranchAndPrune: no multiplier k leads to a factorization")
+}
+
+// partialKeyMultipliers returns the candidate triples (k, kp, kq).
+func partialKeyMultipliers(pk *PartialKey) ([][3]*big.Int, error) {
+
+	if pk.E.Cmp(big.NewInt(3)) < 0 || !pk.E.ProbablyPrime(20) {
+		return nil, fmt.Errorf("BranchAndPrune: solving for kp and kq needs a prime e > 2, got %v", pk.E)
+	}
+
+	ks := []*big.Int{}
+	if pk.D == nil {
+		for k := big.NewInt(1); k.Cmp(pk.E) < 0; k = new(big.Int).Add(k, big.NewInt(1)) {
+			ks = append(ks, k)
+		}
+	} else {
+		// d ≈ (k*(n+1) + 1) / e agrees with d above the bits of p + q.
+		best, bestScore := new(big.Int), -1
+		approx := new(big.Int)
+		nPlus1 := new(big.Int).Add(pk.N, big.NewInt(1))
+		low := pk.N.BitLen()/2 + 2
+		for k := big.NewInt(1); k.Cmp(pk.E) < 0; k.Add(k, big.NewInt(1)) {
+			approx.Mul(k, nPlus1)
+			approx.Add(approx, big.NewInt(1))
+			approx.Div(approx, pk.E)
+			score := 0
+			for i := low; i < pk.N.BitLen(); i++ {
+				if ok, bit := pk.D.known(i); ok && bit == approx.Bit(i) {
+					score++
+				}
+			}
+			if score > bestScore {
+				best.Set(k)
+				bestScore = score
+			}
+		}
+		ks = append(ks, best)
+	}
+
+	// kp and kq are the roots of x^2 - (k*(n-1) + 1)*x - k mod e.
+	res := [][3]*big.Int{}
+	half := new(big.Int).ModInverse(big.NewInt(2), pk.E)
+	for _, k := range ks {
+		b := new(big.Int).Sub(pk.N, big.NewInt(1))
+		b.Mul(b, k)
+		b.Add(b, big.NewInt(1))
+		disc := new(big.Int).Mul(b, b)
+		disc.Add(disc, new(big.Int).Lsh(k, 2))
+		disc.Mod(disc, pk.E)
+		root := new(big.Int).ModSqrt(disc, pk.E)
+		if root == nil {
+			continue
+		}
+		kp := new(big.Int).Add(b, root)
+		kp.Mul(kp, half).Mod(kp, pk.E)
+		kq := new(big.Int).Sub(b, root)
+		kq.Mul(kq, half).Mod(kq, pk.E)
+		res = append(res, [3]*big.Int{k, kp, kq})
+	}
+	return res, nil
+}
+
+// branchAndPrune runs the search for fixed multipliers, nil if d, dp and dq
+// are unknown, accounting its multiplications to meter.
+func branchAndPrune(pk *PartialKey, k, kp, kq *big.Int, maxCandidates int, meter *costMeter) (*PrivateKey, error) {
+
+	one := big.NewInt(1)
+	eInv := new(big.Int)
+	modulus := new(big.Int)
+	t := new(big.Int)
+
+	// implied reports whether bit i of (1 + m*x) / e, computed modulo
+	// 2^(i+1) where e is invertible, agrees with the known bits.
+	implied := func(pb *PartialBits, m, x *big.Int, i int) bool {
+
+		ok, bit := pb.known(i)
+		if !ok {
+			return true
+		}
+		t.Mul(m, x)
+		t.Add(t, one)
+		t.Mul(t, eInv)
+		meter.cost.ModMuls += 2
+		return t.Bit(i) == bit
+	}
+
+	candidates := []partialCandidate{{p: big.NewInt(1), q: big.NewInt(1)}}
+	bits := (pk.N.BitLen()+1)/2 + 1
+	nMod, prod, phi, pm1 := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+
+	for i := 1; i <= bits; i++ {
+		modulus.Lsh(one, uint(i+1))
+		eInv.ModInverse(pk.E, modulus)
+		nMod.Mod(pk.N, modulus)
+		next := []partialCandidate{}
+
+		for _, c := range candidates {
+			for guess := 0; guess < 4; guess++ {
+				p := new(big.Int).SetBit(c.p, i, uint(guess&1))
+				q := new(big.Int).SetBit(c.q, i, uint(guess>>1))
+				if ok, bit := pk.P.known(i); ok && bit != p.Bit(i) {
+					continue
+				}
+				if ok, bit := pk.Q.known(i); ok && bit != q.Bit(i) {
+					continue
+				}
+				meter.cost.ModMuls++
+				if prod.Mul(p, q).Mod(prod, modulus).Cmp(nMod) != 0 {
+					continue
+				}
+				if k != nil {
+					phi.Sub(pk.N, p)
+					phi.Sub(phi, q)
+					phi.Add(phi, one)
+					if !implied(pk.D, k, phi, i) ||
+						!implied(pk.Dp, kp, pm1.Sub(p, one), i) ||
+						!implied(pk.Dq, kq, pm1.Sub(q, one), i) {
+						continue
+					}
+				}
+				if p.Cmp(one) > 0 && q.Cmp(one) > 0 && prod.Mul(p, q).Cmp(pk.N) == 0 {
+					return NewPrivateKey(p, q, pk.E)
+				}
+				next = append(next, partialCandidate{p: p, q: q})
+			}
+		}
+
+		if len(next) > maxCandidates {
+			return nil, fmt.Errorf("BranchAndPrune: %v candidates at bit %v exceed the limit of %v", len(next), i, maxCandidates)
+		}
+		candidates = next
+		meter.sample()
+	}
+	return nil, fmt.Errorf("BranchAndPrune: no candidate factors n")
+}
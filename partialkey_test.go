@@ -0,0 +1,63 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+// partial keeps each bit of v with probability fraction.
+func partial(rnd *mrand.Rand, v *big.Int, fraction float64) *rsa.PartialBits {
+
+	mask := new(big.Int)
+	for i := 0; i < v.BitLen()+8; i++ {
+		if rnd.Float64() < fraction {
+			mask.SetBit(mask, i, 1)
+		}
+	}
+	return &rsa.PartialBits{Value: new(big.Int).And(v, mask), Mask: mask}
+}
+
+func TestBranchAndPrune(t *testing.T) {
+	rnd := mrand.New(mrand.NewSource(1))
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Textbook d = e^-1 mod φ(n).
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(priv.P, one), new(big.Int).Sub(priv.Q, one))
+	d := new(big.Int).ModInverse(priv.E, phi)
+
+	tests := []struct {
+		name string
+		pk   rsa.PartialKey
+	}{
+		{"p and q", rsa.PartialKey{PublicKey: priv.PublicKey,
+			P: partial(rnd, priv.P, 0.6), Q: partial(rnd, priv.Q, 0.6)}},
+		{"p, q, d, dp and dq", rsa.PartialKey{PublicKey: priv.PublicKey,
+			P: partial(rnd, priv.P, 0.3), Q: partial(rnd, priv.Q, 0.3), D: partial(rnd, d, 0.3),
+			Dp: partial(rnd, priv.Dp, 0.3), Dq: partial(rnd, priv.Dq, 0.3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := rsa.BranchAndPrune(&tt.pk, 1<<16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(priv) {
+				t.Errorf("recovered p = %v, q = %v", got.P, got.Q)
+			}
+		})
+	}
+
+	tooFew := rsa.PartialKey{PublicKey: priv.PublicKey, P: partial(rnd, priv.P, 0.1)}
+	if _, _, err := rsa.BranchAndPrune(&tooFew, 1<<10); err == nil {
+		t.Error("expected the search to exceed its limit with 10% of p known")
+	}
+}
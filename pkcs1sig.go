@@ -0,0 +1,307 @@
+package rsa
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// digestInfoPrefixes are the DER prefixes of the DigestInfo of each hash
+// with an object identifier, by Hash name: the AlgorithmIdentifier
+// followed by the OCTET STRING header. ToyHash has none, so it cannot
+// be used with PKCS#1 v1.5 signatures.
+// https://www.rfc-editor.org/rfc/rfc8017#section-9.2
+var digestInfoPrefixes = map[string][]byte{
+	// 2.16.840.1.101.3.4.2.1
+	"sha256": {
+		0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01,
+		0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20,
+	},
+	// 2.16.840.1.101.3.4.2.8
+	"sha3-256": {
+		0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01,
+		0x65, 0x03, 0x04, 0x02, 0x08, 0x05, 0x00, 0x04, 0x20,
+	},
+}
+
+// digestInfoPrefix returns the DigestInfo prefix of h.
+func digestInfoPrefix(h Hash) ([]byte, error) {
+
+	prefix, ok := digestInfoPrefixes[h.Name]
+	if !ok {
+		return nil, fmt.Errorf("no DigestInfo for hash %q", h.Name)
+	}
+	return prefix, nil
+}
+
+// hashMessage returns the hash h of msg.
+func hashMessage(h Hash, msg []byte) []byte {
+
+	d := h.New()
+	d.Write(msg)
+	return d.Sum(nil)
+}
+
+// pkcs1v15SignatureBlock returns the k byte EMSA-PKCS1-v1_5 encoding of the
+// hash h of msg, 0x00 0x01 0xff...0xff 0x00 DigestInfo.
+func pkcs1v15SignatureBlock(h Hash, msg []byte, k int) ([]byte, error) {
+
+	prefix, err := digestInfoPrefix(h)
+	if err != nil {
+		return nil, err
+	}
+	hash := hashMessage(h, msg)
+	tLen := len(prefix) + len(hash)
+	if k < tLen+3+pkcs1v15MinPadding {
+		return nil, fmt.Errorf("a %v byte modulus is too short for a %v DigestInfo", k, h.Name)
+	}
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-tLen-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-tLen:], prefix)
+	copy(em[k-len(hash):], hash)
+	return em, nil
+}
+
+// SignPKCS1v15 returns the PKCS#1 v1.5 signature with SHA-256 of msg,
+// the encoding 0x00 0x01 0xff...0xff 0x00 DigestInfo raised to d.
+func SignPKCS1v15(priv *PrivateKey, msg []byte) (*big.Int, error) {
+
+	return SignPKCS1v15With(SHA256, priv, msg)
+}
+
+// SignPKCS1v15With is SignPKCS1v15 with the hash h, one of SHA256 and
+// SHA3_256, the registered hashes with a DigestInfo.
+func SignPKCS1v15With(h Hash, priv *PrivateKey, msg []byte) (*big.Int, error) {
+
+	em, err := pkcs1v15SignatureBlock(h, msg, modulusLen(priv.N))
+	if err != nil {
+		return nil, fmt.Errorf("SignPKCS1v15With: %v", err)
+	}
+	x := new(big.Int).SetBytes(em)
+	return x.Exp(x, priv.D, priv.N), nil
+}
+
+// VerifyPKCS1v15 checks a SignPKCS1v15 signature the safe way: it encodes
+// msg itself and compares the whole block with sig^e mod n, leaving no
+// byte of it unchecked.
+func VerifyPKCS1v15(pub *PublicKey, msg []byte, sig *big.Int) error {
+
+	return VerifyPKCS1v15With(SHA256, pub, msg, sig)
+}
+
+// VerifyPKCS1v15With is VerifyPKCS1v15 with the hash h.
+func VerifyPKCS1v15With(h Hash, pub *PublicKey, msg []byte, sig *big.Int) error {
+
+	em, err := pkcs1v15SignatureBlock(h, msg, modulusLen(pub.N))
+	if err != nil {
+		return fmt.Errorf("VerifyPKCS1v15With: %v", err)
+	}
+	if sig.Sign() <= 0 || sig.Cmp(pub.N) >= 0 {
+		return fmt.Errorf("VerifyPKCS1v15With: signature out of range")
+	}
+	got := new(big.Int).Exp(sig, pub.E, pub.N).FillBytes(make([]byte, len(em)))
+	if !bytes.Equal(got, em) {
+		return fmt.Errorf("VerifyPKCS1v15With: signature does not match the message")
+	}
+	return nil
+}
+
+// VerifyPKCS1v15Lenient checks a SignPKCS1v15 signature the sloppy way
+// several libraries once did: it parses sig^e mod n from the left,
+// skipping the 0xff bytes up to the 0x00, then compares the DigestInfo
+// and the hash following it, but never checks that the hash ends the
+// block. Whatever follows is ignored, which is enough room to forge
+// signatures for small e, see BleichenbacherForgery. Never use it.
+// It is the verifier of NewSignatureVerifier(IgnoreTrailingBytes|ShortPadding).
+func VerifyPKCS1v15Lenient(pub *PublicKey, msg []byte, sig *big.Int) error {
+
+	if err := verifyPKCS1v15Parsed(SHA256, pub, msg, sig, IgnoreTrailingBytes|ShortPadding); err != nil {
+		return fmt.Errorf("VerifyPKCS1v15Lenient: %v", err)
+	}
+	return nil
+}
+
+// SignatureFlaw is a set of historical bugs of PKCS#1 v1.5 signature
+// verifiers that parse the block rather than rebuild it.
+type SignatureFlaw uint
+
+const (
+	// IgnoreTrailingBytes stops reading after the hash, leaving room for
+	// the garbage of BleichenbacherForgery.
+	IgnoreTrailingBytes SignatureFlaw = 1 << iota
+	// SkipAlgorithmCheck steps over the AlgorithmIdentifier of the
+	// DigestInfo by its encoded length without comparing it, another place
+	// to hide garbage. Kühn et al., Variants of Bleichenbacher's
+	// low-exponent attack on PKCS#1 RSA signatures, 2008.
+	SkipAlgorithmCheck
+	// ShortPadding accepts fewer than 8 0xff padding bytes.
+	ShortPadding
+)
+
+// signatureFlawNames are the names String joins, in bit order.
+var signatureFlawNames = []string{"ignore-trailing-bytes", "skip-algorithm-check", "short-padding"}
+
+// String names the flaws joined by |, or "strict" for none.
+func (flaws SignatureFlaw) String() string {
+
+	if flaws == 0 {
+		return "strict"
+	}
+	var names []string
+	for i, name := range signatureFlawNames {
+		if flaws&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if rest := flaws &^ (1<<len(signatureFlawNames) - 1); rest != 0 {
+		names = append(names, fmt.Sprintf("SignatureFlaw(%#x)", uint(rest)))
+	}
+	return strings.Join(names, "|")
+}
+
+// SignatureVerifier checks PKCS#1 v1.5 signatures made by SignPKCS1v15
+// or SignPKCS1v15With.
+type SignatureVerifier interface {
+	Verify(pub *PublicKey, msg []byte, sig *big.Int) error
+}
+
+// NewSignatureVerifier returns the SHA-256 verifier with the given flaws,
+// so a forgery can be run against every variant to see which accept it:
+// with no flaws it is VerifyPKCS1v15, otherwise a parser of sig^e mod n
+// that has exactly those flaws.
+func NewSignatureVerifier(flaws SignatureFlaw) SignatureVerifier {
+
+	return NewSignatureVerifierWith(SHA256, flaws)
+}
+
+// NewSignatureVerifierWith is NewSignatureVerifier with the hash h.
+func NewSignatureVerifierWith(h Hash, flaws SignatureFlaw) SignatureVerifier {
+
+	return signatureVerifier{hash: h, flaws: flaws}
+}
+
+// signatureVerifier is the SignatureVerifier of its hash and flaws.
+type signatureVerifier struct {
+	hash  Hash
+	flaws SignatureFlaw
+}
+
+func (v signatureVerifier) Verify(pub *PublicKey, msg []byte, sig *big.Int) error {
+
+	if v.flaws == 0 {
+		return VerifyPKCS1v15With(v.hash, pub, msg, sig)
+	}
+	if err := verifyPKCS1v15Parsed(v.hash, pub, msg, sig, v.flaws); err != nil {
+		return fmt.Errorf("Verify (%v): %v", v.flaws, err)
+	}
+	return nil
+}
+
+// verifyPKCS1v15Parsed reads sig^e mod n from the left as
+// 0x00 0x01 0xff...0xff 0x00 DigestInfo of the hash h, checking all of
+// it but what flaws skip.
+func verifyPKCS1v15Parsed(h Hash, pub *PublicKey, msg []byte, sig *big.Int, flaws SignatureFlaw) error {
+
+	prefix, err := digestInfoPrefix(h)
+	if err != nil {
+		return err
+	}
+	k := modulusLen(pub.N)
+	if sig.Sign() <= 0 || sig.Cmp(pub.N) >= 0 {
+		return fmt.Errorf("signature out of range")
+	}
+	em := new(big.Int).Exp(sig, pub.E, pub.N).FillBytes(make([]byte, k))
+	if em[0] != 0 || em[1] != 1 {
+		return fmt.Errorf("not a signature block")
+	}
+	i := 2
+	for i < k && em[i] == 0xff {
+		i++
+	}
+	minPadding := pkcs1v15MinPadding
+	if flaws&ShortPadding != 0 {
+		minPadding = 1
+	}
+	if i-2 < minPadding || i == k || em[i] != 0 {
+		return fmt.Errorf("malformed padding")
+	}
+	rest := em[i+1:]
+
+	// DigestInfo ::= SEQUENCE { AlgorithmIdentifier, OCTET STRING }
+	algorithm := prefix[2 : len(prefix)-2]
+	if flaws&SkipAlgorithmCheck != 0 {
+		if len(rest) < 4 || rest[0] != 0x30 || rest[2] != 0x30 || rest[3] >= 0x80 || int(rest[3]) > len(rest)-4 {
+			return fmt.Errorf("malformed DigestInfo")
+		}
+		algorithm = rest[2 : 4+rest[3]]
+	}
+	hash := hashMessage(h, msg)
+	digestInfo := append([]byte{0x30, byte(len(algorithm) + 2 + len(hash))}, algorithm...)
+	digestInfo = append(digestInfo, 0x04, byte(len(hash)))
+	digestInfo = append(digestInfo, hash...)
+	if !bytes.HasPrefix(rest, digestInfo) {
+		return fmt.Errorf("signature does not match the message")
+	}
+	if flaws&IgnoreTrailingBytes == 0 && len(rest) != len(digestInfo) {
+		return fmt.Errorf("%v bytes follow the hash", len(rest)-len(digestInfo))
+	}
+	return nil
+}
+
+// BleichenbacherForgery forges a signature of msg that
+// VerifyPKCS1v15Lenient accepts under pub, without the private key, for a
+// small e such as 3: it builds the block 0x00 0x01 0xff 0x00 DigestInfo
+// with zero garbage after the hash, and takes the integer e-th root of it,
+// rounded up. Raising the root to e gives back the chosen prefix, as long
+// as the garbage is wide enough to absorb the rounding error of about
+// e * s^(e-1), so the modulus must be over e times longer than the prefix:
+// 55 bytes with SHA-256, a 2048 bit n for e = 3. No reduction modulo n
+// takes place, which is why only a small e works; VerifyPKCS1v15 rejects
+// the forgery as the garbage is not 0xff padding.
+// Hal Finney, Bleichenbacher's RSA signature forgery based on
+// implementation error, 2006.
+func BleichenbacherForgery(pub *PublicKey, msg []byte) (*big.Int, Cost, error) {
+
+	return BleichenbacherForgeryWith(SHA256, pub, msg)
+}
+
+// BleichenbacherForgeryWith is BleichenbacherForgery with the hash h,
+// forging for NewSignatureVerifierWith(h, IgnoreTrailingBytes|ShortPadding).
+func BleichenbacherForgeryWith(h Hash, pub *PublicKey, msg []byte) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if !pub.E.IsInt64() || pub.E.Int64() < 2 || pub.E.Int64() > 64 {
+		return nil, meter.done(), fmt.Errorf("BleichenbacherForgeryWith: e = %v is not a small exponent", pub.E)
+	}
+	digestInfo, err := digestInfoPrefix(h)
+	if err != nil {
+		return nil, meter.done(), fmt.Errorf("BleichenbacherForgeryWith: %v", err)
+	}
+	e := uint(pub.E.Int64())
+	k := modulusLen(pub.N)
+
+	prefix := append([]byte{0x00, 0x01, 0xff, 0x00}, digestInfo...)
+	prefix = append(prefix, hashMessage(h, msg)...)
+	garbageBits := 8 * (k - len(prefix))
+	// s^e - target < e * s^(e-1) + ... must stay below 2^garbageBits.
+	if garbageBits <= int(e-1)*8*k/int(e)+int(e) {
+		return nil, meter.done(), fmt.Errorf("BleichenbacherForgeryWith: a %v byte modulus leaves too little room after the %v byte prefix for e = %v", k, len(prefix), e)
+	}
+
+	target := new(big.Int).SetBytes(prefix)
+	target.Lsh(target, uint(garbageBits))
+	s := nthRoot(new(big.Int), target, e)
+	if new(big.Int).Exp(s, pub.E, nil).Cmp(target) < 0 {
+		s.Add(s, big.NewInt(1))
+	}
+	meter.exp(pub.E)
+	if err := verifyPKCS1v15Parsed(h, pub, msg, s, IgnoreTrailingBytes|ShortPadding); err != nil {
+		return nil, meter.done(), fmt.Errorf("BleichenbacherForgeryWith: %v", err)
+	}
+	meter.exp(pub.E)
+	return s, meter.done(), nil
+}
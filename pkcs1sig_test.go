@@ -0,0 +1,157 @@
+package rsa_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	stdrsa "crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha3"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestSignPKCS1v15(t *testing.T) {
+	priv, err := rsa.GenerateKeyPair(rand.Reader, 1024, big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("pay 100 to bob")
+	sig, err := rsa.SignPKCS1v15(priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(&priv.PublicKey, msg, sig); err != nil {
+		t.Error(err)
+	}
+	if err := rsa.VerifyPKCS1v15Lenient(&priv.PublicKey, msg, sig); err != nil {
+		t.Error(err)
+	}
+	other := []byte("pay 900 to bob")
+	if rsa.VerifyPKCS1v15(&priv.PublicKey, other, sig) == nil || rsa.VerifyPKCS1v15Lenient(&priv.PublicKey, other, sig) == nil {
+		t.Error("signature verified for another message")
+	}
+}
+
+func TestSignPKCS1v15With(t *testing.T) {
+	priv, err := rsa.GenerateKeyPair(rand.Reader, 1024, big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("pay 100 to bob")
+	sig, err := rsa.SignPKCS1v15With(rsa.SHA3_256, priv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15With(rsa.SHA3_256, &priv.PublicKey, msg, sig); err != nil {
+		t.Error(err)
+	}
+	if err := rsa.NewSignatureVerifierWith(rsa.SHA3_256, rsa.IgnoreTrailingBytes).Verify(&priv.PublicKey, msg, sig); err != nil {
+		t.Error(err)
+	}
+	if rsa.VerifyPKCS1v15(&priv.PublicKey, msg, sig) == nil {
+		t.Error("a SHA3-256 signature verified as SHA-256")
+	}
+
+	// The same block as crypto/rsa, so the DigestInfo prefix is right.
+	key := &stdrsa.PrivateKey{
+		PublicKey: stdrsa.PublicKey{N: priv.N, E: int(priv.E.Int64())},
+		D:         priv.D,
+		Primes:    []*big.Int{priv.P, priv.Q},
+	}
+	key.Precompute()
+	hash := sha3.Sum256(msg)
+	expected, err := stdrsa.SignPKCS1v15(nil, key, crypto.SHA3_256, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig.FillBytes(make([]byte, len(expected))), expected) {
+		t.Errorf("SHA3-256 signature differs from crypto/rsa")
+	}
+
+	if _, err := rsa.SignPKCS1v15With(rsa.ToyHash, priv, msg); err == nil {
+		t.Error("expected an error signing with a hash without a DigestInfo")
+	}
+}
+
+func TestBleichenbacherForgery(t *testing.T) {
+	priv, err := rsa.GenerateKeyPair(rand.Reader, 2048, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("pay 1000000 to mallory")
+	sig, cost, err := rsa.BleichenbacherForgery(&priv.PublicKey, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15Lenient(&priv.PublicKey, msg, sig); err != nil {
+		t.Errorf("lenient verifier rejected the forgery: %v", err)
+	}
+	if err := rsa.VerifyPKCS1v15(&priv.PublicKey, msg, sig); err == nil {
+		t.Error("strict verifier accepted the forgery")
+	}
+	t.Logf("forged in %v", cost.Duration)
+
+	// The prefix does not fit a third of a 1024 bit modulus.
+	small, _ := rsa.GenerateKeyPair(rand.Reader, 1024, big.NewInt(3))
+	if _, _, err := rsa.BleichenbacherForgery(&small.PublicKey, msg); err == nil {
+		t.Error("expected no room for a forgery with a 1024 bit modulus")
+	}
+}
+
+// signBlock returns the raw signature of the block parts, left padded
+// with zeros to the modulus size.
+func signBlock(priv *rsa.PrivateKey, parts ...[]byte) *big.Int {
+
+	em := bytes.Join(parts, nil)
+	x := new(big.Int).SetBytes(em)
+	return x.Exp(x, priv.D, priv.N)
+}
+
+func TestSignatureVerifiers(t *testing.T) {
+	priv, err := rsa.GenerateKeyPair(rand.Reader, 1024, big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("pay 100 to bob")
+	hash := sha256.Sum256(msg)
+	k := (priv.N.BitLen() + 7) / 8
+	algorithm := []byte{0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00}
+	// SHA-1's OID with the NULL parameters, same length.
+	otherAlgorithm := []byte{0x30, 0x0d, 0x06, 0x09, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x00, 0x00, 0x00, 0x00, 0x05, 0x00}
+	digestInfo := func(algorithm []byte) []byte {
+
+		return slices.Concat([]byte{0x30, 0x31}, algorithm, []byte{0x04, 0x20}, hash[:])
+	}
+	ffs := func(n int) []byte { return bytes.Repeat([]byte{0xff}, n) }
+
+	valid, _ := rsa.SignPKCS1v15(priv, msg)
+	padding := k - 3 - 51
+	trailing := signBlock(priv, []byte{0, 1}, ffs(padding-4), []byte{0}, digestInfo(algorithm), make([]byte, 4))
+	wrongOID := signBlock(priv, []byte{0, 1}, ffs(padding), []byte{0}, digestInfo(otherAlgorithm))
+	short := signBlock(priv, []byte{0, 1}, ffs(4), []byte{0}, digestInfo(algorithm), make([]byte, padding-4))
+
+	for _, tc := range []struct {
+		flaws    rsa.SignatureFlaw
+		accepted []*big.Int
+	}{
+		{0, []*big.Int{valid}},
+		{rsa.IgnoreTrailingBytes, []*big.Int{valid, trailing}},
+		{rsa.SkipAlgorithmCheck, []*big.Int{valid, wrongOID}},
+		{rsa.IgnoreTrailingBytes | rsa.ShortPadding, []*big.Int{valid, trailing, short}},
+	} {
+		verifier := rsa.NewSignatureVerifier(tc.flaws)
+		for name, sig := range map[string]*big.Int{"valid": valid, "trailing": trailing, "wrong OID": wrongOID, "short padding": short} {
+			err := verifier.Verify(&priv.PublicKey, msg, sig)
+			if expected := slices.Contains(tc.accepted, sig); (err == nil) != expected {
+				t.Errorf("%v verifier on the %v signature: %v, expected acceptance %v", tc.flaws, name, err, expected)
+			}
+		}
+	}
+	if s := (rsa.IgnoreTrailingBytes | rsa.ShortPadding).String(); s != "ignore-trailing-bytes|short-padding" {
+		t.Errorf("flaws named %q", s)
+	}
+}
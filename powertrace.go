@@ -0,0 +1,103 @@
+package rsa
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+)
+
+// PowerModel turns the operations of a modular exponentiation into a
+// synthetic power trace: each operation contributes SamplesPerOp samples
+// at its level, plus a data dependent part proportional to the Hamming
+// weight of the intermediate result, plus Gaussian noise.
+// Distinct Square and Multiply levels make simple power analysis (SPA)
+// possible, DataWeight leaks the processed values for differential
+// power analysis (DPA).
+type PowerModel struct {
+	Square       float64
+	Multiply     float64
+	DataWeight   float64
+	Noise        float64 // standard deviation
+	SamplesPerOp int
+}
+
+// DefaultPowerModel makes multiplications visibly costlier than squarings.
+var DefaultPowerModel = PowerModel{
+	Square:       1.0,
+	Multiply:     1.5,
+	DataWeight:   0.2,
+	Noise:        0.05,
+	SamplesPerOp: 4,
+}
+
+// ExpOperations returns the squarings 'S' and multiplications 'M'
+// ModExp performs for base^exp mod modulus in the given mode, e.g.
+// "SMSSM" for square and multiply with exp = 0b101. Reading the
+// exponent back from it is what SPA does.
+func ExpOperations(exp, modulus *big.Int, mode ExpMode) string {
+
+	var ops []byte
+	modExpObserved(big.NewInt(2), exp, modulus, mode, func(op byte, _ *big.Int) {
+		ops = append(ops, op)
+	})
+	return string(ops)
+}
+
+// SimulatePowerTrace runs ModExp(base, exp, modulus, mode) and records
+// the power trace model predicts for it, noise drawn from rnd.
+func SimulatePowerTrace(base, exp, modulus *big.Int, mode ExpMode, model PowerModel, rnd *rand.Rand) []float64 {
+
+	var trace []float64
+	bitLen := float64(modulus.BitLen())
+
+	modExpObserved(base, exp, modulus, mode, func(op byte, value *big.Int) {
+		level := model.Square
+		if op == opMultiply {
+			level = model.Multiply
+		}
+		level += model.DataWeight * float64(hammingWeight(value)) / bitLen
+		for i := 0; i < max(model.SamplesPerOp, 1); i++ {
+			trace = append(trace, level+rnd.NormFloat64()*model.Noise)
+		}
+	})
+	return trace
+}
+
+// WritePowerTracesCSV writes one trace per line as comma separated
+// values, loadable with numpy.loadtxt(path, delimiter=",").
+// Traces of different lengths are padded with their last sample.
+// Nothing is written if any trace is empty.
+func WritePowerTracesCSV(w io.Writer, traces [][]float64) error {
+
+	width := 0
+	for i, trace := range traces {
+		if len(trace) == 0 {
+			return fmt.Errorf("WritePowerTracesCSV: trace %v is empty", i)
+		}
+		width = max(width, len(trace))
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, trace := range traces {
+		for j := 0; j < width; j++ {
+			if j > 0 {
+				bw.WriteByte(',')
+			}
+			fmt.Fprintf(bw, "%.6f", trace[min(j, len(trace)-1)])
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// hammingWeight counts the 1 bits of a nonnegative number.
+func hammingWeight(n *big.Int) int {
+
+	weight := 0
+	for i := 0; i < n.BitLen(); i++ {
+		weight += int(n.Bit(i))
+	}
+	return weight
+}
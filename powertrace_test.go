@@ -0,0 +1,51 @@
+package rsa_test
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestExpOperations(t *testing.T) {
+	modulus := big.NewInt(937513)
+
+	if ops := rsa.ExpOperations(big.NewInt(0b101), modulus, rsa.ExpSquareMultiply); ops != "SMSSM" {
+		t.Errorf("square and multiply of 0b101 gave %v, expected SMSSM", ops)
+	}
+	// The constant-time schedule does not depend on the exponent's bits.
+	light := rsa.ExpOperations(big.NewInt(1), modulus, rsa.ExpConstantTime)
+	heavy := rsa.ExpOperations(big.NewInt(0xfffff), modulus, rsa.ExpConstantTime)
+	if light != heavy {
+		t.Errorf("constant-time schedules differ:\n%v\n%v", light, heavy)
+	}
+}
+
+func TestSimulatePowerTrace(t *testing.T) {
+	modulus := big.NewInt(937513)
+	exp := big.NewInt(638471)
+	rnd := rand.New(rand.NewSource(4))
+
+	model := rsa.DefaultPowerModel
+	trace := rsa.SimulatePowerTrace(big.NewInt(888888), exp, modulus, rsa.ExpSquareMultiply, model, rnd)
+	ops := rsa.ExpOperations(exp, modulus, rsa.ExpSquareMultiply)
+	if len(trace) != len(ops)*model.SamplesPerOp {
+		t.Fatalf("trace has %v samples for %v operations", len(trace), len(ops))
+	}
+
+	var sb strings.Builder
+	if err := rsa.WritePowerTracesCSV(&sb, [][]float64{trace, trace[:10]}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if len(lines) != 2 || strings.Count(lines[0], ",") != len(trace)-1 || strings.Count(lines[1], ",") != len(trace)-1 {
+		t.Errorf("unexpected CSV shape: %v lines", len(lines))
+	}
+
+	sb.Reset()
+	if err := rsa.WritePowerTracesCSV(&sb, [][]float64{trace, nil}); err == nil || sb.Len() != 0 {
+		t.Errorf("expected an error and no output for an empty trace, got %v and %v bytes", err, sb.Len())
+	}
+}
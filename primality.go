@@ -0,0 +1,282 @@
+package rsa
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"text/tabwriter"
+	"time"
+)
+
+// KnownPseudoprimes lists small composites that fool at least one of the
+// probabilistic tests for some bases: Carmichael numbers pass the Fermat
+// test for every coprime base, 2047 and 3277 are strong pseudoprimes
+// to base 2 and 1905 is an Euler-Jacobi pseudoprime to base 2.
+// https://oeis.org/A002997 https://oeis.org/A001262 https://oeis.org/A047713
+var KnownPseudoprimes = []int64{561, 1105, 1729, 1905, 2047, 2465, 2821, 3277, 4033, 4681, 6601, 8321, 8911, 10585, 15841, 29341, 41041, 46657, 52633, 62745, 63973, 75361, 101101, 115921, 126217, 162401, 172081, 188461, 252601, 278545, 294409, 314821, 334153, 340561, 399001, 410041, 449065, 488881, 512461}
+
+// FermatProbablyPrime reports whether n passes rounds Fermat tests
+// a^(n-1) = 1 mod n with random bases a from rnd.
+// https://en.wikipedia.org/wiki/Fermat_primality_test
+func FermatProbablyPrime(n *big.Int, rounds int, rnd *rand.Rand) bool {
+
+	if small, ok := smallPrimality(n); ok {
+		return small
+	}
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	r := new(big.Int)
+	for i := 0; i < rounds; i++ {
+		if r.Exp(randomBase(n, rnd), nMinus1, n).Cmp(big.NewInt(1)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MillerRabin reports whether n passes rounds Miller-Rabin tests with
+// random bases from rnd: writing n-1 = 2^s * t with t odd, a^t must be 1
+// or reach n-1 by repeated squaring. A composite passes a round with
+// probability at most 1/4.
+// https://en.wikipedia.org/wiki/Miller%E2%80%93Rabin_primality_test
+func MillerRabin(n *big.Int, rounds int, rnd *rand.Rand) bool {
+
+	if small, ok := smallPrimality(n); ok {
+		return small
+	}
+	one := big.NewInt(1)
+	nMinus1 := new(big.Int).Sub(n, one)
+	s := nMinus1.TrailingZeroBits()
+	t := new(big.Int).Rsh(nMinus1, s)
+	x := new(big.Int)
+
+rounds:
+	for i := 0; i < rounds; i++ {
+		x.Exp(randomBase(n, rnd), t, n)
+		if x.Cmp(one) == 0 || x.Cmp(nMinus1) == 0 {
+			continue
+		}
+		for j := uint(1); j < s; j++ {
+			x.Mul(x, x)
+			x.Mod(x, n)
+			if x.Cmp(nMinus1) == 0 {
+				continue rounds
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// SolovayStrassen reports whether n passes rounds Solovay-Strassen tests
+// a^((n-1)/2) = (a/n) mod n, the Jacobi symbol, with random bases from rnd.
+// A composite passes a round with probability at most 1/2.
+// https://en.wikipedia.org/wiki/Solovay%E2%80%93Strassen_primality_test
+func SolovayStrassen(n *big.Int, rounds int, rnd *rand.Rand) bool {
+
+	if small, ok := smallPrimality(n); ok {
+		return small
+	}
+	half := new(big.Int).Rsh(n, 1)
+	x, jacobi := new(big.Int), new(big.Int)
+	for i := 0; i < rounds; i++ {
+		a := randomBase(n, rnd)
+		j := big.Jacobi(a, n)
+		if j == 0 {
+			return false
+		}
+		jacobi.SetInt64(int64(j))
+		if x.Exp(a, half, n).Cmp(jacobi.Mod(jacobi, n)) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// KnownFrobeniusPseudoprimes lists the composites below 200000 that pass
+// QuadraticFrobeniusTest for the Fibonacci polynomial x^2 - x - 1, a = 1
+// and b = -1. Every fixed polynomial has such pseudoprimes, which is why
+// QuadraticFrobenius draws a new one every round.
+// https://oeis.org/A212424
+var KnownFrobeniusPseudoprimes = []int64{5777, 10877, 75077, 100127, 113573, 161027, 162133}
+
+// QuadraticFrobeniusTest reports whether the odd n > 2 passes the Frobenius
+// test with respect to f(x) = x^2 - ax + b, whose discriminant
+// Δ = a^2 - 4b must have the Jacobi symbol (Δ/n) = -1, and gcd(n, b) = 1.
+// For a prime n f is then irreducible, (Z/n)[x]/f is the field of n^2
+// elements and x^n is the other root of f, a - x, by the Frobenius
+// automorphism; n must pass x^n = a - x and b^(n-1) = 1 mod n.
+// Passing implies passing the Lucas test with P = a and Q = b.
+// Crandall and Pomerance, Prime Numbers: A Computational Perspective, 3.6.
+// https://en.wikipedia.org/wiki/Frobenius_pseudoprime
+func QuadraticFrobeniusTest(n, a, b *big.Int) (bool, error) {
+
+	delta := new(big.Int).Mul(a, a)
+	delta.Sub(delta, new(big.Int).Lsh(b, 2))
+	if n.Bit(0) == 0 || n.Cmp(big.NewInt(3)) < 0 {
+		return false, fmt.Errorf("QuadraticFrobeniusTest: %v is not an odd number > 2", n)
+	}
+	if big.Jacobi(new(big.Int).Mod(delta, n), n) != -1 {
+		return false, fmt.Errorf("QuadraticFrobeniusTest: (Δ/n) = (%v/%v) is not -1", delta, n)
+	}
+	if new(big.Int).GCD(nil, nil, new(big.Int).Mod(b, n), n).Cmp(big.NewInt(1)) != 0 {
+		return false, nil
+	}
+
+	c0, c1 := frobeniusPowX(n, a, b, n)
+	conjugate := new(big.Int).Mod(a, n)
+	if c0.Cmp(conjugate) != 0 || c1.Cmp(new(big.Int).Sub(n, big.NewInt(1))) != 0 {
+		return false, nil
+	}
+	nMinus1 := new(big.Int).Sub(n, big.NewInt(1))
+	return new(big.Int).Exp(new(big.Int).Mod(b, n), nMinus1, n).Cmp(big.NewInt(1)) == 0, nil
+}
+
+// QuadraticFrobenius reports whether n passes rounds QuadraticFrobeniusTest
+// rounds, each with a random polynomial x^2 - ax + b from rnd, so that the
+// pseudoprimes of any one polynomial, such as KnownFrobeniusPseudoprimes,
+// are caught by the others. Squares, for which no (Δ/n) is -1, are
+// rejected first.
+func QuadraticFrobenius(n *big.Int, rounds int, rnd *rand.Rand) bool {
+
+	if small, ok := smallPrimality(n); ok {
+		return small
+	}
+	if root := new(big.Int).Sqrt(n); root.Mul(root, root).Cmp(n) == 0 {
+		return false
+	}
+
+	delta := new(big.Int)
+	for i := 0; i < rounds; i++ {
+		var a, b *big.Int
+		for {
+			a, b = randomBase(n, rnd), randomBase(n, rnd)
+			delta.Mul(a, a)
+			delta.Sub(delta, new(big.Int).Lsh(b, 2))
+			if big.Jacobi(delta.Mod(delta, n), n) == -1 {
+				break
+			}
+		}
+		if pass, _ := QuadraticFrobeniusTest(n, a, b); !pass {
+			return false
+		}
+	}
+	return true
+}
+
+// frobeniusPowX returns x^e in (Z/n)[x]/(x^2 - ax + b) as c0 + c1*x, by
+// square and multiply on the polynomials with x^2 = ax - b.
+func frobeniusPowX(e, a, b, n *big.Int) (c0, c1 *big.Int) {
+
+	c0, c1 = big.NewInt(1), big.NewInt(0)
+	t0, t1, sq := new(big.Int), new(big.Int), new(big.Int)
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		// (c0 + c1x)^2 = (c0^2 - b*c1^2) + (2*c0*c1 + a*c1^2)x
+		sq.Mul(c1, c1)
+		t1.Mul(c0, c1)
+		t1.Add(t1.Lsh(t1, 1), new(big.Int).Mul(a, sq))
+		t0.Mul(c0, c0)
+		t0.Sub(t0, sq.Mul(sq, b))
+		c0.Mod(t0, n)
+		c1.Mod(t1, n)
+		if e.Bit(i) == 1 {
+			// (c0 + c1x)x = -b*c1 + (c0 + a*c1)x
+			t0.Mul(b, c1)
+			t1.Mul(a, c1)
+			c1.Mod(t1.Add(t1, c0), n)
+			c0.Mod(t0.Neg(t0), n)
+		}
+	}
+	return c0, c1
+}
+
+// BailliePSW reports whether n passes the Baillie-PSW test, a base 2
+// strong test followed by a strong Lucas test, which math/big implements
+// as ProbablyPrime(0). No composite passing it is known.
+// https://en.wikipedia.org/wiki/Baillie%E2%80%93PSW_primality_test
+func BailliePSW(n *big.Int) bool {
+
+	return n.ProbablyPrime(0)
+}
+
+// PrimalityReport is the outcome of one test over a candidate set.
+type PrimalityReport struct {
+	Test       string
+	Candidates int
+	// FalsePositives counts composites reported prime.
+	// None of the tests ever rejects a prime.
+	FalsePositives int
+	Duration       time.Duration
+}
+
+// ComparePrimalityTests runs every test over the candidates, the
+// probabilistic ones with rounds random bases from rnd, and counts the
+// composites each lets through. The reference is ProbablyPrime(20),
+// exact below 2^64 where the pseudoprime lists live.
+func ComparePrimalityTests(candidates []*big.Int, rounds int, rnd *rand.Rand) []PrimalityReport {
+
+	tests := []struct {
+		name string
+		test func(n *big.Int) bool
+	}{
+		{"Fermat", func(n *big.Int) bool { return FermatProbablyPrime(n, rounds, rnd) }},
+		{"Miller-Rabin", func(n *big.Int) bool { return MillerRabin(n, rounds, rnd) }},
+		{"Solovay-Strassen", func(n *big.Int) bool { return SolovayStrassen(n, rounds, rnd) }},
+		{"Quadratic Frobenius", func(n *big.Int) bool { return QuadraticFrobenius(n, rounds, rnd) }},
+		{"Baillie-PSW", BailliePSW},
+	}
+
+	prime := make([]bool, len(candidates))
+	for i, n := range candidates {
+		prime[i] = n.ProbablyPrime(20)
+	}
+
+	reports := []PrimalityReport{}
+	for _, tt := range tests {
+		report := PrimalityReport{Test: tt.name, Candidates: len(candidates)}
+		start := time.Now()
+		for i, n := range candidates {
+			if tt.test(n) && !prime[i] {
+				report.FalsePositives++
+			}
+		}
+		report.Duration = time.Since(start)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// WritePrimalityTable writes the reports as an aligned text table.
+func WritePrimalityTable(w io.Writer, reports []PrimalityReport) error {
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "test\tcandidates\tfalse positives\terror rate\ttime")
+	for _, r := range reports {
+		rate := 0.0
+		if r.Candidates > 0 {
+			rate = float64(r.FalsePositives) / float64(r.Candidates)
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%.4f\t%v\n", r.Test, r.Candidates, r.FalsePositives, rate, r.Duration)
+	}
+	return tw.Flush()
+}
+
+// smallPrimality decides n < 5 and even n, reporting false for ok otherwise.
+func smallPrimality(n *big.Int) (prime bool, ok bool) {
+
+	if n.Cmp(big.NewInt(5)) < 0 {
+		return n.Cmp(big.NewInt(2)) == 0 || n.Cmp(big.NewInt(3)) == 0, true
+	}
+	if n.Bit(0) == 0 {
+		return false, true
+	}
+	return false, false
+}
+
+// randomBase returns a base in [2, n-2] for n >= 5.
+func randomBase(n *big.Int, rnd *rand.Rand) *big.Int {
+
+	a := new(big.Int).Sub(n, big.NewInt(3))
+	a.Rand(rnd, a)
+	return a.Add(a, big.NewInt(2))
+}
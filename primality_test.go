@@ -0,0 +1,102 @@
+package rsa_test
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestPrimalityTests(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range []int64{2, 3, 5, 7, 97, 7919, 1000003, 2147483647} {
+		b := big.NewInt(n)
+		if !rsa.FermatProbablyPrime(b, 5, rnd) || !rsa.MillerRabin(b, 5, rnd) ||
+			!rsa.SolovayStrassen(b, 5, rnd) || !rsa.BailliePSW(b) {
+			t.Errorf("prime %v rejected", n)
+		}
+	}
+	for _, n := range []int64{0, 1, 4, 9, 91, 1000001} {
+		b := big.NewInt(n)
+		if rsa.FermatProbablyPrime(b, 5, rnd) || rsa.MillerRabin(b, 5, rnd) ||
+			rsa.SolovayStrassen(b, 5, rnd) || rsa.BailliePSW(b) {
+			t.Errorf("composite %v accepted", n)
+		}
+	}
+	for _, n := range rsa.KnownPseudoprimes {
+		if big.NewInt(n).ProbablyPrime(20) {
+			t.Errorf("pseudoprime %v is prime", n)
+		}
+	}
+}
+
+func TestComparePrimalityTests(t *testing.T) {
+	candidates := []*big.Int{}
+	for _, n := range rsa.KnownPseudoprimes {
+		candidates = append(candidates, big.NewInt(n))
+	}
+
+	reports := rsa.ComparePrimalityTests(candidates, 1, rand.New(rand.NewSource(1)))
+	errors := map[string]int{}
+	for _, r := range reports {
+		errors[r.Test] = r.FalsePositives
+	}
+	if errors["Fermat"] == 0 {
+		t.Error("a single Fermat round let no Carmichael number through")
+	}
+	if errors["Fermat"] <= errors["Miller-Rabin"] {
+		t.Errorf("Miller-Rabin (%v) no better than Fermat (%v)", errors["Miller-Rabin"], errors["Fermat"])
+	}
+	if errors["Quadratic Frobenius"] != 0 {
+		t.Errorf("Quadratic Frobenius let %v pseudoprimes through", errors["Quadratic Frobenius"])
+	}
+	if errors["Baillie-PSW"] != 0 {
+		t.Errorf("Baillie-PSW let %v pseudoprimes through", errors["Baillie-PSW"])
+	}
+
+	var buf bytes.Buffer
+	if err := rsa.WritePrimalityTable(&buf, reports); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 6 {
+		t.Errorf("table has %v lines:\n%v", len(lines), buf.String())
+	}
+}
+
+func TestQuadraticFrobenius(t *testing.T) {
+	one, minusOne := big.NewInt(1), big.NewInt(-1)
+	for _, n := range []int64{7, 13, 17, 23, 97, 1000003} {
+		if pass, err := rsa.QuadraticFrobeniusTest(big.NewInt(n), one, minusOne); !pass || err != nil {
+			t.Errorf("prime %v rejected by x^2 - x - 1 (%v)", n, err)
+		}
+	}
+	// (5/n) = 1 leaves x^2 - x - 1 reducible, unusable for n.
+	if _, err := rsa.QuadraticFrobeniusTest(big.NewInt(11), one, minusOne); err == nil {
+		t.Error("expected an error for (Δ/n) = 1")
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, n := range rsa.KnownFrobeniusPseudoprimes {
+		b := big.NewInt(n)
+		if pass, err := rsa.QuadraticFrobeniusTest(b, one, minusOne); !pass || err != nil {
+			t.Errorf("pseudoprime %v rejected by x^2 - x - 1 (%v)", n, err)
+		}
+		if b.ProbablyPrime(20) || rsa.QuadraticFrobenius(b, 3, rnd) {
+			t.Errorf("pseudoprime %v accepted with random polynomials", n)
+		}
+	}
+
+	for _, n := range []int64{2, 3, 5, 97, 7919, 2147483647} {
+		if !rsa.QuadraticFrobenius(big.NewInt(n), 5, rnd) {
+			t.Errorf("prime %v rejected", n)
+		}
+	}
+	for _, n := range []int64{0, 1, 4, 9, 49, 91, 561, 1000001} {
+		if rsa.QuadraticFrobenius(big.NewInt(n), 5, rnd) {
+			t.Errorf("composite %v accepted", n)
+		}
+	}
+}
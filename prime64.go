@@ -0,0 +1,70 @@
+package rsa
+
+import "math/bits"
+
+// prime64Witnesses are Jim Sinclair's 7 Miller-Rabin bases, which no
+// composite below 2^64 passes all of.
+// https://miller-rabin.appspot.com/
+var prime64Witnesses = [...]uint64{2, 325, 9375, 28178, 450775, 9780504, 1795265022}
+
+// IsPrime64 reports whether n is prime, deterministically: it divides by a
+// few small primes and runs Miller-Rabin with prime64Witnesses on machine
+// words, far cheaper than big.Int.ProbablyPrime for factors and demo
+// parameters that fit 64 bits.
+// https://en.wikipedia.org/wiki/Miller%E2%80%93Rabin_primality_test#Testing_against_small_sets_of_bases
+func IsPrime64(n uint64) bool {
+
+	if n < 2 {
+		return false
+	}
+	for _, p := range [...]uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+	if n < 41*41 {
+		return true
+	}
+
+	s := uint(bits.TrailingZeros64(n - 1))
+	t := (n - 1) >> s
+witnesses:
+	for _, a := range prime64Witnesses {
+		a %= n
+		if a == 0 {
+			continue // a multiple of n says nothing
+		}
+		x := expMod64u(a, t, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		for range s - 1 {
+			x = mulMod64(x, x, n)
+			if x == n-1 {
+				continue witnesses
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// mulMod64 returns x*y mod n through the 128 bit product, for x, y < n.
+func mulMod64(x, y, n uint64) uint64 {
+
+	hi, lo := bits.Mul64(x, y)
+	return bits.Rem64(hi, lo, n)
+}
+
+// expMod64u returns base^exp mod n by square and multiply, base < n.
+func expMod64u(base, exp, n uint64) uint64 {
+
+	result := uint64(1)
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			result = mulMod64(result, base, n)
+		}
+		base = mulMod64(base, base, n)
+	}
+	return result
+}
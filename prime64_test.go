@@ -0,0 +1,35 @@
+package rsa_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestIsPrime64(t *testing.T) {
+	for n := uint64(0); n < 20000; n++ {
+		if got, expected := rsa.IsPrime64(n), new(big.Int).SetUint64(n).ProbablyPrime(20); got != expected {
+			t.Fatalf("IsPrime64(%v) = %v, expected %v", n, got, expected)
+		}
+	}
+	for _, n := range rsa.KnownPseudoprimes {
+		if rsa.IsPrime64(uint64(n)) {
+			t.Errorf("IsPrime64(%v) = true for a pseudoprime", n)
+		}
+	}
+	for _, tc := range []struct {
+		n     uint64
+		prime bool
+	}{
+		{3825123056546413051, false}, // strong pseudoprime to the prime bases 2 to 23
+		{18446744073709551557, true}, // the largest prime below 2^64
+		{18446744073709551615, false},
+		{4294967291 * 4294967279, false},
+		{1<<61 - 1, true},
+	} {
+		if got := rsa.IsPrime64(tc.n); got != tc.prime {
+			t.Errorf("IsPrime64(%v) = %v, expected %v", tc.n, got, tc.prime)
+		}
+	}
+}
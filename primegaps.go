@@ -0,0 +1,165 @@
+package rsa
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// primeRounds is the number of Miller-Rabin rounds, on top of the
+// Baillie-PSW test ProbablyPrime always runs, of the prime walks below.
+const primeRounds = 20
+
+// NextPrime sets z to the smallest prime greater than n and returns z.
+func NextPrime(z, n *big.Int) *big.Int {
+
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return z.SetInt64(2)
+	}
+	z.Add(n, big.NewInt(1))
+	if z.Bit(0) == 0 && z.Cmp(big.NewInt(2)) != 0 {
+		z.Add(z, big.NewInt(1))
+	}
+	for !z.ProbablyPrime(primeRounds) {
+		z.Add(z, big.NewInt(2))
+	}
+	return z
+}
+
+// PrevPrime sets z to the largest prime less than n > 2 and returns z.
+func PrevPrime(z, n *big.Int) (*big.Int, error) {
+
+	if n.Cmp(big.NewInt(2)) <= 0 {
+		return nil, fmt.Errorf("PrevPrime: there is no prime below %v", n)
+	}
+	if n.Cmp(big.NewInt(3)) == 0 {
+		return z.SetInt64(2), nil
+	}
+	z.Sub(n, big.NewInt(1))
+	if z.Bit(0) == 0 {
+		z.Sub(z, big.NewInt(1))
+	}
+	for !z.ProbablyPrime(primeRounds) {
+		z.Sub(z, big.NewInt(2))
+	}
+	return z, nil
+}
+
+// PrimePi returns π(x), the number of primes up to x, by sieving.
+// https://en.wikipedia.org/wiki/Prime-counting_function
+func PrimePi(x int64) int64 {
+
+	return int64(len(smallPrimes(x)))
+}
+
+// PrimePiEstimate returns the prime number theorem's estimates of π(x),
+// x / ln x, which undercounts by a factor approaching 1 slowly, and the
+// logarithmic integral li(x), accurate to about sqrt(x) ln x.
+// https://en.wikipedia.org/wiki/Prime_number_theorem
+func PrimePiEstimate(x float64) (xOverLogX, li float64) {
+
+	if x < 2 {
+		return 0, 0
+	}
+	return x / math.Log(x), logIntegral(x)
+}
+
+// PrimeProbability returns the chance that a random bits bit number is
+// prime, about 1 / ln(2^bits) by the prime number theorem: 1 in 355 for
+// 512 bits. It is twice that for odd candidates, so random prime
+// generation expects to test only a few hundred of them, which is why
+// it is fast.
+func PrimeProbability(bits int) float64 {
+
+	return 1 / (float64(bits) * math.Ln2)
+}
+
+// PrimeGapStats summarizes the gaps between consecutive primes in a range.
+type PrimeGapStats struct {
+	// Primes counts the primes found in the range.
+	Primes int
+	// MinGap and MaxGap are the extreme gaps, MaxGapStart the prime
+	// opening the largest one.
+	MinGap, MaxGap int64
+	MaxGapStart    *big.Int
+	// MeanGap is the average gap, to compare with ExpectedGap, the
+	// ln of the middle of the range the prime number theorem predicts.
+	MeanGap, ExpectedGap float64
+}
+
+// PrimeGaps walks the primes in [from, to] and reports their gaps.
+// The range should hold at least 2 primes; a range of width w costs
+// about w / ln(to) primality tests.
+func PrimeGaps(from, to *big.Int) (PrimeGapStats, error) {
+
+	var stats PrimeGapStats
+	prev := NextPrime(new(big.Int), new(big.Int).Sub(from, big.NewInt(1)))
+	if prev.Cmp(to) > 0 {
+		return stats, fmt.Errorf("PrimeGaps: no prime in [%v, %v]", from, to)
+	}
+	first := new(big.Int).Set(prev)
+	stats.Primes = 1
+	gap := new(big.Int)
+
+	for next := NextPrime(new(big.Int), prev); next.Cmp(to) <= 0; NextPrime(next, next) {
+		g := gap.Sub(next, prev).Int64()
+		if stats.Primes == 1 || g < stats.MinGap {
+			stats.MinGap = g
+		}
+		if g > stats.MaxGap {
+			stats.MaxGap = g
+			stats.MaxGapStart = new(big.Int).Set(prev)
+		}
+		stats.Primes++
+		prev.Set(next)
+	}
+	if stats.Primes < 2 {
+		return stats, fmt.Errorf("PrimeGaps: only 1 prime in [%v, %v]", from, to)
+	}
+
+	span, _ := new(big.Float).SetInt(gap.Sub(prev, first)).Float64()
+	stats.MeanGap = span / float64(stats.Primes-1)
+	middle := new(big.Int).Add(from, to)
+	stats.ExpectedGap = bigLog(middle.Rsh(middle, 1))
+	return stats, nil
+}
+
+// bigLog returns the natural logarithm of x > 0, also beyond float64.
+func bigLog(x *big.Int) float64 {
+
+	// x = mantissa * 2^shift with the mantissa held exactly in 64 bits.
+	shift := max(x.BitLen()-64, 0)
+	mantissa, _ := new(big.Float).SetInt(new(big.Int).Rsh(x, uint(shift))).Float64()
+	return math.Log(mantissa) + float64(shift)*math.Ln2
+}
+
+// logIntegral is li(x) for x >= 2, by Ramanujan's series while its terms
+// fit a float64 and by the asymptotic expansion
+// x / ln x * (1 + 1/ln x + 2/ln^2 x + 6/ln^3 x) beyond.
+// https://en.wikipedia.org/wiki/Logarithmic_integral_function#Series_representation
+func logIntegral(x float64) float64 {
+
+	lnX := math.Log(x)
+	if x > 1e100 {
+		return x / lnX * (1 + 1/lnX + 2/(lnX*lnX) + 6/(lnX*lnX*lnX))
+	}
+
+	const eulerGamma = 0.5772156649015329
+	var sum, inner float64
+	term := 1.0 // (ln x)^n / (n! 2^(n-1)), sign included
+	for n := 1; n < 1000; n++ {
+		term *= lnX / float64(n)
+		if n > 1 {
+			term /= -2
+		}
+		if (n-1)%2 == 0 {
+			inner += 1 / float64(n)
+		}
+		next := sum + term*inner
+		if next == sum {
+			break
+		}
+		sum = next
+	}
+	return eulerGamma + math.Log(lnX) + math.Sqrt(x)*sum
+}
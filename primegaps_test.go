@@ -0,0 +1,70 @@
+package rsa_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestNextPrevPrime(t *testing.T) {
+	for _, tc := range []struct{ n, next, prev int64 }{
+		{3, 5, 2}, {13, 17, 11}, {14, 17, 13}, {90, 97, 89}, {7920, 7927, 7919},
+	} {
+		if got := rsa.NextPrime(new(big.Int), big.NewInt(tc.n)); got.Int64() != tc.next {
+			t.Errorf("NextPrime(%v) = %v, expected %v", tc.n, got, tc.next)
+		}
+		if got, err := rsa.PrevPrime(new(big.Int), big.NewInt(tc.n)); err != nil || got.Int64() != tc.prev {
+			t.Errorf("PrevPrime(%v) = %v (%v), expected %v", tc.n, got, err, tc.prev)
+		}
+	}
+	if got := rsa.NextPrime(new(big.Int), big.NewInt(-5)); got.Int64() != 2 {
+		t.Errorf("NextPrime(-5) = %v, expected 2", got)
+	}
+	if _, err := rsa.PrevPrime(new(big.Int), big.NewInt(2)); err == nil {
+		t.Error("expected an error for the prime below 2")
+	}
+}
+
+func TestPrimePi(t *testing.T) {
+	for x, want := range map[int64]int64{1: 0, 2: 1, 100: 25, 1000000: 78498} {
+		if got := rsa.PrimePi(x); got != want {
+			t.Errorf("π(%v) = %v, expected %v", x, got, want)
+		}
+	}
+
+	// li(10^6) = 78627.549..., li(10^9) = 50849234.957...
+	for x, want := range map[float64]float64{1e6: 78627.549, 1e9: 50849234.957} {
+		xOverLogX, li := rsa.PrimePiEstimate(x)
+		if math.Abs(li-want) > 0.01 || xOverLogX > li {
+			t.Errorf("estimates of π(%v) = %v, %v, expected li = %v", x, xOverLogX, li, want)
+		}
+	}
+	if p := rsa.PrimeProbability(512); math.Abs(1/p-354.9) > 0.1 {
+		t.Errorf("1 in %v 512 bit numbers is prime, expected 1 in 354.9", 1/p)
+	}
+}
+
+func TestPrimeGaps(t *testing.T) {
+	stats, err := rsa.PrimeGaps(big.NewInt(1), big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Primes != 25 || stats.MinGap != 1 || stats.MaxGap != 8 || stats.MaxGapStart.Int64() != 89 {
+		t.Errorf("gaps below 100: %+v", stats)
+	}
+
+	// Near 2^64 the mean gap is close to ln(2^64) = 44.4.
+	from := new(big.Int).Lsh(big.NewInt(1), 64)
+	stats, err = rsa.PrimeGaps(from, new(big.Int).Add(from, big.NewInt(20000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(stats.ExpectedGap-44.36) > 0.01 || math.Abs(stats.MeanGap-stats.ExpectedGap) > 10 {
+		t.Errorf("gaps above 2^64: %+v", stats)
+	}
+	if _, err := rsa.PrimeGaps(big.NewInt(24), big.NewInt(28)); err == nil {
+		t.Error("expected an error for a range without primes")
+	}
+}
@@ -0,0 +1,180 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Implementation selects the deliberately naive or the hardened variant of
+// the operations of Primitives, so a lesson can run the same code, and the
+// same attack, against both and compare.
+type Implementation int
+
+const (
+	// Naive implementations follow the textbook and its classic mistakes.
+	Naive Implementation = iota
+	// Hardened implementations fix them as far as math/big allows.
+	Hardened
+)
+
+// String names the implementation.
+func (impl Implementation) String() string {
+
+	switch impl {
+	case Naive:
+		return "naive"
+	case Hardened:
+		return "hardened"
+	}
+	return fmt.Sprintf("Implementation(%d)", int(impl))
+}
+
+// Primitives are operations whose naive implementations are classic
+// sources of RSA vulnerabilities.
+type Primitives interface {
+	// ModExp sets z to base^exp mod modulus and returns z.
+	ModExp(z, base, exp, modulus *big.Int) *big.Int
+	// ModInverse sets z to a^-1 mod n and returns z.
+	ModInverse(z, a, n *big.Int) (*big.Int, error)
+	// UnpadPKCS1v15 returns the message of the PKCS#1 v1.5 encryption block
+	// 0x00 0x02 PS 0x00 M, PS at least 8 nonzero bytes.
+	UnpadPKCS1v15(em []byte) ([]byte, error)
+}
+
+// NewPrimitives returns the primitives of the implementation impl:
+//   - ModExp is ExpSquareMultiply, whose time follows the exponent's bits,
+//     or ExpConstantTime;
+//   - the naive ModInverse returns the raw Bézout coefficient, which may be
+//     negative, and a wrong result instead of an error when a has no
+//     inverse, while the hardened one reduces a, returns a result in
+//     [0, n) and fails for gcd(a, n) != 1;
+//   - the naive UnpadPKCS1v15 stops at the first defect and tells which it
+//     was, a padding oracle by its errors and its timing as Bleichenbacher
+//     exploited, while the hardened one scans the whole block without
+//     branching on its bytes and fails with a single error.
+func NewPrimitives(impl Implementation) Primitives {
+
+	if impl == Hardened {
+		return hardenedPrimitives{}
+	}
+	return naivePrimitives{}
+}
+
+// pkcs1v15MinPadding is the least number of random bytes in PS.
+const pkcs1v15MinPadding = 8
+
+// PadPKCS1v15 returns the k byte PKCS#1 v1.5 encryption block of msg with
+// nonzero random padding from random, crypto/rand.Reader if nil.
+// https://www.rfc-editor.org/rfc/rfc8017#section-7.2.1
+func PadPKCS1v15(random io.Reader, msg []byte, k int) ([]byte, error) {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	if len(msg) > k-3-pkcs1v15MinPadding {
+		return nil, fmt.Errorf("PadPKCS1v15: %v byte message too long for a %v byte block", len(msg), k)
+	}
+	em := make([]byte, k)
+	em[1] = 2
+	ps := em[2 : k-len(msg)-1]
+	if _, err := io.ReadFull(random, ps); err != nil {
+		return nil, fmt.Errorf("PadPKCS1v15: %v", err)
+	}
+	for i := range ps {
+		for ps[i] == 0 {
+			if _, err := io.ReadFull(random, ps[i:i+1]); err != nil {
+				return nil, fmt.Errorf("PadPKCS1v15: %v", err)
+			}
+		}
+	}
+	copy(em[k-len(msg):], msg)
+	return em, nil
+}
+
+// naivePrimitives are the Naive Primitives.
+type naivePrimitives struct{}
+
+func (naivePrimitives) ModExp(z, base, exp, modulus *big.Int) *big.Int {
+
+	return ModExp(z, base, exp, modulus, ExpSquareMultiply)
+}
+
+func (naivePrimitives) ModInverse(z, a, n *big.Int) (*big.Int, error) {
+
+	x := new(big.Int)
+	new(big.Int).GCD(x, nil, a, n)
+	return z.Set(x), nil
+}
+
+func (naivePrimitives) UnpadPKCS1v15(em []byte) ([]byte, error) {
+
+	if len(em) < 3+pkcs1v15MinPadding {
+		return nil, fmt.Errorf("UnpadPKCS1v15: block too short")
+	}
+	if em[0] != 0 {
+		return nil, fmt.Errorf("UnpadPKCS1v15: first byte is %#x, not 0", em[0])
+	}
+	if em[1] != 2 {
+		return nil, fmt.Errorf("UnpadPKCS1v15: block type is %v, not 2", em[1])
+	}
+	for i := 2; i < len(em); i++ {
+		if em[i] != 0 {
+			continue
+		}
+		if i-2 < pkcs1v15MinPadding {
+			return nil, fmt.Errorf("UnpadPKCS1v15: padding of %v bytes, less than %v", i-2, pkcs1v15MinPadding)
+		}
+		return em[i+1:], nil
+	}
+	return nil, fmt.Errorf("UnpadPKCS1v15: no 0 byte ends the padding")
+}
+
+// hardenedPrimitives are the Hardened Primitives.
+type hardenedPrimitives struct{}
+
+// errPKCS1v15 is the one error of the hardened UnpadPKCS1v15.
+var errPKCS1v15 = errors.New("UnpadPKCS1v15: decryption error")
+
+func (hardenedPrimitives) ModExp(z, base, exp, modulus *big.Int) *big.Int {
+
+	return ModExp(z, base, exp, modulus, ExpConstantTime)
+}
+
+func (hardenedPrimitives) ModInverse(z, a, n *big.Int) (*big.Int, error) {
+
+	if n.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("ModInverse: modulus %v must be > 1", n)
+	}
+	reduced := ModEuclid(new(big.Int), a, n)
+	if z.ModInverse(reduced, n) == nil {
+		return nil, fmt.Errorf("ModInverse: %v has no inverse modulo %v", a, n)
+	}
+	return z, nil
+}
+
+// UnpadPKCS1v15 follows crypto/rsa: every byte is examined and the checks
+// are combined with masks, so neither the time nor the error depends on
+// where the block is malformed. The block length is public.
+func (hardenedPrimitives) UnpadPKCS1v15(em []byte) ([]byte, error) {
+
+	if len(em) < 3+pkcs1v15MinPadding {
+		return nil, errPKCS1v15
+	}
+	valid := subtle.ConstantTimeByteEq(em[0], 0) & subtle.ConstantTimeByteEq(em[1], 2)
+	lookingForIndex, index := 1, 0
+	for i := 2; i < len(em); i++ {
+		isZero := subtle.ConstantTimeByteEq(em[i], 0)
+		index = subtle.ConstantTimeSelect(lookingForIndex&isZero, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(isZero, 0, lookingForIndex)
+	}
+	valid &= 1 - lookingForIndex
+	valid &= subtle.ConstantTimeLessOrEq(2+pkcs1v15MinPadding, index)
+	if valid == 0 {
+		return nil, errPKCS1v15
+	}
+	return em[index+1:], nil
+}
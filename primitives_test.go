@@ -0,0 +1,83 @@
+package rsa_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestPrimitivesAgree(t *testing.T) {
+	naive, hardened := rsa.NewPrimitives(rsa.Naive), rsa.NewPrimitives(rsa.Hardened)
+	n := big.NewInt(937513)
+	for _, x := range []int64{2, 3, 65537, 123456} {
+		b := big.NewInt(x)
+		if naive.ModExp(new(big.Int), b, b, n).Cmp(hardened.ModExp(new(big.Int), b, b, n)) != 0 {
+			t.Errorf("ModExp(%v, %v) differs", x, x)
+		}
+	}
+
+	msg := []byte("hi")
+	em, err := rsa.PadPKCS1v15(nil, msg, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []rsa.Primitives{naive, hardened} {
+		if got, err := p.UnpadPKCS1v15(em); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("unpadded %q (%v), expected %q", got, err, msg)
+		}
+	}
+	if _, err := rsa.PadPKCS1v15(nil, make([]byte, 6), 16); err == nil {
+		t.Error("expected an error for a message leaving less than 8 padding bytes")
+	}
+}
+
+func TestPrimitivesDiffer(t *testing.T) {
+	naive, hardened := rsa.NewPrimitives(rsa.Naive), rsa.NewPrimitives(rsa.Hardened)
+
+	// 3 * 7 = 21 = 1 mod 10, but Euclid finds the coefficient -3.
+	n := big.NewInt(10)
+	if got, _ := naive.ModInverse(new(big.Int), big.NewInt(3), n); got.Int64() != -3 {
+		t.Errorf("naive inverse of 3 mod 10 is %v, expected the raw -3", got)
+	}
+	if got, err := hardened.ModInverse(new(big.Int), big.NewInt(3), n); err != nil || got.Int64() != 7 {
+		t.Errorf("hardened inverse of 3 mod 10 is %v (%v), expected 7", got, err)
+	}
+	if _, err := naive.ModInverse(new(big.Int), big.NewInt(4), n); err != nil {
+		t.Errorf("naive inverse of 4 mod 10 failed (%v), expected a silently wrong result", err)
+	}
+	if _, err := hardened.ModInverse(new(big.Int), big.NewInt(4), n); err == nil {
+		t.Error("hardened inverse of 4 mod 10 succeeded")
+	}
+
+	// Every defect gets its own naive error, and the same hardened one.
+	good, _ := rsa.PadPKCS1v15(nil, []byte("hi"), 16)
+	defects := map[string][]byte{}
+	for name, defect := range map[string]func(em []byte){
+		"first byte":   func(em []byte) { em[0] = 1 },
+		"block type":   func(em []byte) { em[1] = 1 },
+		"short PS":     func(em []byte) { em[5] = 0 },
+		"no separator": func(em []byte) { em[13] = 0xff },
+	} {
+		em := bytes.Clone(good)
+		defect(em)
+		defects[name] = em
+	}
+	naiveErrors, hardenedErrors := map[string]bool{}, map[string]bool{}
+	for name, em := range defects {
+		_, err := naive.UnpadPKCS1v15(em)
+		if err == nil {
+			t.Fatalf("naive: %v defect accepted", name)
+		}
+		naiveErrors[err.Error()] = true
+		_, err = hardened.UnpadPKCS1v15(em)
+		if err == nil {
+			t.Fatalf("hardened: %v defect accepted", name)
+		}
+		hardenedErrors[err.Error()] = true
+	}
+	if len(naiveErrors) != len(defects) || len(hardenedErrors) != 1 {
+		t.Errorf("%v distinct naive and %v hardened errors, expected %v and 1", len(naiveErrors), len(hardenedErrors), len(defects))
+	}
+}
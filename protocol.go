@@ -0,0 +1,203 @@
+package rsa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// ProtocolOptions selects how SignThenEncrypt and EncryptThenSign compose
+// the primitives. The zero value is the naive composition.
+type ProtocolOptions struct {
+	// BindIdentities names the other party inside the protected data, as
+	// Davis proposed: sign-then-encrypt signs the recipient's modulus with
+	// the message, encrypt-then-sign encrypts the sender's modulus with it.
+	// It defeats ForwardSurreptitiously and ClaimAuthorship respectively.
+	BindIdentities bool
+}
+
+// Envelope is a message sent between two key pairs: the message, for
+// sign-then-encrypt with its signature, encrypted with a key sealed by
+// RSA-KEM, and for encrypt-then-sign a signature over the ciphertext.
+type Envelope struct {
+	// KEM is the RSA-KEM ciphertext under the recipient's key.
+	KEM *big.Int
+	// Body is the plaintext XORed with the KDF2 keystream of the KEM key,
+	// a demo cipher that, lacking a MAC, is malleable.
+	Body []byte
+	// Signature is the sender's FDH signature of KEM and Body in
+	// encrypt-then-sign, nil in sign-then-encrypt.
+	Signature *big.Int
+}
+
+// SignThenEncrypt signs msg with the sender's key and encrypts the message
+// with its signature to recipient. The signature proves who wrote msg but,
+// unless opts binds identities, not to whom: see ForwardSurreptitiously.
+// https://www.usenix.org/legacy/publications/library/proceedings/usenix01/full_papers/davis/davis.pdf
+func SignThenEncrypt(random io.Reader, sender *PrivateKey, recipient *PublicKey, msg []byte, opts *ProtocolOptions) (*Envelope, error) {
+
+	sig, err := SignFDH(sender, signedData(recipient, msg, opts))
+	if err != nil {
+		return nil, fmt.Errorf("SignThenEncrypt: %v", err)
+	}
+	plain := append(sig.FillBytes(make([]byte, modulusLen(sender.N))), msg...)
+	env, err := sealEnvelope(random, recipient, plain)
+	if err != nil {
+		return nil, fmt.Errorf("SignThenEncrypt: %v", err)
+	}
+	return env, nil
+}
+
+// OpenSignThenEncrypt decrypts env with the recipient's key and returns
+// the message after checking the sender's signature on it.
+func OpenSignThenEncrypt(recipient *PrivateKey, sender *PublicKey, env *Envelope, opts *ProtocolOptions) ([]byte, error) {
+
+	plain, err := openEnvelope(recipient, env)
+	if err != nil {
+		return nil, fmt.Errorf("OpenSignThenEncrypt: %v", err)
+	}
+	sigLen := modulusLen(sender.N)
+	if len(plain) < sigLen {
+		return nil, fmt.Errorf("OpenSignThenEncrypt: body too short for a signature")
+	}
+	sig, msg := new(big.Int).SetBytes(plain[:sigLen]), plain[sigLen:]
+	if err := VerifyFDH(sender, signedData(&recipient.PublicKey, msg, opts), sig); err != nil {
+		return nil, fmt.Errorf("OpenSignThenEncrypt: %v", err)
+	}
+	return msg, nil
+}
+
+// EncryptThenSign encrypts msg to recipient and signs the ciphertext with
+// the sender's key. The signature proves who sent the ciphertext, not who
+// wrote the message, unless opts binds identities: see ClaimAuthorship.
+func EncryptThenSign(random io.Reader, sender *PrivateKey, recipient *PublicKey, msg []byte, opts *ProtocolOptions) (*Envelope, error) {
+
+	plain := msg
+	if opts != nil && opts.BindIdentities {
+		plain = append(sender.N.FillBytes(make([]byte, modulusLen(sender.N))), msg...)
+	}
+	env, err := sealEnvelope(random, recipient, plain)
+	if err != nil {
+		return nil, fmt.Errorf("EncryptThenSign: %v", err)
+	}
+	if env.Signature, err = SignFDH(sender, env.signedCiphertext()); err != nil {
+		return nil, fmt.Errorf("EncryptThenSign: %v", err)
+	}
+	return env, nil
+}
+
+// OpenEncryptThenSign checks the sender's signature on env and returns the
+// message decrypted with the recipient's key.
+func OpenEncryptThenSign(recipient *PrivateKey, sender *PublicKey, env *Envelope, opts *ProtocolOptions) ([]byte, error) {
+
+	if env.Signature == nil {
+		return nil, fmt.Errorf("OpenEncryptThenSign: envelope is not signed")
+	}
+	if err := VerifyFDH(sender, env.signedCiphertext(), env.Signature); err != nil {
+		return nil, fmt.Errorf("OpenEncryptThenSign: %v", err)
+	}
+	plain, err := openEnvelope(recipient, env)
+	if err != nil {
+		return nil, fmt.Errorf("OpenEncryptThenSign: %v", err)
+	}
+	if opts == nil || !opts.BindIdentities {
+		return plain, nil
+	}
+	idLen := modulusLen(sender.N)
+	if len(plain) < idLen || !bytes.Equal(plain[:idLen], sender.N.FillBytes(make([]byte, idLen))) {
+		return nil, fmt.Errorf("OpenEncryptThenSign: the message was not written by the signer")
+	}
+	return plain[idLen:], nil
+}
+
+// ForwardSurreptitiously is Bob's attack on sign-then-encrypt: he opens
+// an envelope Alice sent him and re-encrypts her signed message to Carol,
+// who, checking Alice's signature, believes Alice wrote to her. Alice's
+// "I love you" is thereby delivered to someone she never addressed.
+func ForwardSurreptitiously(random io.Reader, bob *PrivateKey, carol *PublicKey, env *Envelope) (*Envelope, error) {
+
+	plain, err := openEnvelope(bob, env)
+	if err != nil {
+		return nil, fmt.Errorf("ForwardSurreptitiously: %v", err)
+	}
+	forwarded, err := sealEnvelope(random, carol, plain)
+	if err != nil {
+		return nil, fmt.Errorf("ForwardSurreptitiously: %v", err)
+	}
+	return forwarded, nil
+}
+
+// ClaimAuthorship is Mallory's attack on encrypt-then-sign: he intercepts
+// Alice's envelope, replaces her signature with his own and passes it on.
+// The recipient credits Mallory with a message Mallory cannot even read,
+// say the answer to a contest.
+func ClaimAuthorship(mallory *PrivateKey, env *Envelope) (*Envelope, error) {
+
+	sig, err := SignFDH(mallory, env.signedCiphertext())
+	if err != nil {
+		return nil, fmt.Errorf("ClaimAuthorship: %v", err)
+	}
+	return &Envelope{KEM: env.KEM, Body: env.Body, Signature: sig}, nil
+}
+
+// signedCiphertext is the data encrypt-then-sign signs, KEM and Body each
+// preceded by its 4 byte length so that no other split of the bytes
+// between KEM and Body carries the same signature.
+func (env *Envelope) signedCiphertext() []byte {
+
+	kem := env.KEM.Bytes()
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(kem)))
+	data = append(data, kem...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(env.Body)))
+	return append(data, env.Body...)
+}
+
+// signedData is the data sign-then-encrypt signs, msg preceded by the
+// recipient's modulus if opts binds identities.
+func signedData(recipient *PublicKey, msg []byte, opts *ProtocolOptions) []byte {
+
+	if opts == nil || !opts.BindIdentities {
+		return msg
+	}
+	return append(recipient.N.FillBytes(make([]byte, modulusLen(recipient.N))), msg...)
+}
+
+// sealEnvelope encrypts plain to pub under a fresh RSA-KEM key.
+func sealEnvelope(random io.Reader, pub *PublicKey, plain []byte) (*Envelope, error) {
+
+	key, c, err := EncapsulateKEM(random, pub, len(plain))
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	return &Envelope{KEM: c, Body: xorBytes(key, plain)}, nil
+}
+
+// openEnvelope decrypts the Body of env with priv.
+func openEnvelope(priv *PrivateKey, env *Envelope) ([]byte, error) {
+
+	key, err := DecapsulateKEM(priv, env.KEM, len(env.Body))
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	return xorBytes(key, env.Body), nil
+}
+
+// xorBytes returns a XOR b for equally long a and b.
+func xorBytes(a, b []byte) []byte {
+
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
+
+// modulusLen is the byte length of n.
+func modulusLen(n *big.Int) int {
+
+	return (n.BitLen() + 7) / 8
+}
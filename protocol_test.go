@@ -0,0 +1,98 @@
+package rsa_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestProtocols(t *testing.T) {
+	alice, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("I love you")
+
+	for _, opts := range []*rsa.ProtocolOptions{nil, {BindIdentities: true}} {
+		env, err := rsa.SignThenEncrypt(nil, alice, &bob.PublicKey, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := rsa.OpenSignThenEncrypt(bob, &alice.PublicKey, env, opts); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("%+v: sign-then-encrypt opened to %q (%v)", opts, got, err)
+		}
+
+		env, err = rsa.EncryptThenSign(nil, alice, &bob.PublicKey, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := rsa.OpenEncryptThenSign(bob, &alice.PublicKey, env, opts); err != nil || !bytes.Equal(got, msg) {
+			t.Errorf("%+v: encrypt-then-sign opened to %q (%v)", opts, got, err)
+		}
+		if _, err := rsa.OpenEncryptThenSign(bob, &bob.PublicKey, env, opts); err == nil {
+			t.Errorf("%+v: expected the signature check to fail for the wrong sender", opts)
+		}
+		// Moving the first byte of Body to the end of KEM leaves their
+		// concatenation unchanged, but not what the signature covers.
+		shifted := &rsa.Envelope{
+			KEM:       new(big.Int).Add(new(big.Int).Lsh(env.KEM, 8), big.NewInt(int64(env.Body[0]))),
+			Body:      env.Body[1:],
+			Signature: env.Signature,
+		}
+		if _, err := rsa.OpenEncryptThenSign(bob, &alice.PublicKey, shifted, opts); err == nil {
+			t.Errorf("%+v: expected the signature check to fail for a byte moved from Body to KEM", opts)
+		}
+	}
+}
+
+func TestProtocolAttacks(t *testing.T) {
+	var keys [3]*rsa.PrivateKey
+	for i := range keys {
+		key, err := rsatest.RandomKeyPair(rand.Reader, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	alice, bob, carol := keys[0], keys[1], keys[2]
+	mallory := carol
+	msg := []byte("I love you")
+
+	for _, opts := range []*rsa.ProtocolOptions{nil, {BindIdentities: true}} {
+		bound := opts != nil
+
+		env, err := rsa.SignThenEncrypt(nil, alice, &bob.PublicKey, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forwarded, err := rsa.ForwardSurreptitiously(nil, bob, &carol.PublicKey, env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = rsa.OpenSignThenEncrypt(carol, &alice.PublicKey, forwarded, opts)
+		if (err == nil) == bound {
+			t.Errorf("bound %v: surreptitious forwarding accepted: %v", bound, err == nil)
+		}
+
+		env, err = rsa.EncryptThenSign(nil, alice, &bob.PublicKey, msg, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claimed, err := rsa.ClaimAuthorship(mallory, env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = rsa.OpenEncryptThenSign(bob, &mallory.PublicKey, claimed, opts)
+		if (err == nil) == bound {
+			t.Errorf("bound %v: claimed authorship accepted: %v", bound, err == nil)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package rsa
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"math/big"
+	"strings"
+)
+
+// GetRhoSequence records the x-sequence walked by Pollard's Rho
+// x = (x*x + 1) % n starting from x0, until a value repeats.
+// It returns the distinct values in visiting order and the index mu
+// at which the loop starts: seq[:mu] is the tail and seq[mu:] the cycle,
+// whose last value maps back onto seq[mu].
+// Walking modulo one of the prime factors found by GetPrimeFactors
+// draws the ρ whose collision the gcd test detects.
+// https://en.wikipedia.org/wiki/Pollard's_rho_algorithm
+func GetRhoSequence(x0, n int64) ([]int64, int) {
+
+	seen := make(map[int64]int)
+	seq := []int64{}
+
+	x := big.NewInt(x0)
+	one := big.NewInt(1)
+	nBig := big.NewInt(n)
+	x.Mod(x, nBig)
+
+	for {
+		if mu, ok := seen[x.Int64()]; ok {
+			return seq, mu
+		}
+		seen[x.Int64()] = len(seq)
+		seq = append(seq, x.Int64())
+
+		x.Mul(x, x)
+		x.Add(x, one)
+		x.Mod(x, nBig) // x = (x*x + 1) % n
+	}
+}
+
+// WriteRhoDOT emits a Graphviz DOT digraph of a sequence returned by
+// GetRhoSequence: the tail is drawn as a chain leading into the loop
+// and the collision point seq[mu], where tail and loop meet, is highlighted.
+// Render it with e.g. `dot -Tsvg rho.dot > rho.svg`.
+func WriteRhoDOT(w io.Writer, seq []int64, mu int) error {
+
+	if mu < 0 || mu >= len(seq) {
+		return fmt.Errorf("WriteRhoDOT: loop start %v is outside the sequence of length %v", mu, len(seq))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph rho {\n")
+	sb.WriteString("\tnode [shape=circle];\n")
+
+	for i, x := range seq {
+		switch {
+		case i == mu:
+			fmt.Fprintf(&sb, "\tn%d [label=\"%d\", style=filled, fillcolor=red, xlabel=\"collision\"];\n", i, x)
+		case i < mu:
+			fmt.Fprintf(&sb, "\tn%d [label=\"%d\", style=dashed];\n", i, x)
+		default:
+			fmt.Fprintf(&sb, "\tn%d [label=\"%d\"];\n", i, x)
+		}
+	}
+	for i := 1; i < len(seq); i++ {
+		fmt.Fprintf(&sb, "\tn%d -> n%d;\n", i-1, i)
+	}
+	// Closing edge of the loop back onto the collision point.
+	fmt.Fprintf(&sb, "\tn%d -> n%d [color=red];\n", len(seq)-1, mu)
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// RhoSequence yields the sequence Pollard's Rho walks to factor n,
+// x0 = 2 and x = (x*x + c) % n, as GetPrimeFactors does. It never ends on
+// its own: the caller breaks, e.g. once a value repeats. Every value is a
+// new big.Int the caller may keep.
+func RhoSequence(n, c *big.Int) iter.Seq[*big.Int] {
+
+	return func(yield func(*big.Int) bool) {
+
+		x := big.NewInt(2)
+		x.Mod(x, n)
+		for yield(new(big.Int).Set(x)) {
+			x.Mul(x, x)
+			x.Add(x, c)
+			x.Mod(x, n)
+		}
+	}
+}
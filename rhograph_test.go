@@ -0,0 +1,62 @@
+package rsa_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestGetRhoSequence(t *testing.T) {
+	// 937513 = 877 * 1069, walk modulo the factor 877.
+	var p int64 = 877
+
+	seq, mu := rsa.GetRhoSequence(2, p)
+	if mu < 0 || mu >= len(seq) {
+		t.Fatalf("loop start %v outside sequence of length %v", mu, len(seq))
+	}
+	last := seq[len(seq)-1]
+	if next := (last*last + 1) % p; next != seq[mu] {
+		t.Errorf("last value %v maps to %v, expected collision point %v", last, next, seq[mu])
+	}
+
+	var sb strings.Builder
+	if err := rsa.WriteRhoDOT(&sb, seq, mu); err != nil {
+		t.Fatal(err)
+	}
+	dot := sb.String()
+	if !strings.HasPrefix(dot, "digraph rho {") || !strings.Contains(dot, "fillcolor=red") {
+		t.Errorf("unexpected DOT output:\n%v", dot)
+	}
+}
+
+func TestRhoSequence(t *testing.T) {
+	n, c := big.NewInt(937513), big.NewInt(1)
+	want := []int64{2, 5, 26, 677, 458330}
+	i := 0
+	for x := range rsa.RhoSequence(n, c) {
+		if x.Int64() != want[i] {
+			t.Errorf("x%v = %v, expected %v", i, x, want[i])
+		}
+		if i++; i == len(want) {
+			break
+		}
+	}
+
+	// Floyd's cycle detection on the lazy sequence modulo the factor 877
+	// finds x_j = x_2j for some j between the tail length and the ρ length.
+	var values []*big.Int
+	j := 0
+	for x := range rsa.RhoSequence(big.NewInt(877), c) {
+		values = append(values, x)
+		if k := len(values); k > 1 && k%2 == 1 && values[k/2].Cmp(x) == 0 {
+			j = k / 2
+			break
+		}
+	}
+	seq, mu := rsa.GetRhoSequence(2, 877)
+	if j < mu || j > len(seq) {
+		t.Errorf("x%v = x%v, the ρ has tail %v and length %v", j, 2*j, mu, len(seq))
+	}
+}
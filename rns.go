@@ -0,0 +1,130 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// RNSBasis is a residue number system: pairwise coprime word size moduli
+// m_i, a number x in [0, M), M their product, being represented by its
+// residues x mod m_i. Additions and multiplications act on each residue
+// alone, without carries between words, which is why RSA hardware splits
+// its multiplications into independent channels; the CRT takes the
+// residues back to x. Results wrap modulo M, so a basis must be wide
+// enough for the largest intermediate value, twice the operand size for
+// one product.
+// https://en.wikipedia.org/wiki/Residue_number_system
+type RNSBasis struct {
+	moduli  []uint64
+	product *big.Int
+}
+
+// RNS is a number as its residues modulo the moduli of an RNSBasis.
+type RNS []uint64
+
+// NewRNSBasis returns the basis of the pairwise coprime moduli > 1.
+func NewRNSBasis(moduli []uint64) (*RNSBasis, error) {
+
+	if len(moduli) == 0 {
+		return nil, fmt.Errorf("NewRNSBasis: no moduli")
+	}
+	b := &RNSBasis{moduli: append([]uint64(nil), moduli...), product: big.NewInt(1)}
+	for i, m := range moduli {
+		if m < 2 {
+			return nil, fmt.Errorf("NewRNSBasis: modulus %v must be > 1", m)
+		}
+		for _, other := range moduli[:i] {
+			if g := binaryGCD64(m, other); g != 1 {
+				return nil, fmt.Errorf("NewRNSBasis: moduli %v and %v share the factor %v", other, m, g)
+			}
+		}
+		b.product.Mul(b.product, new(big.Int).SetUint64(m))
+	}
+	return b, nil
+}
+
+// NewPrimeRNSBasis returns a basis of the largest primes below 2^32 for
+// numbers of up to bits bits: M >= 2^bits. 32 bit moduli keep every
+// product within a machine word.
+func NewPrimeRNSBasis(bits int) (*RNSBasis, error) {
+
+	if bits < 1 {
+		return nil, fmt.Errorf("NewPrimeRNSBasis: need at least 1 bit, got %v", bits)
+	}
+	var moduli []uint64
+	for p, covered := uint64(1<<32-1), 0; covered < bits; p -= 2 {
+		if IsPrime64(p) {
+			moduli = append(moduli, p)
+			covered += 31 // every p is above 2^31
+		}
+	}
+	return NewRNSBasis(moduli)
+}
+
+// Moduli returns a copy of the moduli of the basis.
+func (b *RNSBasis) Moduli() []uint64 {
+
+	return append([]uint64(nil), b.moduli...)
+}
+
+// Product returns M, the product of the moduli.
+func (b *RNSBasis) Product() *big.Int {
+
+	return new(big.Int).Set(b.product)
+}
+
+// FromBig returns the residues of x in [0, M).
+func (b *RNSBasis) FromBig(x *big.Int) (RNS, error) {
+
+	if x.Sign() < 0 || x.Cmp(b.product) >= 0 {
+		return nil, fmt.Errorf("RNSBasis.FromBig: %v is out of range [0, M)", x)
+	}
+	r := make(RNS, len(b.moduli))
+	residue := new(big.Int)
+	for i, m := range b.moduli {
+		r[i] = residue.Mod(x, residue.SetUint64(m)).Uint64()
+	}
+	return r, nil
+}
+
+// ToBig reconstructs the x in [0, M) with residues r by the CRT.
+func (b *RNSBasis) ToBig(r RNS) (*big.Int, error) {
+
+	if len(r) != len(b.moduli) {
+		return nil, fmt.Errorf("RNSBasis.ToBig: %v residues for %v moduli", len(r), len(b.moduli))
+	}
+	residues := make([]*big.Int, len(r))
+	moduli := make([]*big.Int, len(r))
+	for i := range r {
+		residues[i] = new(big.Int).SetUint64(r[i])
+		moduli[i] = new(big.Int).SetUint64(b.moduli[i])
+	}
+	x, _, err := CRT(residues, moduli)
+	if err != nil {
+		return nil, fmt.Errorf("RNSBasis.ToBig: %v", err)
+	}
+	return x, nil
+}
+
+// Add sets z to x + y mod M residue by residue and returns z.
+func (b *RNSBasis) Add(z, x, y RNS) RNS {
+
+	for i, m := range b.moduli {
+		sum, carry := bits.Add64(x[i], y[i], 0)
+		if carry != 0 || sum >= m {
+			sum -= m
+		}
+		z[i] = sum
+	}
+	return z
+}
+
+// Mul sets z to x * y mod M residue by residue and returns z.
+func (b *RNSBasis) Mul(z, x, y RNS) RNS {
+
+	for i, m := range b.moduli {
+		z[i] = mulMod64(x[i], y[i], m)
+	}
+	return z
+}
@@ -0,0 +1,60 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestRNS(t *testing.T) {
+	basis, err := rsa.NewPrimeRNSBasis(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if basis.Product().BitLen() <= 1024 {
+		t.Fatalf("M has %v bits, expected over 1024", basis.Product().BitLen())
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), 512)
+	for range 20 {
+		a, _ := rand.Int(rand.Reader, limit)
+		b, _ := rand.Int(rand.Reader, limit)
+		ra, err := basis.FromBig(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rb, _ := basis.FromBig(b)
+
+		product, err := basis.ToBig(basis.Mul(make(rsa.RNS, len(ra)), ra, rb))
+		if expected := new(big.Int).Mul(a, b); err != nil || product.Cmp(expected) != 0 {
+			t.Errorf("%v * %v = %v (%v) in RNS, expected %v", a, b, product, err, expected)
+		}
+		sum, err := basis.ToBig(basis.Add(ra, ra, rb))
+		if expected := new(big.Int).Add(a, b); err != nil || sum.Cmp(expected) != 0 {
+			t.Errorf("%v + %v = %v (%v) in RNS, expected %v", a, b, sum, err, expected)
+		}
+	}
+
+	// Results wrap modulo M, near 2^64 moduli included.
+	small, err := rsa.NewRNSBasis([]uint64{1<<64 - 59, 1<<61 - 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := small.Product()
+	x := new(big.Int).Sub(m, big.NewInt(1))
+	rx, _ := small.FromBig(x)
+	if got, _ := small.ToBig(small.Add(make(rsa.RNS, 2), rx, rx)); got.Cmp(new(big.Int).Sub(m, big.NewInt(2))) != 0 {
+		t.Errorf("(M-1) + (M-1) = %v, expected M-2", got)
+	}
+	if got, _ := small.ToBig(small.Mul(make(rsa.RNS, 2), rx, rx)); got.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("(M-1)^2 = %v, expected 1", got)
+	}
+
+	if _, err := rsa.NewRNSBasis([]uint64{15, 21}); err == nil {
+		t.Error("accepted moduli sharing a factor")
+	}
+	if _, err := small.FromBig(m); err == nil {
+		t.Error("converted M itself")
+	}
+}
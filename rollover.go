@@ -0,0 +1,96 @@
+package rsa
+
+import (
+	"fmt"
+	"iter"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// reEncrypted is the outcome of re-encrypting one ciphertext.
+type reEncrypted struct {
+	c   *big.Int
+	err error
+}
+
+// ReEncrypt rotates the textbook RSA ciphertexts from oldPriv to newPub as
+// they stream in: each is decrypted with DecryptCRT and encrypted again
+// under newPub by one goroutine per CPU, and the results are yielded in
+// input order, with an error for a ciphertext out of range or a plaintext
+// too large for the new modulus. Breaking out of the loop stops the
+// workers and the reading of ciphertexts, of which at most a few per
+// worker are read ahead.
+// Plaintexts are wiped once re-encrypted.
+func ReEncrypt(ciphertexts iter.Seq[*big.Int], oldPriv *PrivateKey, newPub *PublicKey) iter.Seq2[*big.Int, error] {
+
+	return func(yield func(*big.Int, error) bool) {
+
+		workers := runtime.NumCPU()
+		type job struct {
+			c      *big.Int
+			result chan<- reEncrypted
+		}
+		jobs := make(chan job)
+		// Every ciphertext reserves a result slot in input order, so the
+		// workers may finish out of order while the output does not.
+		slots := make(chan chan reEncrypted, workers)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer close(done)
+
+		for range workers {
+			wg.Add(1)
+			go func() {
+
+				defer wg.Done()
+				for j := range jobs {
+					c, err := reEncrypt(j.c, oldPriv, newPub)
+					j.result <- reEncrypted{c: c, err: err}
+				}
+			}()
+		}
+		wg.Add(1)
+		go func() {
+
+			defer wg.Done()
+			defer close(slots)
+			defer close(jobs)
+			for c := range ciphertexts {
+				result := make(chan reEncrypted, 1)
+				select {
+				case slots <- result:
+				case <-done:
+					return
+				}
+				select {
+				case jobs <- job{c: c, result: result}:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for result := range slots {
+			r := <-result
+			if !yield(r.c, r.err) {
+				return
+			}
+		}
+	}
+}
+
+// reEncrypt moves one ciphertext from oldPriv to newPub.
+func reEncrypt(c *big.Int, oldPriv *PrivateKey, newPub *PublicKey) (*big.Int, error) {
+
+	m, err := DecryptCRT(oldPriv, c, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ReEncrypt: %v", err)
+	}
+	defer zeroizeInt(m)
+	if m.Cmp(newPub.N) >= 0 {
+		return nil, fmt.Errorf("ReEncrypt: plaintext does not fit the new modulus")
+	}
+	return new(big.Int).Exp(m, newPub.E, newPub.N), nil
+}
@@ -0,0 +1,61 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestReEncrypt(t *testing.T) {
+	oldPriv, err := rsa.GenerateKeyPair(rand.Reader, 512, big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPriv, err := rsa.GenerateKeyPair(rand.Reader, 512, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages, ciphertexts []*big.Int
+	for i := range 500 {
+		m := big.NewInt(int64(i) + 1000)
+		messages = append(messages, m)
+		ciphertexts = append(ciphertexts, new(big.Int).Exp(m, oldPriv.E, oldPriv.N))
+	}
+	ciphertexts[7] = new(big.Int).Set(oldPriv.N) // out of range
+
+	i := 0
+	for c, err := range rsa.ReEncrypt(slices.Values(ciphertexts), oldPriv, &newPriv.PublicKey) {
+		if i == 7 {
+			if err == nil {
+				t.Error("re-encrypted a ciphertext out of range")
+			}
+		} else if m := new(big.Int).Exp(c, newPriv.D, newPriv.N); err != nil || m.Cmp(messages[i]) != 0 {
+			t.Errorf("ciphertext %v: decrypted %v (%v) under the new key, expected %v", i, m, err, messages[i])
+		}
+		i++
+	}
+	if i != len(ciphertexts) {
+		t.Errorf("yielded %v results, expected %v", i, len(ciphertexts))
+	}
+
+	// Breaking early stops the stream.
+	read := 0
+	counted := func(yield func(*big.Int) bool) {
+		for _, c := range ciphertexts {
+			read++
+			if !yield(c) {
+				return
+			}
+		}
+	}
+	for range rsa.ReEncrypt(counted, oldPriv, &newPriv.PublicKey) {
+		break
+	}
+	if read == len(ciphertexts) {
+		t.Error("read the whole stream after the consumer stopped")
+	}
+}
@@ -0,0 +1,194 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// RootMod returns all e-th roots of c modulo n = p*q for distinct primes
+// p and q, sorted, or an error if c is not an e-th power modulo n.
+// For gcd(e, φ(n)) = 1 there is exactly one root, c^(e^-1 mod φ(n)), the
+// RSA decryption. Otherwise the roots modulo p are a root times the
+// gcd(e, p-1) e-th roots of unity, likewise modulo q, and every pair
+// combines by the CRT into a root modulo n: for Rabin's e = 2 these are
+// the 4 square roots. All of them are enumerated, so gcd(e, p-1) and
+// gcd(e, q-1) must be small.
+// https://en.wikipedia.org/wiki/Rabin_cryptosystem#Decryption
+func RootMod(c, e, p, q *big.Int) ([]*big.Int, error) {
+
+	if e.Sign() <= 0 {
+		return nil, fmt.Errorf("RootMod: e (%v) must be positive", e)
+	}
+	if p.Cmp(q) == 0 {
+		return nil, fmt.Errorf("RootMod: p and q must be distinct primes")
+	}
+	rootsP, err := rootsModPrime(c, e, p)
+	if err != nil {
+		return nil, fmt.Errorf("RootMod: %v", err)
+	}
+	rootsQ, err := rootsModPrime(c, e, q)
+	if err != nil {
+		return nil, fmt.Errorf("RootMod: %v", err)
+	}
+
+	roots := make([]*big.Int, 0, len(rootsP)*len(rootsQ))
+	for _, rp := range rootsP {
+		for _, rq := range rootsQ {
+			root, _, err := CRT([]*big.Int{rp, rq}, []*big.Int{p, q})
+			if err != nil {
+				return nil, fmt.Errorf("RootMod: %v", err)
+			}
+			roots = append(roots, root)
+		}
+	}
+	slices.SortFunc(roots, (*big.Int).Cmp)
+	return roots, nil
+}
+
+// rootsModPrime returns all x in [0, p) with x^e = c mod the prime p.
+// One root is taken prime factor r of e by prime factor, choosing among
+// the r-th roots one that still has the remaining root, then multiplied
+// by the gcd(e, p-1) e-th roots of unity.
+func rootsModPrime(c, e, p *big.Int) ([]*big.Int, error) {
+
+	a := ModEuclid(new(big.Int), c, p)
+	if a.Sign() == 0 {
+		return []*big.Int{a}, nil
+	}
+	m := new(big.Int).Sub(p, big.NewInt(1))
+	if !isPowerResidue(a, e, p) {
+		return nil, fmt.Errorf("%v is not an %v-th power modulo %v", c, e, p)
+	}
+
+	factors, err := Factorize(e)
+	if err != nil {
+		return nil, err
+	}
+	rest := new(big.Int).Set(e)
+	gcd := new(big.Int)
+	for _, factor := range factors {
+		r := factor.Prime
+		for k := 0; k < factor.Exp; k++ {
+			rest.Quo(rest, r)
+			if gcd.GCD(nil, nil, r, m).Cmp(big.NewInt(1)) == 0 {
+				// x -> x^r permutes Z_p*, its inverse is x -> x^(r^-1 mod p-1).
+				a.Exp(a, new(big.Int).ModInverse(r, m), p)
+				continue
+			}
+			root := rthRootModPrime(a, r, p)
+			unity := unityRoot(r, p)
+			for !isPowerResidue(root, rest, p) {
+				root.Mul(root, unity)
+				root.Mod(root, p)
+			}
+			a = root
+		}
+	}
+
+	g := new(big.Int).GCD(nil, nil, e, m)
+	unity := unityRoot(g, p)
+	roots := []*big.Int{a}
+	for x := new(big.Int).Mul(a, unity); x.Mod(x, p).Cmp(a) != 0; x.Mul(x, unity) {
+		roots = append(roots, new(big.Int).Set(x))
+	}
+	slices.SortFunc(roots, (*big.Int).Cmp)
+	return roots, nil
+}
+
+// isPowerResidue reports whether the nonzero a is an e-th power modulo the
+// prime p: a^((p-1)/gcd(e, p-1)) = 1.
+func isPowerResidue(a, e, p *big.Int) bool {
+
+	m := new(big.Int).Sub(p, big.NewInt(1))
+	g := new(big.Int).GCD(nil, nil, e, m)
+	return new(big.Int).Exp(a, m.Quo(m, g), p).Cmp(big.NewInt(1)) == 0
+}
+
+// rthRootModPrime returns an r-th root of a modulo the prime p, for a prime
+// r dividing p-1 and a an r-th power, by the generalization of
+// Tonelli-Shanks: with p-1 = r^s * t and r coprime to t, x = a^(r^-1 mod t)
+// is a root up to an error in the cyclic subgroup of order r^s, whose
+// discrete logarithm is found digit by digit as in Pohlig-Hellman, in
+// O(s*r) multiplications.
+// https://en.wikipedia.org/wiki/Tonelli%E2%80%93Shanks_algorithm
+func rthRootModPrime(a, r, p *big.Int) *big.Int {
+
+	m := new(big.Int).Sub(p, big.NewInt(1))
+	s := 0
+	t := new(big.Int).Set(m)
+	mod := new(big.Int)
+	for quo := new(big.Int); ; s++ {
+		quo.DivMod(t, r, mod)
+		if mod.Sign() != 0 {
+			break
+		}
+		t.Set(quo)
+	}
+
+	// G generates the subgroup of order r^s.
+	nonResidue := big.NewInt(2)
+	for isPowerResidue(nonResidue, r, p) {
+		nonResidue.Add(nonResidue, big.NewInt(1))
+	}
+	g := new(big.Int).Exp(nonResidue, t, p)
+
+	alpha := new(big.Int)
+	if t.Cmp(big.NewInt(1)) != 0 {
+		alpha.ModInverse(r, t)
+	}
+	x := new(big.Int).Exp(a, alpha, p)
+	// errorTerm = x^r / a lies in <G>, find E with G^E = errorTerm.
+	errorTerm := new(big.Int).Exp(x, r, p)
+	errorTerm.Mul(errorTerm, new(big.Int).ModInverse(a, p))
+	errorTerm.Mod(errorTerm, p)
+
+	rPow := func(k int) *big.Int {
+		return new(big.Int).Exp(r, big.NewInt(int64(k)), nil)
+	}
+	gamma := new(big.Int).Exp(g, rPow(s-1), p) // of order r
+	gInv := new(big.Int).ModInverse(g, p)
+	logE := new(big.Int)
+	h := new(big.Int)
+	for i := 0; i < s; i++ {
+		h.Exp(gInv, logE, p)
+		h.Mul(h, errorTerm)
+		h.Exp(h.Mod(h, p), rPow(s-1-i), p)
+		digit := new(big.Int)
+		for power := big.NewInt(1); power.Cmp(h) != 0; digit.Add(digit, big.NewInt(1)) {
+			power.Mul(power, gamma)
+			power.Mod(power, p)
+		}
+		logE.Add(logE, digit.Mul(digit, rPow(i)))
+	}
+
+	// As a is an r-th power, r divides E, and x * G^(-E/r) is a root.
+	return x.Mul(x, gInv.Exp(gInv, logE.Quo(logE, r), p)).Mod(x, p)
+}
+
+// unityRoot returns a primitive k-th root of unity modulo the prime p for
+// k dividing p-1, w^((p-1)/k) for the first w = 2, 3, ... for which no
+// power k/r with r a prime factor of k is 1.
+func unityRoot(k, p *big.Int) *big.Int {
+
+	if k.Cmp(big.NewInt(1)) == 0 {
+		return big.NewInt(1)
+	}
+	factors, _ := Factorize(k)
+	m := new(big.Int).Sub(p, big.NewInt(1))
+	exp := m.Quo(m, k)
+	check := new(big.Int)
+	for w := big.NewInt(2); ; w.Add(w, big.NewInt(1)) {
+		root := new(big.Int).Exp(w, exp, p)
+		primitive := true
+		for _, factor := range factors {
+			if check.Exp(root, new(big.Int).Quo(k, factor.Prime), p).Cmp(big.NewInt(1)) == 0 {
+				primitive = false
+				break
+			}
+		}
+		if primitive {
+			return root
+		}
+	}
+}
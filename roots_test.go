@@ -0,0 +1,57 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestRootModBruteForce(t *testing.T) {
+	for _, primes := range [][2]int64{{13, 19}, {17, 41}, {2, 7}} {
+		p, q := big.NewInt(primes[0]), big.NewInt(primes[1])
+		n := primes[0] * primes[1]
+		for _, e := range []int64{1, 2, 3, 4, 5, 8, 9, 10, 12} {
+			expected := map[int64][]*big.Int{}
+			for x := int64(0); x < n; x++ {
+				c := new(big.Int).Exp(big.NewInt(x), big.NewInt(e), big.NewInt(n)).Int64()
+				expected[c] = append(expected[c], big.NewInt(x))
+			}
+			for c := int64(0); c < n; c++ {
+				roots, err := rsa.RootMod(big.NewInt(c), big.NewInt(e), p, q)
+				if len(expected[c]) == 0 {
+					if err == nil {
+						t.Errorf("n = %v, e = %v: %v has no root, got %v", n, e, c, roots)
+					}
+					continue
+				}
+				if err != nil || !slices.EqualFunc(roots, expected[c], func(a, b *big.Int) bool { return a.Cmp(b) == 0 }) {
+					t.Errorf("n = %v, e = %v: roots of %v = %v (%v), expected %v", n, e, c, roots, err, expected[c])
+				}
+			}
+		}
+	}
+}
+
+func TestRootModLarge(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := rand.Int(rand.Reader, priv.N)
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+	roots, err := rsa.RootMod(c, priv.E, priv.P, priv.Q)
+	if err != nil || len(roots) != 1 || roots[0].Cmp(m) != 0 {
+		t.Errorf("RSA decryption by RootMod gave %v (%v), expected %v", roots, err, m)
+	}
+
+	// Rabin: the 4 square roots of m^2, m among them.
+	square := new(big.Int).Exp(m, big.NewInt(2), priv.N)
+	roots, err = rsa.RootMod(square, big.NewInt(2), priv.P, priv.Q)
+	if err != nil || len(roots) != 4 || !slices.ContainsFunc(roots, func(r *big.Int) bool { return r.Cmp(m) == 0 }) {
+		t.Errorf("square roots of %v = %v (%v), expected 4 including %v", square, roots, err, m)
+	}
+}
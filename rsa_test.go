@@ -2,9 +2,13 @@
 package rsa_test
 
 import (
+	"crypto/rand"
 	"testing"
 	"fmt"
+	"math/big"
+	"strings"
 	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/dataset"
 )
 
 
@@ -27,3 +31,117 @@ func TestReverseRsaEnc(t *testing.T) {
 		fmt.Printf("Decrypted message matches original. Success breaking rsa encryption for public key n: %v e: %v", n, e)
 	}
 }
+
+// BenchmarkGetPrimeFactorsWithStats times the factoring alone, without
+// the printing of GetPrimeFactors.
+func BenchmarkGetPrimeFactorsWithStats(b *testing.B) {
+	// Only the dataset sizes the int64 argument can hold.
+	for _, bits := range []int{40, 60} {
+		s, err := dataset.Get(bits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprint(bits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rsa.GetPrimeFactorsWithStats(s.N.Int64())
+			}
+		})
+	}
+}
+
+func TestGetPrimeFactorsWithStats(t *testing.T) {
+	for _, bits := range []int{40, 60} {
+		s, err := dataset.Get(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, q, stats := rsa.GetPrimeFactorsWithStats(s.N.Int64())
+		if new(big.Int).Mul(p, q).Cmp(s.N) != 0 || p.Cmp(big.NewInt(1)) == 0 || q.Cmp(big.NewInt(1)) == 0 {
+			t.Errorf("%v bits: %v * %v is not a factorization of %v", bits, p, q, s.N)
+		}
+		if stats.Iterations == 0 || stats.GcdCalls != stats.Iterations || stats.CycleLength == 0 || stats.Duration <= 0 {
+			t.Errorf("%v bits: unexpected stats %+v", bits, stats)
+		}
+		if cost := stats.Cost(); cost.ModMuls != stats.Iterations || cost.Duration != stats.Duration {
+			t.Errorf("%v bits: cost %+v does not match stats %+v", bits, cost, stats)
+		}
+	}
+
+	// A prime has nothing to find, every run hits n and restarts.
+	_, _, stats := rsa.GetPrimeFactorsWithStats(1000003)
+	if stats.Restarts == 0 {
+		t.Errorf("expected restarts factoring a prime, got %+v", stats)
+	}
+}
+
+func TestEuclideanDivision(t *testing.T) {
+	for _, tc := range []struct{ a, b, q, r int64 }{
+		{7, 3, 2, 1},
+		{-7, 3, -3, 2},
+		{7, -3, -2, 1},
+		{-7, -3, 3, 2},
+		{-6, 3, -2, 0},
+	} {
+		a, b := big.NewInt(tc.a), big.NewInt(tc.b)
+		q := rsa.DivEuclid(new(big.Int), a, b)
+		r := rsa.ModEuclid(new(big.Int), a, b)
+		if q.Int64() != tc.q || r.Int64() != tc.r {
+			t.Errorf("%v / %v = %v rem %v, expected %v rem %v", tc.a, tc.b, q, r, tc.q, tc.r)
+		}
+		if got := rsa.EuclideanMod(tc.a, tc.b); got != tc.r {
+			t.Errorf("EuclideanMod(%v, %v) = %v, expected %v", tc.a, tc.b, got, tc.r)
+		}
+	}
+}
+
+func TestGetMultInverse(t *testing.T) {
+	var modulus int64 = 3120
+	for _, n := range []int64{17, -17, 7, 3119} {
+		inv, err := rsa.GetMultInverse(n, modulus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inv < 0 || inv >= modulus || rsa.EuclideanMod(n*inv, modulus) != 1 {
+			t.Errorf("GetMultInverse(%v, %v) = %v is not the canonical inverse", n, modulus, inv)
+		}
+	}
+	if _, err := rsa.GetMultInverse(12, modulus); err == nil {
+		t.Error("expected an error inverting a number sharing a factor with the modulus")
+	}
+}
+
+func TestDecryptWithPrivateMaterial(t *testing.T) {
+	// A modulus this size is out of DecryptCipher's reach, but not of
+	// whoever knows the private material.
+	priv, err := rsa.GenerateKeyPair(rand.Reader, 1024, big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := big.NewInt(1234567890)
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+	phi := new(big.Int).Mul(new(big.Int).Sub(priv.P, big.NewInt(1)), new(big.Int).Sub(priv.Q, big.NewInt(1)))
+
+	for name, decrypt := range map[string]func() (*big.Int, error){
+		"factors": func() (*big.Int, error) { return rsa.DecryptWithFactors(c, priv.P, priv.Q, priv.E) },
+		"phi":     func() (*big.Int, error) { return rsa.DecryptWithPhi(c, priv.N, priv.E, phi) },
+		"d":       func() (*big.Int, error) { return rsa.DecryptWithD(c, priv.N, priv.D) },
+	} {
+		if got, err := decrypt(); err != nil || got.Cmp(m) != 0 {
+			t.Errorf("with %v: decrypted %v (%v), expected %v", name, got, err, m)
+		}
+	}
+
+	if _, err := rsa.DecryptWithD(priv.N, priv.N, priv.D); err == nil {
+		t.Error("expected an error for a ciphertext not below n")
+	}
+	if _, err := rsa.DecryptWithPhi(c, priv.N, big.NewInt(2), phi); err == nil {
+		t.Error("expected an error for an e not invertible modulo φ(n)")
+	} else if strings.Contains(err.Error(), phi.String()) {
+		t.Errorf("the error reveals φ(n): %v", err)
+	}
+	if _, err := rsa.DecryptWithFactors(c, priv.P, priv.P, priv.E); err == nil {
+		t.Error("expected an error for equal factors")
+	} else if strings.Contains(err.Error(), priv.P.String()) {
+		t.Errorf("the error reveals p: %v", err)
+	}
+}
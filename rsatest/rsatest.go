@@ -0,0 +1,177 @@
+// Package rsatest provides random generators and invariant checks for
+// property-based testing and fuzzing of code built on package rsa.
+// Generators draw from the given io.Reader, e.g. crypto/rand.Reader,
+// and the checks return a descriptive error when an invariant breaks.
+package rsatest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/nethatix/rsa"
+)
+
+// DefaultE is the public exponent of the generated key pairs.
+var DefaultE = big.NewInt(65537)
+
+// RandomSemiprime returns n = p * q of about bits bits
+// with distinct random primes p and q of half that size.
+func RandomSemiprime(random io.Reader, bits int) (n, p, q *big.Int, err error) {
+
+	if bits < 4 {
+		return nil, nil, nil, fmt.Errorf("RandomSemiprime: %v bits is too small for 2 distinct primes", bits)
+	}
+
+	for {
+		if p, err = rsa.RandomPrime(random, bits-bits/2); err != nil {
+			return nil, nil, nil, err
+		}
+		if q, err = rsa.RandomPrime(random, bits/2); err != nil {
+			return nil, nil, nil, err
+		}
+		if p.Cmp(q) != 0 {
+			return new(big.Int).Mul(p, q), p, q, nil
+		}
+	}
+}
+
+// RandomCoprimePair returns 2 random positive numbers below 2^bits
+// whose greatest common divisor is 1.
+func RandomCoprimePair(random io.Reader, bits int) (a, b *big.Int, err error) {
+
+	if bits < 1 {
+		return nil, nil, fmt.Errorf("RandomCoprimePair: %v bits leaves no positive number", bits)
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	one := big.NewInt(1)
+
+	for {
+		if a, err = rand.Int(random, limit); err != nil {
+			return nil, nil, err
+		}
+		if b, err = rand.Int(random, limit); err != nil {
+			return nil, nil, err
+		}
+		if a.Sign() > 0 && b.Sign() > 0 && new(big.Int).GCD(nil, nil, a, b).Cmp(one) == 0 {
+			return a, b, nil
+		}
+	}
+}
+
+// RandomKeyPair returns a valid canonical key pair with a modulus of
+// about bits bits and public exponent DefaultE, redrawing the primes
+// until e is invertible.
+func RandomKeyPair(random io.Reader, bits int) (*rsa.PrivateKey, error) {
+
+	for {
+		_, p, q, err := RandomSemiprime(random, bits)
+		if err != nil {
+			return nil, err
+		}
+		if priv, err := rsa.NewPrivateKey(p, q, DefaultE); err == nil {
+			return priv, nil
+		}
+	}
+}
+
+// RandomInvalidKeyPair returns a key pair like RandomKeyPair whose private
+// exponent is off by one, so that CheckKeyPair and CheckEncDec fail on it.
+func RandomInvalidKeyPair(random io.Reader, bits int) (*rsa.PrivateKey, error) {
+
+	priv, err := RandomKeyPair(random, bits)
+	if err != nil {
+		return nil, err
+	}
+	priv.D.Add(priv.D, big.NewInt(1))
+	return priv, nil
+}
+
+// CheckEncDec verifies that decrypting the encryption of m gives m back,
+// both with d directly and through the CRT parameters.
+func CheckEncDec(priv *rsa.PrivateKey, m *big.Int) error {
+
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+	if dec := new(big.Int).Exp(c, priv.D, priv.N); dec.Cmp(m) != 0 {
+		return fmt.Errorf("CheckEncDec: decrypting %v gives %v, expected %v", c, dec, m)
+	}
+
+	// m = mq + q * (qInv * (mp - mq) mod p)
+	mp := new(big.Int).Exp(c, priv.Dp, priv.P)
+	mq := new(big.Int).Exp(c, priv.Dq, priv.Q)
+	h := new(big.Int).Sub(mp, mq)
+	h.Mul(h, priv.Qinv)
+	h.Mod(h, priv.P)
+	h.Mul(h, priv.Q)
+	h.Add(h, mq)
+	if h.Cmp(m) != 0 {
+		return fmt.Errorf("CheckEncDec: CRT decryption of %v gives %v, expected %v", c, h, m)
+	}
+	return nil
+}
+
+// CheckKeyPair verifies the relations between all the fields of a key:
+// n = p*q, e*d = 1 mod (p-1) and mod (q-1), the CRT exponents and
+// q*qInv = 1 mod p.
+func CheckKeyPair(priv *rsa.PrivateKey) error {
+
+	one := big.NewInt(1)
+	if new(big.Int).Mul(priv.P, priv.Q).Cmp(priv.N) != 0 {
+		return fmt.Errorf("CheckKeyPair: n is not p*q")
+	}
+
+	for _, prime := range []*big.Int{priv.P, priv.Q} {
+		primeMinus1 := new(big.Int).Sub(prime, one)
+		ed := new(big.Int).Mul(priv.E, priv.D)
+		if ed.Mod(ed, primeMinus1).Cmp(one) != 0 {
+			return fmt.Errorf("CheckKeyPair: e*d is not 1 mod %v", primeMinus1)
+		}
+	}
+
+	if new(big.Int).Mod(priv.D, new(big.Int).Sub(priv.P, one)).Cmp(priv.Dp) != 0 {
+		return fmt.Errorf("CheckKeyPair: Dp is not d mod (p-1)")
+	}
+	if new(big.Int).Mod(priv.D, new(big.Int).Sub(priv.Q, one)).Cmp(priv.Dq) != 0 {
+		return fmt.Errorf("CheckKeyPair: Dq is not d mod (q-1)")
+	}
+	qQinv := new(big.Int).Mul(priv.Q, priv.Qinv)
+	if qQinv.Mod(qQinv, priv.P).Cmp(one) != 0 {
+		return fmt.Errorf("CheckKeyPair: Qinv is not q^-1 mod p")
+	}
+	return nil
+}
+
+// CheckBezout verifies that the gcd and coefficients returned by
+// rsa.GetExtBinaryGCD satisfy a*x + b*y = gcd and that gcd divides both.
+func CheckBezout(a, b *big.Int) error {
+
+	x, y := new(big.Int), new(big.Int)
+	gcd, err := rsa.GetExtBinaryGCD(new(big.Int), x, y, a, b)
+	if err != nil {
+		return err
+	}
+
+	sum := new(big.Int).Mul(a, x)
+	sum.Add(sum, new(big.Int).Mul(b, y))
+	if sum.Cmp(gcd) != 0 {
+		return fmt.Errorf("CheckBezout: %v*%v + %v*%v = %v, not the gcd %v", a, x, b, y, sum, gcd)
+	}
+	if new(big.Int).Mod(a, gcd).Sign() != 0 || new(big.Int).Mod(b, gcd).Sign() != 0 {
+		return fmt.Errorf("CheckBezout: %v does not divide both %v and %v", gcd, a, b)
+	}
+	return nil
+}
+
+// CheckBezout64 is CheckBezout for rsa.GetExtEuclideanAlgorithm's int64s.
+func CheckBezout64(a, b int64) error {
+
+	gcd, x, y := rsa.GetExtEuclideanAlgorithm(a, b)
+	if a*x+b*y != gcd {
+		return fmt.Errorf("CheckBezout64: %v*%v + %v*%v is not the gcd %v", a, x, b, y, gcd)
+	}
+	if gcd != 0 && (a%gcd != 0 || b%gcd != 0) {
+		return fmt.Errorf("CheckBezout64: %v does not divide both %v and %v", gcd, a, b)
+	}
+	return nil
+}
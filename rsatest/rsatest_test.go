@@ -0,0 +1,78 @@
+package rsatest_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand/v2"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestGeneratorsSatisfyInvariants(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		priv, err := rsatest.RandomKeyPair(rand.Reader, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rsatest.CheckKeyPair(priv); err != nil {
+			t.Error(err)
+		}
+		m, err := rand.Int(rand.Reader, priv.N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rsatest.CheckEncDec(priv, m); err != nil {
+			t.Error(err)
+		}
+
+		a, b, err := rsatest.RandomCoprimePair(rand.Reader, 128)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rsatest.CheckBezout(a, b); err != nil {
+			t.Error(err)
+		}
+	}
+
+	invalid, err := rsatest.RandomInvalidKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsatest.CheckKeyPair(invalid) == nil || rsatest.CheckEncDec(invalid, big.NewInt(42)) == nil {
+		t.Error("expected the invalid key pair to break the invariants")
+	}
+}
+
+func TestGeneratorsReproducible(t *testing.T) {
+	var keys [2]*rsa.PrivateKey
+	for i := range keys {
+		priv, err := rsatest.RandomKeyPair(mathrand.NewChaCha8([32]byte{1}), 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = priv
+	}
+	if !keys[0].Equal(keys[1]) {
+		t.Error("the same seed generated different key pairs")
+	}
+
+	if _, _, err := rsatest.RandomCoprimePair(rand.Reader, 0); err == nil {
+		t.Error("expected an error for 0 bits")
+	}
+}
+
+func FuzzCheckBezout64(f *testing.F) {
+	f.Add(int64(240), int64(46))
+	f.Add(int64(638471), int64(936000))
+	f.Fuzz(func(t *testing.T, a, b int64) {
+		// Keep the coefficients' products within int64.
+		if a < 0 || b < 0 || a > 1<<30 || b > 1<<30 {
+			t.Skip()
+		}
+		if err := rsatest.CheckBezout64(a, b); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+)
+
+// Scenario scripts a classroom demo from key generation to verified
+// recovery: generate Recipients weak keys, encrypt Message to each, run
+// Attack on what an eavesdropper sees and check the result, so new demos
+// are a JSON file rather than Go code, e.g.
+//
+//	{"name": "Close primes", "attack": "fermat", "message": "hi",
+//	 "key": {"bits": 128, "e": 65537, "weakness": "close-primes", "gapBits": 16}}
+type Scenario struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Key         ScenarioKey `json:"key"`
+	// Message is encoded with Base256Alphabet.
+	Message string `json:"message"`
+	// Attack is one of ScenarioAttacks().
+	Attack string `json:"attack"`
+	// Format is how Run writes numbers, decimal by default.
+	Format NumberFormat `json:"format"`
+}
+
+// ScenarioKey describes the keys of a Scenario.
+type ScenarioKey struct {
+	Bits int   `json:"bits"`
+	E    int64 `json:"e"`
+	// Weakness is "" for a random key or "close-primes" for factors
+	// 2^(GapBits-1) to 2^GapBits apart.
+	Weakness string `json:"weakness,omitempty"`
+	GapBits  int    `json:"gapBits,omitempty"`
+	// Recipients is the number of keys the message goes to, 1 if 0.
+	Recipients int `json:"recipients,omitempty"`
+}
+
+// ScenarioResult is the outcome of Scenario.Run.
+type ScenarioResult struct {
+	// Recovered is the message the attack found.
+	Recovered string
+	Cost      Cost
+}
+
+// scenarioAttack recovers the plaintext of the scenario's ciphertexts.
+// priv is for the oracles a scenario plays, not for the attack itself.
+type scenarioAttack func(w io.Writer, cts []Ciphertext, priv []*PrivateKey, format NumberFormat) (*big.Int, Cost, error)
+
+// scenarioAttacks are the attacks a Scenario names.
+var scenarioAttacks = map[string]scenarioAttack{
+	"fermat": func(w io.Writer, cts []Ciphertext, _ []*PrivateKey, format NumberFormat) (*big.Int, Cost, error) {
+
+		p, q, cost, err := FermatFactorization(cts[0].Key.N, 1<<20)
+		if err != nil {
+			return nil, cost, err
+		}
+		fmt.Fprintf(w, "Fermat's method: n = %v * %v\n", format.Format(p), format.Format(q))
+		m, err := DecryptWithFactors(cts[0].C, p, q, cts[0].Key.E)
+		return m, cost, err
+	},
+	"rho": func(w io.Writer, cts []Ciphertext, _ []*PrivateKey, format NumberFormat) (*big.Int, Cost, error) {
+
+		meter := newCostMeter()
+		factors, err := Factorize(cts[0].Key.N)
+		if err != nil {
+			return nil, meter.done(), err
+		}
+		if len(factors) != 2 || factors[0].Exp != 1 || factors[1].Exp != 1 {
+			return nil, meter.done(), fmt.Errorf("n is not the product of 2 distinct primes")
+		}
+		p, q := factors[0].Prime, factors[1].Prime
+		fmt.Fprintf(w, "Pollard's rho: n = %v * %v\n", format.Format(p), format.Format(q))
+		m, err := DecryptWithFactors(cts[0].C, p, q, cts[0].Key.E)
+		return m, meter.done(), err
+	},
+	"hastad": func(w io.Writer, cts []Ciphertext, _ []*PrivateKey, _ NumberFormat) (*big.Int, Cost, error) {
+
+		fmt.Fprintf(w, "Håstad: combining %v ciphertexts with the CRT\n", len(cts))
+		return HastadBroadcast(cts)
+	},
+	"parity-oracle": func(w io.Writer, cts []Ciphertext, priv []*PrivateKey, _ NumberFormat) (*big.Int, Cost, error) {
+
+		m, cost, err := ParityAttack(&cts[0].Key, cts[0].C, NewParityOracle(priv[0]))
+		fmt.Fprintf(w, "parity oracle: %v queries\n", cost.OracleQueries)
+		return m, cost, err
+	},
+}
+
+// ScenarioAttacks returns the attack names a Scenario accepts, sorted.
+func ScenarioAttacks() []string {
+
+	names := make([]string, 0, len(scenarioAttacks))
+	for name := range scenarioAttacks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LoadScenario reads a Scenario from its JSON spec and validates it.
+func LoadScenario(r io.Reader) (*Scenario, error) {
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var s Scenario
+	if err := decoder.Decode(&s); err != nil {
+		return nil, fmt.Errorf("LoadScenario: %v", err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("LoadScenario: %v", err)
+	}
+	return &s, nil
+}
+
+// validate checks the spec before any key is generated.
+func (s *Scenario) validate() error {
+
+	if _, ok := scenarioAttacks[s.Attack]; !ok {
+		return fmt.Errorf("unknown attack %q, expected one of %v", s.Attack, ScenarioAttacks())
+	}
+	if s.Key.Bits < 16 {
+		return fmt.Errorf("keys of %v bits are too small", s.Key.Bits)
+	}
+	if s.Key.E < 3 || s.Key.E%2 == 0 {
+		return fmt.Errorf("e (%v) must be odd and at least 3", s.Key.E)
+	}
+	if s.Key.Recipients < 0 {
+		return fmt.Errorf("negative number of recipients")
+	}
+	switch s.Key.Weakness {
+	case "":
+	case "close-primes":
+		if s.Key.GapBits < 2 {
+			return fmt.Errorf("close primes need gapBits >= 2")
+		}
+	default:
+		return fmt.Errorf("unknown key weakness %q", s.Key.Weakness)
+	}
+	return nil
+}
+
+// Run plays the scenario, drawing keys from random or crypto/rand.Reader
+// if nil, and narrates every step to w. It fails if the attack fails or
+// recovers anything but the message.
+func (s *Scenario) Run(random io.Reader, w io.Writer) (ScenarioResult, error) {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	if w == nil {
+		w = io.Discard
+	}
+	if err := s.validate(); err != nil {
+		return ScenarioResult{}, fmt.Errorf("Scenario: %v", err)
+	}
+	fmt.Fprintf(w, "== %v\n", s.Name)
+	if s.Description != "" {
+		fmt.Fprintln(w, s.Description)
+	}
+
+	m, err := Encode(s.Message, Base256Alphabet)
+	if err != nil {
+		return ScenarioResult{}, fmt.Errorf("Scenario: %v", err)
+	}
+	recipients := max(s.Key.Recipients, 1)
+	keys := make([]*PrivateKey, recipients)
+	cts := make([]Ciphertext, recipients)
+	for i := range keys {
+		if keys[i], err = s.generateKey(random); err != nil {
+			return ScenarioResult{}, fmt.Errorf("Scenario: %v", err)
+		}
+		defer keys[i].Zeroize()
+		if m.Cmp(keys[i].N) >= 0 {
+			return ScenarioResult{}, fmt.Errorf("Scenario: message %q does not fit a %v bit modulus", s.Message, s.Key.Bits)
+		}
+		cts[i] = Ciphertext{Key: keys[i].PublicKey, C: new(big.Int).Exp(m, keys[i].E, keys[i].N)}
+		fmt.Fprintf(w, "key %v: n = %v, e = %v\n", i+1, s.Format.Format(keys[i].N), keys[i].E)
+		fmt.Fprintf(w, "encrypted %q: c = %v\n", s.Message, s.Format.Format(cts[i].C))
+	}
+
+	recovered, cost, err := scenarioAttacks[s.Attack](w, cts, keys, s.Format)
+	if err != nil {
+		fmt.Fprintf(w, "attack %v failed: %v\n", s.Attack, err)
+		return ScenarioResult{Cost: cost}, fmt.Errorf("Scenario: %v: %v", s.Attack, err)
+	}
+	text, err := Decode(recovered, Base256Alphabet)
+	if err != nil || recovered.Cmp(m) != 0 {
+		return ScenarioResult{Cost: cost}, fmt.Errorf("Scenario: %v recovered %v, not the message", s.Attack, s.Format.Format(recovered))
+	}
+	fmt.Fprintf(w, "recovered %q in %v\n", text, cost.Duration)
+	return ScenarioResult{Recovered: text, Cost: cost}, nil
+}
+
+// generateKey generates one key with the scenario's weakness.
+func (s *Scenario) generateKey(random io.Reader) (*PrivateKey, error) {
+
+	e := big.NewInt(s.Key.E)
+	if s.Key.Weakness != "close-primes" {
+		return GenerateKeyPair(random, s.Key.Bits, e)
+	}
+	var err error
+	// Retry the rare primes with p-1 or q-1 sharing a factor with e.
+	for range 100 {
+		var p, q *big.Int
+		if _, p, q, err = GenerateSemiprime(random, s.Key.Bits, s.Key.GapBits); err != nil {
+			return nil, err
+		}
+		var priv *PrivateKey
+		if priv, err = NewPrivateKey(p, q, e); err == nil {
+			return priv, nil
+		}
+	}
+	return nil, err
+}
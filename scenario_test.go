@@ -0,0 +1,69 @@
+package rsa_test
+
+import (
+	"bytes"
+	mathrand "math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestScenarioRun(t *testing.T) {
+	for _, spec := range []string{
+		`{"name": "Close primes", "attack": "fermat", "message": "hi",
+		  "key": {"bits": 128, "e": 65537, "weakness": "close-primes", "gapBits": 16}}`,
+		`{"name": "Small key", "attack": "rho", "message": "hi", "key": {"bits": 64, "e": 65537}}`,
+		`{"name": "Broadcast", "attack": "hastad", "message": "attack at dawn", "format": "hex",
+		  "key": {"bits": 256, "e": 3, "recipients": 3}}`,
+		`{"name": "Parity", "attack": "parity-oracle", "message": "hi", "key": {"bits": 128, "e": 65537}}`,
+	} {
+		scenario, err := rsa.LoadScenario(strings.NewReader(spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		result, err := scenario.Run(nil, &out)
+		if err != nil || result.Recovered != scenario.Message {
+			t.Errorf("%v: recovered %q (%v), expected %q", scenario.Name, result.Recovered, err, scenario.Message)
+		}
+		if !strings.Contains(out.String(), "recovered") {
+			t.Errorf("%v: the transcript misses the recovery:\n%v", scenario.Name, out.String())
+		}
+	}
+}
+
+func TestScenarioReproducible(t *testing.T) {
+	scenario, err := rsa.LoadScenario(strings.NewReader(`{"name": "Close primes", "attack": "fermat", "message": "hi",
+	  "key": {"bits": 128, "e": 65537, "weakness": "close-primes", "gapBits": 16}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyLine := func() string {
+
+		var out bytes.Buffer
+		if _, err := scenario.Run(mathrand.NewChaCha8([32]byte{7}), &out); err != nil {
+			t.Fatal(err)
+		}
+		line, _, _ := strings.Cut(out.String()[strings.Index(out.String(), "key 1:"):], "\n")
+		return line
+	}
+	if first, second := keyLine(), keyLine(); first != second {
+		t.Errorf("the same seed gave %q and %q", first, second)
+	}
+}
+
+func TestLoadScenarioRejects(t *testing.T) {
+	for _, spec := range []string{
+		`not json`,
+		`{"attack": "guess", "key": {"bits": 128, "e": 3}}`,
+		`{"attack": "rho", "key": {"bits": 8, "e": 3}}`,
+		`{"attack": "rho", "key": {"bits": 128, "e": 4}}`,
+		`{"attack": "rho", "key": {"bits": 128, "e": 3, "weakness": "close-primes"}}`,
+		`{"attack": "rho", "key": {"bits": 128, "e": 3}, "extra": 1}`,
+	} {
+		if _, err := rsa.LoadScenario(strings.NewReader(spec)); err == nil {
+			t.Errorf("loaded %v, expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package rsa
+
+import (
+	"math/big"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// ScoreOptions configures ScoreCandidate and RankCandidates. The zero
+// value decodes with Base256Alphabet and scores printable characters only.
+type ScoreOptions struct {
+	// Alphabet decodes candidates, Base256Alphabet if nil.
+	Alphabet *Alphabet
+	// Dictionary lists the words expected in the plaintext; matching
+	// ignores case.
+	Dictionary []string
+	// PaddedLen, if positive, expects candidates to be PKCS#1 v1.5
+	// encryption blocks of PaddedLen bytes, unpadded before decoding.
+	PaddedLen int
+}
+
+// Candidate is a possible plaintext with the signals of its plausibility.
+type Candidate struct {
+	M    *big.Int
+	Text string
+	// Printable is the share of printable characters in Text.
+	Printable float64
+	// Words is the share of the words of Text found in the dictionary.
+	Words float64
+	// ValidPadding reports a well-formed block.
+	ValidPadding bool
+	// Score in [0, 1] is the mean of the signals the options ask for.
+	Score float64
+}
+
+// ScoreCandidate rates how plausible m is as a recovered plaintext.
+// A candidate that does not decode, or whose expected padding is invalid,
+// scores 0 on the signals that depend on its text.
+func ScoreCandidate(m *big.Int, opts *ScoreOptions) Candidate {
+
+	if opts == nil {
+		opts = &ScoreOptions{}
+	}
+	alphabet := opts.Alphabet
+	if alphabet == nil {
+		alphabet = Base256Alphabet
+	}
+	c := Candidate{M: m}
+	signals := 1
+
+	decoded := m
+	if opts.PaddedLen > 0 {
+		signals++
+		decoded = nil
+		if msg, err := unpaddedCandidate(m, opts.PaddedLen); err == nil {
+			c.ValidPadding = true
+			decoded = new(big.Int).SetBytes(msg)
+		}
+	}
+	if decoded != nil {
+		if text, err := Decode(decoded, alphabet); err == nil {
+			c.Text = text
+			c.Printable = printableShare(text)
+			c.Words = dictionaryShare(text, opts.Dictionary)
+		}
+	}
+
+	c.Score = c.Printable
+	if len(opts.Dictionary) > 0 {
+		signals++
+		c.Score += c.Words
+	}
+	if c.ValidPadding {
+		c.Score++
+	}
+	c.Score /= float64(signals)
+	return c
+}
+
+// RankCandidates scores the candidates of an attack such as RootMod,
+// MeetInTheMiddle or BruteForceDecrypt and returns them from the most
+// plausible to the least, ties in input order, so a pipeline can take the
+// first as the recovery.
+func RankCandidates(candidates []*big.Int, opts *ScoreOptions) []Candidate {
+
+	ranked := make([]Candidate, len(candidates))
+	for i, m := range candidates {
+		ranked[i] = ScoreCandidate(m, opts)
+	}
+	slices.SortStableFunc(ranked, func(a, b Candidate) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return ranked
+}
+
+// unpaddedCandidate returns the message of m as a k byte PKCS#1 v1.5
+// encryption block.
+func unpaddedCandidate(m *big.Int, k int) ([]byte, error) {
+
+	if m.Sign() < 0 || (m.BitLen()+7)/8 > k {
+		return nil, errPKCS1v15
+	}
+	return NewPrimitives(Hardened).UnpadPKCS1v15(m.FillBytes(make([]byte, k)))
+}
+
+// printableShare returns the share of the runes of text that are
+// printable or white space, an invalid UTF-8 byte counting as one
+// unprintable rune.
+func printableShare(text string) float64 {
+
+	total, printable := 0, 0
+	for _, r := range text {
+		total++
+		if r != unicode.ReplacementChar && (unicode.IsPrint(r) || unicode.IsSpace(r)) {
+			printable++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(printable) / float64(total)
+}
+
+// dictionaryShare returns the share of the words of text, runs of
+// letters, in dictionary.
+func dictionaryShare(text string, dictionary []string) float64 {
+
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(words) == 0 || len(dictionary) == 0 {
+		return 0
+	}
+	known := make(map[string]bool, len(dictionary))
+	for _, word := range dictionary {
+		known[strings.ToLower(word)] = true
+	}
+	hits := 0
+	for _, word := range words {
+		if known[strings.ToLower(word)] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(words))
+}
@@ -0,0 +1,40 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestRankCandidates(t *testing.T) {
+	secret, _ := rsa.Encode("attack at dawn", rsa.Base256Alphabet)
+	gibberish := new(big.Int).SetBytes([]byte{0x81, 0x02, 0xfe, 0x00, 0x13, 0x99, 0x7f})
+	almost, _ := rsa.Encode("xqzv kw jpq", rsa.Base256Alphabet)
+
+	opts := &rsa.ScoreOptions{Dictionary: []string{"attack", "at", "dawn", "retreat"}}
+	ranked := rsa.RankCandidates([]*big.Int{gibberish, almost, secret}, opts)
+	if ranked[0].Text != "attack at dawn" || ranked[0].Score != 1 {
+		t.Errorf("ranked first %+v, expected the secret with score 1", ranked[0])
+	}
+	if ranked[1].M != almost || ranked[1].Printable != 1 || ranked[1].Words != 0 {
+		t.Errorf("ranked second %+v, expected the printable nonsense", ranked[1])
+	}
+
+	// Only a well-formed block scores on padding.
+	k := 64
+	em, err := rsa.PadPKCS1v15(rand.Reader, []byte("attack at dawn"), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := new(big.Int).SetBytes(em)
+	opts.PaddedLen = k
+	ranked = rsa.RankCandidates([]*big.Int{secret, padded}, opts)
+	if ranked[0].M != padded || !ranked[0].ValidPadding || ranked[0].Text != "attack at dawn" {
+		t.Errorf("ranked first %+v, expected the padded block", ranked[0])
+	}
+	if ranked[1].ValidPadding || ranked[1].Score != 0 {
+		t.Errorf("scored the unpadded candidate %+v, expected 0", ranked[1])
+	}
+}
@@ -0,0 +1,108 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// GenerateSemiprime returns n = p * q of exactly bits bits whose random
+// prime factors are gapBits apart: 2^(gapBits-1) <= |p - q| < 2^gapBits.
+// Close primes make n easy for Fermat's factorization method while Rho
+// only depends on the size of the smaller factor, so sweeping gapBits
+// shows where one method overtakes the other.
+// The primes are drawn from random, crypto/rand.Reader if nil.
+// Very small gaps need twin-like primes and may take many draws.
+func GenerateSemiprime(random io.Reader, bits int, gapBits int) (n, p, q *big.Int, err error) {
+
+	if bits < 8 || gapBits < 2 || gapBits >= bits/2 {
+		return nil, nil, nil, fmt.Errorf("GenerateSemiprime: need 8 <= bits and 2 <= gapBits < bits/2, got %v and %v", bits, gapBits)
+	}
+
+	gapLow := new(big.Int).Lsh(big.NewInt(1), uint(gapBits-1))
+	gapHigh := new(big.Int).Lsh(big.NewInt(1), uint(gapBits))
+	gap := new(big.Int)
+
+	// p is drawn from [sqrt(2^(bits-1)), sqrt(2^bits) - 2^gapBits) so that
+	// p * q lands on exactly bits bits.
+	pLow := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	pLow.Sqrt(pLow)
+	pLow.Add(pLow, big.NewInt(1))
+	pRange := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	pRange.Sqrt(pRange)
+	pRange.Sub(pRange, gapHigh)
+	pRange.Sub(pRange, pLow)
+	if pRange.Sign() <= 0 {
+		return nil, nil, nil, fmt.Errorf("GenerateSemiprime: a %v bit gap leaves no room for %v bit factors", gapBits, bits/2)
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	for {
+		if p, err = rand.Int(random, pRange); err != nil {
+			return nil, nil, nil, err
+		}
+		p.Add(p, pLow)
+		p.SetBit(p, 0, 1)
+		for !p.ProbablyPrime(20) {
+			p.Add(p, big.NewInt(2))
+		}
+
+		// q is the first prime after p + delta with a random delta in [2^(gapBits-1), 2^gapBits).
+		delta, err := rand.Int(random, gapLow)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		q = delta.Add(delta, gapLow)
+		q.Add(q, p)
+		q.SetBit(q, 0, 1)
+		for !q.ProbablyPrime(20) {
+			q.Add(q, big.NewInt(2))
+		}
+
+		n = new(big.Int).Mul(p, q)
+		gap.Sub(q, p)
+		if n.BitLen() == bits && gap.Cmp(gapHigh) < 0 {
+			return n, p, q, nil
+		}
+	}
+}
+
+// FermatFactorization splits an odd n > 1 into p <= q with n = p * q by
+// Fermat's method, looking for a >= sqrt(n) with a^2 - n = b^2 a square,
+// so that n = (a - b)(a + b). It needs (q - p)^2 / (8 sqrt(n)) steps, only
+// a few for the close factors GenerateSemiprime makes with a small gap, and
+// none for a perfect square, which returns p = q = sqrt(n) at once. A prime
+// n ends with the trivial 1 * n, maxSteps permitting.
+// https://en.wikipedia.org/wiki/Fermat%27s_factorization_method
+func FermatFactorization(n *big.Int, maxSteps int) (p, q *big.Int, cost Cost, err error) {
+
+	meter := newCostMeter()
+	if n.Sign() <= 0 || n.Bit(0) == 0 || n.Cmp(big.NewInt(1)) == 0 {
+		return nil, nil, meter.done(), fmt.Errorf("FermatFactorization: %v is not an odd number > 1", n)
+	}
+	if root, ok := exactSqrt(n); ok {
+		meter.cost.ModMuls++
+		return root, new(big.Int).Set(root), meter.done(), nil
+	}
+
+	a := Isqrt(new(big.Int), n)
+	a.Add(a, big.NewInt(1))
+	// r = a^2 - n, kept up to date by r += 2a + 1 as a grows by 1.
+	r := new(big.Int).Mul(a, a)
+	r.Sub(r, n)
+	meter.cost.ModMuls++
+	step := new(big.Int)
+	for i := 0; i < maxSteps; i++ {
+		if b, ok := exactSqrt(r); ok {
+			meter.cost.ModMuls += i + 1
+			return new(big.Int).Sub(a, b), new(big.Int).Add(a, b), meter.done(), nil
+		}
+		r.Add(r, step.Lsh(a, 1).Add(step, big.NewInt(1)))
+		a.Add(a, big.NewInt(1))
+	}
+	meter.cost.ModMuls += maxSteps
+	return nil, nil, meter.done(), fmt.Errorf("FermatFactorization: no factor of %v in %v steps", n, maxSteps)
+}
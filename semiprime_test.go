@@ -0,0 +1,64 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestGenerateSemiprime(t *testing.T) {
+	for _, sizes := range []struct{ bits, gapBits int }{{64, 8}, {128, 20}, {255, 100}} {
+		n, p, q, err := rsa.GenerateSemiprime(nil, sizes.bits, sizes.gapBits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.BitLen() != sizes.bits || new(big.Int).Mul(p, q).Cmp(n) != 0 {
+			t.Errorf("%+v: %v * %v does not give a %v bit n", sizes, p, q, sizes.bits)
+		}
+		if gap := new(big.Int).Sub(q, p); gap.Sign() <= 0 || gap.BitLen() != sizes.gapBits {
+			t.Errorf("%+v: gap %v between %v and %v does not have %v bits", sizes, gap, p, q, sizes.gapBits)
+		}
+		if !p.ProbablyPrime(20) || !q.ProbablyPrime(20) {
+			t.Errorf("%+v: factors are not prime", sizes)
+		}
+	}
+
+	if _, _, _, err := rsa.GenerateSemiprime(nil, 64, 40); err == nil {
+		t.Error("expected an error for a gap larger than the factors")
+	}
+}
+
+func TestFermatFactorization(t *testing.T) {
+	n, p, q, err := rsa.GenerateSemiprime(rand.Reader, 128, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotP, gotQ, cost, err := rsa.FermatFactorization(n, 1000)
+	if err != nil || gotP.Cmp(p) != 0 || gotQ.Cmp(q) != 0 {
+		t.Errorf("factored %v into %v * %v (%v), expected %v * %v", n, gotP, gotQ, err, p, q)
+	}
+	t.Logf("%v steps", cost.ModMuls)
+
+	// Perfect squares are returned at once.
+	prime := big.NewInt(1000003)
+	square := new(big.Int).Mul(prime, prime)
+	if p, q, cost, err := rsa.FermatFactorization(square, 0); err != nil || p.Cmp(prime) != 0 || q.Cmp(prime) != 0 || cost.ModMuls != 1 {
+		t.Errorf("factored %v into %v * %v (%v) in %v steps, expected its root twice at once", square, p, q, err, cost.ModMuls)
+	}
+	if factors, err := rsa.Factorize(square); err != nil || len(factors) != 1 || factors[0].Exp != 2 {
+		t.Errorf("Factorize(%v) = %v (%v)", square, factors, err)
+	}
+	if p, q, stats := rsa.GetPrimeFactorsWithStats(square.Int64()); p.Cmp(prime) != 0 || q.Cmp(prime) != 0 || stats.Iterations != 0 {
+		t.Errorf("GetPrimeFactorsWithStats(%v) = %v, %v after %v iterations", square, p, q, stats.Iterations)
+	}
+
+	// Far apart factors take too long.
+	if _, _, _, err := rsa.FermatFactorization(big.NewInt(3*1000003), 10); err == nil {
+		t.Error("expected no factor in 10 steps")
+	}
+	if _, _, _, err := rsa.FermatFactorization(big.NewInt(10), 10); err == nil {
+		t.Error("expected an error for an even n")
+	}
+}
@@ -0,0 +1,237 @@
+package rsa
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// Kinds of SessionEvent.
+const (
+	EventRandom = "random"
+	EventQuery  = "query"
+	EventStep   = "step"
+)
+
+// SessionEvent is one recorded input or milestone of a session.
+type SessionEvent struct {
+	Kind string `json:"kind"`
+	// Data holds the bytes drawn from the random source.
+	Data []byte `json:"data,omitempty"`
+	// Query and Answer are the hexadecimal oracle question and reply,
+	// Answer empty if the oracle failed with Error.
+	Query  string `json:"query,omitempty"`
+	Answer string `json:"answer,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// Label and Value describe a step such as a generated key or an
+	// intermediate attack result.
+	Label string `json:"label,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Session records everything nondeterministic a run consumes, the random
+// bytes behind key generation, blinding or KEM and the answers of the
+// oracles, so that the run can be replayed exactly: a flaky attack can be
+// debugged on the failing run, and a student's submission can be checked
+// against the recorded session. Steps mark milestones; on replay they must
+// come out the same, pinpointing where the run diverges.
+// A recording Session is created by NewSession, a replaying one by
+// ReadSession, and both are used the same way.
+type Session struct {
+	mu      sync.Mutex
+	replay  bool
+	events  []SessionEvent
+	random  io.Reader
+	pending []byte // replay: recorded random bytes not yet served
+	cursors map[string]int
+}
+
+// NewSession returns a Session recording the bytes read from random,
+// crypto/rand.Reader if nil.
+func NewSession(random io.Reader) *Session {
+
+	if random == nil {
+		random = rand.Reader
+	}
+	return &Session{random: random, cursors: map[string]int{}}
+}
+
+// ReadSession loads a session written by WriteTo for replay.
+func ReadSession(r io.Reader) (*Session, error) {
+
+	s := &Session{replay: true, cursors: map[string]int{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		var event SessionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("ReadSession: line %v: %v", len(s.events)+1, err)
+		}
+		s.events = append(s.events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ReadSession: %v", err)
+	}
+	return s, nil
+}
+
+// WriteTo writes the events as JSON lines.
+func (s *Session) WriteTo(w io.Writer) (int64, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var written int64
+	for _, event := range s.events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return written, err
+		}
+		n, err := w.Write(append(line, '\n'))
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// Events returns a copy of the recorded or loaded events.
+func (s *Session) Events() []SessionEvent {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SessionEvent(nil), s.events...)
+}
+
+// Random returns the session's random source: recording, it passes the
+// underlying reader's bytes through; replaying, it serves the recorded
+// bytes in order and fails once they run out.
+func (s *Session) Random() io.Reader {
+
+	return sessionReader{s}
+}
+
+// sessionReader is the io.Reader of Session.Random.
+type sessionReader struct {
+	s *Session
+}
+
+// Read records or replays random bytes.
+func (r sessionReader) Read(p []byte) (int, error) {
+
+	s := r.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.replay {
+		n, err := s.random.Read(p)
+		if n > 0 {
+			s.events = append(s.events, SessionEvent{Kind: EventRandom, Data: append([]byte(nil), p[:n]...)})
+		}
+		return n, err
+	}
+
+	for len(s.pending) < len(p) {
+		event, ok := s.next(EventRandom)
+		if !ok {
+			break
+		}
+		s.pending = append(s.pending, event.Data...)
+	}
+	if len(s.pending) == 0 {
+		return 0, fmt.Errorf("Session: recorded random bytes exhausted")
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Oracle wraps oracle so its queries are recorded; replaying, the
+// recorded answers are returned and oracle, which may be nil, is not
+// consulted. A query differing from the recorded one is an error.
+func (s *Session) Oracle(oracle Oracle) Oracle {
+
+	return sessionOracle{s: s, oracle: oracle}
+}
+
+// sessionOracle is the Oracle of Session.Oracle.
+type sessionOracle struct {
+	s      *Session
+	oracle Oracle
+}
+
+// Query records or replays one oracle query.
+func (o sessionOracle) Query(c *big.Int) (*big.Int, error) {
+
+	s := o.s
+	if !s.replay {
+		answer, err := o.oracle.Query(c)
+		event := SessionEvent{Kind: EventQuery, Query: c.Text(16)}
+		if err != nil {
+			event.Error = err.Error()
+		} else {
+			event.Answer = answer.Text(16)
+		}
+		s.mu.Lock()
+		s.events = append(s.events, event)
+		s.mu.Unlock()
+		return answer, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event, ok := s.next(EventQuery)
+	if !ok {
+		return nil, fmt.Errorf("Session: no more recorded oracle queries")
+	}
+	if event.Query != c.Text(16) {
+		return nil, fmt.Errorf("Session: query %v diverges from the recorded %v", c.Text(16), event.Query)
+	}
+	if event.Error != "" {
+		return nil, fmt.Errorf("%v", event.Error)
+	}
+	answer, ok := new(big.Int).SetString(event.Answer, 16)
+	if !ok {
+		return nil, fmt.Errorf("Session: malformed recorded answer %q", event.Answer)
+	}
+	return answer, nil
+}
+
+// Step records a milestone, or on replay checks it against the recording.
+func (s *Session) Step(label string, value fmt.Stringer) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	step := SessionEvent{Kind: EventStep, Label: label, Value: value.String()}
+	if !s.replay {
+		s.events = append(s.events, step)
+		return nil
+	}
+
+	event, ok := s.next(EventStep)
+	if !ok {
+		return fmt.Errorf("Session: step %q is not in the recording", label)
+	}
+	if event.Label != step.Label || event.Value != step.Value {
+		return fmt.Errorf("Session: step %q = %v diverges from the recorded %q = %v", label, step.Value, event.Label, event.Value)
+	}
+	return nil
+}
+
+// next returns the next unconsumed event of kind, each kind replayed in
+// its own recorded order.
+func (s *Session) next(kind string) (SessionEvent, bool) {
+
+	for i := s.cursors[kind]; i < len(s.events); i++ {
+		if s.events[i].Kind == kind {
+			s.cursors[kind] = i + 1
+			return s.events[i], true
+		}
+	}
+	s.cursors[kind] = len(s.events)
+	return SessionEvent{}, false
+}
@@ -0,0 +1,66 @@
+package rsa_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+// sessionRun generates a key, encrypts and runs the parity attack,
+// drawing everything nondeterministic from session.
+func sessionRun(t *testing.T, session *rsa.Session, oracle func(*rsa.PrivateKey) rsa.Oracle) error {
+
+	priv, err := rsa.GenerateKeyPair(session.Random(), 128, big.NewInt(65537))
+	if err != nil {
+		return err
+	}
+	if err := session.Step("modulus", priv.N); err != nil {
+		return err
+	}
+	m, err := rand.Int(session.Random(), priv.N)
+	if err != nil {
+		return err
+	}
+	c := new(big.Int).Exp(m, priv.E, priv.N)
+
+	recovered, _, err := rsa.ParityAttack(&priv.PublicKey, c, session.Oracle(oracle(priv)))
+	if err != nil {
+		return err
+	}
+	return session.Step("plaintext", recovered)
+}
+
+func TestSessionReplay(t *testing.T) {
+	recording := rsa.NewSession(rand.Reader)
+	if err := sessionRun(t, recording, func(priv *rsa.PrivateKey) rsa.Oracle { return rsa.NewParityOracle(priv) }); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := recording.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	saved := buf.String()
+
+	// The replay needs neither fresh randomness nor the private key's oracle.
+	replay, err := rsa.ReadSession(bytes.NewBufferString(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sessionRun(t, replay, func(*rsa.PrivateKey) rsa.Oracle { return nil }); err != nil {
+		t.Fatalf("replay diverged: %v", err)
+	}
+	if len(replay.Events()) != len(recording.Events()) {
+		t.Errorf("replayed %v events, recorded %v", len(replay.Events()), len(recording.Events()))
+	}
+
+	diverging, err := rsa.ReadSession(bytes.NewBufferString(saved))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := diverging.Step("modulus", big.NewInt(42)); err == nil {
+		t.Error("expected a diverging step to be reported")
+	}
+}
@@ -0,0 +1,142 @@
+package rsa
+
+import "iter"
+
+// TotientSieve returns φ(k) for every 0 <= k <= limit, φ(0) reported as 0,
+// by starting from φ(k) = k and multiplying in 1 - 1/p for every prime
+// p dividing k, without factoring any k individually.
+// https://en.wikipedia.org/wiki/Euler%27s_totient_function
+func TotientSieve(limit int) []int64 {
+
+	if limit < 0 {
+		return nil
+	}
+	phi := make([]int64, limit+1)
+	for k := range phi {
+		phi[k] = int64(k)
+	}
+	for p := 2; p <= limit; p++ {
+		if phi[p] != int64(p) {
+			continue // composite, already reduced by a smaller prime
+		}
+		for k := p; k <= limit; k += p {
+			phi[k] -= phi[k] / int64(p)
+		}
+	}
+	return phi
+}
+
+// SmallestPrimeFactorSieve returns spf(k), the smallest prime factor of k,
+// for every 0 <= k <= limit, 0 and 1 reported as having none (0).
+// Dividing k by spf(k) repeatedly factors any k <= limit in log(k) steps.
+func SmallestPrimeFactorSieve(limit int) []int64 {
+
+	if limit < 0 {
+		return nil
+	}
+	spf := make([]int64, limit+1)
+	for p := 2; p <= limit; p++ {
+		if spf[p] != 0 {
+			continue
+		}
+		for k := p; k <= limit; k += p {
+			if spf[k] == 0 {
+				spf[k] = int64(p)
+			}
+		}
+	}
+	return spf
+}
+
+// smallPrimes returns the primes up to bound by the sieve of Eratosthenes.
+// https://en.wikipedia.org/wiki/Sieve_of_Eratosthenes
+func smallPrimes(bound int64) []int64 {
+
+	if bound < 2 {
+		return nil
+	}
+	composite := make([]bool, bound+1)
+	primes := []int64{}
+	for i := int64(2); i <= bound; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for j := i * i; j <= bound; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// primeSegment is the width of the windows Primes sieves one at a time.
+const primeSegment = 1 << 15
+
+// Primes yields the primes up to limit in increasing order, lazily, by a
+// segmented sieve of Eratosthenes: memory grows with the primes up to
+// sqrt(limit) only, so Primes(math.MaxUint64) can be ranged over until
+// the caller breaks.
+// https://en.wikipedia.org/wiki/Sieve_of_Eratosthenes#Segmented_sieve
+func Primes(limit uint64) iter.Seq[uint64] {
+
+	return func(yield func(uint64) bool) {
+
+		var base []uint64 // primes up to sqrt(limit) found so far
+		composite := make([]bool, primeSegment)
+		for lo := uint64(0); ; lo += primeSegment {
+			hi := limit
+			if limit-lo >= primeSegment {
+				hi = lo + primeSegment - 1
+			}
+			clear(composite)
+			for _, p := range base {
+				if p > hi/p {
+					break
+				}
+				start := lo
+				if rem := lo % p; rem != 0 {
+					if hi-lo < p-rem {
+						continue // no multiple of p in the segment
+					}
+					start += p - rem
+				}
+				markMultiples(composite, lo, hi, p, max(p*p, start))
+			}
+
+			// Primes below sqrt(hi) are found in this segment only while
+			// the first one is sieved, so their multiples are crossed out
+			// as they are met.
+			for x := lo; ; x++ {
+				if x >= 2 && !composite[x-lo] {
+					if x <= hi/x {
+						markMultiples(composite, lo, hi, x, x*x)
+					}
+					if x <= limit/x {
+						base = append(base, x)
+					}
+					if !yield(x) {
+						return
+					}
+				}
+				if x == hi {
+					break
+				}
+			}
+			if hi == limit {
+				return
+			}
+		}
+	}
+}
+
+// markMultiples crosses the multiples of p from start to hi out of the
+// segment beginning at lo, without overflowing near math.MaxUint64.
+func markMultiples(composite []bool, lo, hi, p, start uint64) {
+
+	for j := start; j <= hi; j += p {
+		composite[j-lo] = true
+		if hi-j < p {
+			break
+		}
+	}
+}
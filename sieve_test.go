@@ -0,0 +1,67 @@
+package rsa_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestTotientSieve(t *testing.T) {
+	want := []int64{0, 1, 1, 2, 2, 4, 2, 6, 4, 6, 4, 10, 4, 12}
+	phi := rsa.TotientSieve(len(want) - 1)
+	for k := range want {
+		if phi[k] != want[k] {
+			t.Errorf("φ(%v) = %v, expected %v", k, phi[k], want[k])
+		}
+	}
+
+	// Agrees with the factorization based GetPhi for the classroom modulus.
+	phi = rsa.TotientSieve(937513)
+	if got := rsa.GetPhi(new(big.Int), big.NewInt(877), big.NewInt(1069)); phi[937513] != got.Int64() {
+		t.Errorf("φ(937513) = %v, expected %v", phi[937513], got)
+	}
+}
+
+func TestSmallestPrimeFactorSieve(t *testing.T) {
+	spf := rsa.SmallestPrimeFactorSieve(1000)
+	for k, want := range map[int]int64{0: 0, 1: 0, 2: 2, 9: 3, 35: 5, 97: 97, 1000: 2, 961: 31} {
+		if spf[k] != want {
+			t.Errorf("spf(%v) = %v, expected %v", k, spf[k], want)
+		}
+	}
+}
+
+func TestPrimes(t *testing.T) {
+	var primes []int64
+	for p := range rsa.Primes(100000) {
+		primes = append(primes, int64(p))
+	}
+	want := rsa.PrimePi(100000)
+	if int64(len(primes)) != want {
+		t.Fatalf("yielded %v primes up to 100000, expected %v", len(primes), want)
+	}
+	for _, p := range primes {
+		if !big.NewInt(p).ProbablyPrime(0) {
+			t.Fatalf("yielded the composite %v", p)
+		}
+	}
+	if primes[len(primes)-1] != 99991 {
+		t.Errorf("last prime %v, expected 99991", primes[len(primes)-1])
+	}
+
+	// Lazily from far beyond what a plain sieve could hold.
+	count := 0
+	for p := range rsa.Primes(math.MaxUint64) {
+		if count++; count == 5 {
+			if p != 11 {
+				t.Errorf("fifth prime %v, expected 11", p)
+			}
+			break
+		}
+	}
+	for p := range rsa.Primes(1) {
+		t.Errorf("yielded %v below 2", p)
+	}
+}
@@ -0,0 +1,96 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// IsSmooth reports whether every prime factor of n > 0 is at most bound.
+// 0, divisible by every prime, is not smooth.
+// https://en.wikipedia.org/wiki/Smooth_number
+func IsSmooth(n *big.Int, bound int64) bool {
+
+	if n.Sign() == 0 {
+		return false
+	}
+	rest := new(big.Int).Abs(n)
+	quotient, remainder := new(big.Int), new(big.Int)
+	for _, prime := range smallPrimes(bound) {
+		r := big.NewInt(prime)
+		for {
+			quotient.QuoRem(rest, r, remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			rest.Set(quotient)
+		}
+	}
+	return rest.Cmp(big.NewInt(1)) == 0
+}
+
+// IsSafePrime reports whether p is a prime of the form 2q + 1 with q prime,
+// so that p-1 has a prime factor as large as possible.
+// https://en.wikipedia.org/wiki/Safe_and_Sophie_Germain_primes
+func IsSafePrime(p *big.Int) bool {
+
+	if !p.ProbablyPrime(20) {
+		return false
+	}
+	q := new(big.Int).Rsh(p, 1)
+	return q.ProbablyPrime(20)
+}
+
+// PollardPMinus1 sets z to a nontrivial factor of n and returns it with
+// the Cost of the search if n has a prime factor p with p-1 bound-smooth:
+// a^M - 1 is then a multiple of p for M the product of all prime powers
+// up to bound, because the order of a modulo p divides p-1 which divides M.
+// https://en.wikipedia.org/wiki/Pollard%27s_p_%E2%88%92_1_algorithm
+func PollardPMinus1(z, n *big.Int, bound int64) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	one := big.NewInt(1)
+	a := big.NewInt(2)
+	power := new(big.Int)
+	gcd := new(big.Int)
+
+	for _, prime := range smallPrimes(bound) {
+		// The largest power of prime not exceeding bound.
+		pk := prime
+		for pk <= bound/prime {
+			pk *= prime
+		}
+		a.Exp(a, power.SetInt64(pk), n)
+		meter.exp(power)
+	}
+
+	GetGcd(gcd, a.Sub(a, one), n)
+	if gcd.Cmp(one) == 0 || gcd.Cmp(n) == 0 {
+		return nil, meter.done(), fmt.Errorf("PollardPMinus1: no factor of %v with a %v-smooth p-1", n, bound)
+	}
+	return z.Set(gcd), meter.done(), nil
+}
+
+// CheckSmoothOrder flags moduli with a prime p where p-1 is bound-smooth.
+// Every element then has an order made of small primes, so Pollard's p-1
+// factors n and the discrete logarithms of small subgroup confinement
+// attacks are cheap; safe primes rule this out. With priv the factors
+// p-1 and q-1 are examined directly, without it n is probed by
+// PollardPMinus1, which can only find the weakness, not prove its absence.
+func CheckSmoothOrder(pub *PublicKey, priv *PrivateKey, bound int64) error {
+
+	one := big.NewInt(1)
+	if priv == nil {
+		if p, _, err := PollardPMinus1(new(big.Int), pub.N, bound); err == nil {
+			return fmt.Errorf("CheckSmoothOrder: p-1 is %v-smooth for the factor p = %v found by Pollard's p-1", bound, p)
+		}
+		return nil
+	}
+
+	for _, prime := range []*big.Int{priv.P, priv.Q} {
+		order := new(big.Int).Sub(prime, one)
+		if IsSmooth(order, bound) {
+			return fmt.Errorf("CheckSmoothOrder: %v-1 is %v-smooth", prime, bound)
+		}
+	}
+	return nil
+}
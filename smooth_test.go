@@ -0,0 +1,98 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+// smoothPrime returns a prime p > 2^bits with p-1 twice a product of
+// distinct primes below 1000.
+func smoothPrime(bits int) *big.Int {
+
+	rnd := mrand.New(mrand.NewSource(1))
+	small := []int64{}
+	for i := int64(3); i < 1000; i += 2 {
+		if big.NewInt(i).ProbablyPrime(0) {
+			small = append(small, i)
+		}
+	}
+	for {
+		m := big.NewInt(2)
+		for _, i := range rnd.Perm(len(small)) {
+			m.Mul(m, big.NewInt(small[i]))
+			if m.BitLen() >= bits {
+				break
+			}
+		}
+		p := new(big.Int).Add(m, big.NewInt(1))
+		if p.ProbablyPrime(20) {
+			return p
+		}
+	}
+}
+
+func TestIsSmooth(t *testing.T) {
+	tests := []struct {
+		n     int64
+		bound int64
+		want  bool
+	}{
+		{0, 7, false},
+		{1, 2, true},
+		{1024, 2, true},
+		{2 * 3 * 5 * 7 * 7, 7, true},
+		{2 * 3 * 11, 7, false},
+		{1000003, 1000, false},
+	}
+	for _, tt := range tests {
+		if got := rsa.IsSmooth(big.NewInt(tt.n), tt.bound); got != tt.want {
+			t.Errorf("IsSmooth(%v, %v) = %v, expected %v", tt.n, tt.bound, got, tt.want)
+		}
+	}
+}
+
+func TestIsSafePrime(t *testing.T) {
+	for n, want := range map[int64]bool{5: true, 7: true, 11: true, 13: false, 23: true, 29: false, 15: false} {
+		if got := rsa.IsSafePrime(big.NewInt(n)); got != want {
+			t.Errorf("IsSafePrime(%v) = %v, expected %v", n, got, want)
+		}
+	}
+}
+
+func TestCheckSmoothOrder(t *testing.T) {
+	p := smoothPrime(128)
+	q, err := rand.Prime(rand.Reader, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak, err := rsa.NewPrivateKey(p, q, rsatest.DefaultE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.CheckSmoothOrder(&weak.PublicKey, weak, 1000); err == nil {
+		t.Error("smooth p-1 not flagged from the factors")
+	}
+	if err := rsa.CheckSmoothOrder(&weak.PublicKey, nil, 1000); err == nil {
+		t.Error("smooth p-1 not flagged by probing")
+	}
+	factor, cost, err := rsa.PollardPMinus1(new(big.Int), weak.N, 1000)
+	if err != nil || (factor.Cmp(p) != 0 && factor.Cmp(q) != 0) {
+		t.Errorf("PollardPMinus1 = %v, %v, expected %v", factor, err, p)
+	}
+	if cost.ModMuls <= 0 {
+		t.Errorf("PollardPMinus1 cost %+v, expected multiplications", cost)
+	}
+
+	strong, err := rsatest.RandomKeyPair(rand.Reader, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.CheckSmoothOrder(&strong.PublicKey, strong, 100); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,134 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Mersenne sets z to the Mersenne number 2^p - 1 and returns z.
+// https://en.wikipedia.org/wiki/Mersenne_prime
+func Mersenne(z *big.Int, p uint) *big.Int {
+
+	z.Lsh(big.NewInt(1), p)
+	return z.Sub(z, big.NewInt(1))
+}
+
+// Fermat sets z to the Fermat number 2^(2^k) + 1 and returns z.
+// https://en.wikipedia.org/wiki/Fermat_number
+func Fermat(z *big.Int, k uint) *big.Int {
+
+	z.Lsh(big.NewInt(1), 1<<k)
+	return z.Add(z, big.NewInt(1))
+}
+
+// MersenneFactor sets z to the smallest prime factor of 2^p - 1, p an odd
+// prime, among the first limit candidates and returns z with the Cost of
+// the search. Every prime
+// factor q has the form 2jp + 1 and is 1 or 7 mod 8, and divides
+// 2^p - 1 exactly when 2^p = 1 mod q, so only those few candidates are tried.
+func MersenneFactor(z *big.Int, p uint, limit int) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if p < 3 || !IsPrime64(uint64(p)) {
+		return nil, meter.done(), fmt.Errorf("MersenneFactor: exponent %v is not an odd prime", p)
+	}
+
+	step := big.NewInt(2 * int64(p))
+	exp := big.NewInt(int64(p))
+	one := big.NewInt(1)
+	q := big.NewInt(1)
+	r := new(big.Int)
+
+	for j := 0; j < limit; j++ {
+		q.Add(q, step)
+		if mod8 := q.Bit(2)<<2 | q.Bit(1)<<1 | q.Bit(0); mod8 != 1 && mod8 != 7 {
+			continue
+		}
+		meter.exp(exp)
+		if r.Exp(big.NewInt(2), exp, q).Cmp(one) == 0 {
+			return z.Set(q), meter.done(), nil
+		}
+	}
+	return nil, meter.done(), fmt.Errorf("MersenneFactor: no factor of 2^%v - 1 among %v candidates", p, limit)
+}
+
+// FermatFactor sets z to the smallest prime factor of 2^(2^k) + 1, k >= 2,
+// among the first limit candidates and returns z with the Cost of the
+// search. Every prime factor has
+// the form j * 2^(k+2) + 1, Lucas' refinement of Euler's result, and divides
+// the number exactly when 2^(2^k) = -1 mod q.
+func FermatFactor(z *big.Int, k uint, limit int) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	if k < 2 {
+		return nil, meter.done(), fmt.Errorf("FermatFactor: F%v is prime", k)
+	}
+
+	step := new(big.Int).Lsh(big.NewInt(1), k+2)
+	exp := new(big.Int).Lsh(big.NewInt(1), k)
+	q := big.NewInt(1)
+	r := new(big.Int)
+
+	for j := 0; j < limit; j++ {
+		q.Add(q, step)
+		r.Exp(big.NewInt(2), exp, q)
+		meter.exp(exp)
+		if r.Add(r, big.NewInt(1)).Cmp(q) == 0 {
+			return z.Set(q), meter.done(), nil
+		}
+	}
+	return nil, meter.done(), fmt.Errorf("FermatFactor: no factor of F%v among %v candidates", k, limit)
+}
+
+// PollardRhoPower sets z to a nontrivial factor of n found by Pollard's rho
+// with the polynomial x^power + 1 and returns z with the Cost of the
+// search. When every prime factor q
+// of n is 1 mod power, as for Mersenne and Fermat numbers, x^power takes
+// only (q-1)/power distinct values mod q and the cycle is about sqrt(power)
+// times shorter than with x^2 + 1.
+// Brent and Pollard factored F8 this way.
+// https://maths-people.anu.edu.au/~brent/pd/rpb061.pdf
+func PollardRhoPower(z, n *big.Int, power int64, maxIterations int) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	one := big.NewInt(1)
+	exp := big.NewInt(power)
+	step := func(x *big.Int) {
+
+		x.Exp(x, exp, n)
+		meter.exp(exp)
+		x.Add(x, one)
+		if x.Cmp(n) == 0 {
+			x.SetInt64(0)
+		}
+	}
+
+	// Not 2, which x^power + 1 fixes for many special forms.
+	x, y := big.NewInt(3), big.NewInt(3)
+	diff, gcd := new(big.Int), new(big.Int)
+	for i := 0; i < maxIterations; i++ {
+		step(x)
+		step(y)
+		step(y)
+		GetGcd(gcd, diff.Abs(diff.Sub(x, y)), n)
+		if gcd.Cmp(n) == 0 {
+			break
+		}
+		if gcd.Cmp(one) != 0 {
+			return z.Set(gcd), meter.done(), nil
+		}
+	}
+	return nil, meter.done(), fmt.Errorf("PollardRhoPower: no factor of %v with x^%v + 1", n, power)
+}
+
+// MersenneRho sets z to a factor of 2^p - 1 by PollardRhoPower with power 2p.
+func MersenneRho(z *big.Int, p uint, maxIterations int) (*big.Int, Cost, error) {
+
+	return PollardRhoPower(z, Mersenne(new(big.Int), p), 2*int64(p), maxIterations)
+}
+
+// FermatRho sets z to a factor of F_k by PollardRhoPower with power 2^(k+2).
+func FermatRho(z *big.Int, k uint, maxIterations int) (*big.Int, Cost, error) {
+
+	return PollardRhoPower(z, Fermat(new(big.Int), k), 1<<(k+2), maxIterations)
+}
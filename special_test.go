@@ -0,0 +1,52 @@
+package rsa_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestMersenneFermat(t *testing.T) {
+	if got := rsa.Mersenne(new(big.Int), 7); got.Int64() != 127 {
+		t.Errorf("M7 = %v", got)
+	}
+	if got := rsa.Fermat(new(big.Int), 4); got.Int64() != 65537 {
+		t.Errorf("F4 = %v", got)
+	}
+}
+
+func TestSpecialFormFactors(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor func(z *big.Int) (*big.Int, rsa.Cost, error)
+		want   int64
+	}{
+		{"MersenneFactor 11", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.MersenneFactor(z, 11, 100) }, 23},
+		{"MersenneFactor 29", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.MersenneFactor(z, 29, 100) }, 233},
+		// Cole's 1903 factorization of M67.
+		{"MersenneFactor 67", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.MersenneFactor(z, 67, 2000000) }, 193707721},
+		{"FermatFactor 5", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.FermatFactor(z, 5, 100) }, 641},
+		{"FermatFactor 6", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.FermatFactor(z, 6, 10000) }, 274177},
+		{"MersenneRho 67", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.MersenneRho(z, 67, 100000) }, 193707721},
+		{"FermatRho 6", func(z *big.Int) (*big.Int, rsa.Cost, error) { return rsa.FermatRho(z, 6, 10000) }, 274177},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cost, err := tt.factor(new(big.Int))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Int64() != tt.want {
+				t.Errorf("got %v, expected %v", got, tt.want)
+			}
+			if cost.ModMuls <= 0 || cost.Duration <= 0 {
+				t.Errorf("cost %+v, expected multiplications and a duration", cost)
+			}
+		})
+	}
+
+	if _, _, err := rsa.MersenneFactor(new(big.Int), 61, 1000); err == nil {
+		t.Error("found a factor of the Mersenne prime M61")
+	}
+}
@@ -0,0 +1,51 @@
+package rsa
+
+import "math/big"
+
+// Isqrt sets z to the integer square root floor(sqrt(n)) of n >= 0 by
+// Newton's method and returns z: from 2^ceil(bits/2), above the root, the
+// iterates x = (x + n/x) / 2 decrease to it, doubling the correct bits
+// every step. Like big.Int's Sqrt it panics for a negative n.
+// https://en.wikipedia.org/wiki/Integer_square_root#Algorithm_using_Newton's_method
+func Isqrt(z, n *big.Int) *big.Int {
+
+	if n.Sign() < 0 {
+		panic("Isqrt: square root of negative number")
+	}
+	if n.Sign() == 0 {
+		return z.SetInt64(0)
+	}
+	x := new(big.Int).Lsh(big.NewInt(1), uint(n.BitLen()+1)/2)
+	next := new(big.Int)
+	for {
+		next.Quo(n, x)
+		next.Add(next, x)
+		next.Rsh(next, 1)
+		if next.Cmp(x) >= 0 {
+			return z.Set(x)
+		}
+		x.Set(next)
+	}
+}
+
+// exactSqrt returns the square root of n and true if n is a perfect
+// square, nil and false otherwise.
+func exactSqrt(n *big.Int) (*big.Int, bool) {
+
+	if n.Sign() < 0 {
+		return nil, false
+	}
+	// Squares are 0, 1, 4 or 9 mod 16, ruling out 3 in 4 numbers cheaply.
+	if low := n.Bits(); len(low) > 0 {
+		switch low[0] & 15 {
+		case 0, 1, 4, 9:
+		default:
+			return nil, false
+		}
+	}
+	root := Isqrt(new(big.Int), n)
+	if new(big.Int).Mul(root, root).Cmp(n) != 0 {
+		return nil, false
+	}
+	return root, true
+}
@@ -0,0 +1,31 @@
+package rsa_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestIsqrt(t *testing.T) {
+	for _, n := range []int64{0, 1, 2, 3, 4, 8, 9, 15, 16, 17, 937513, 1 << 62} {
+		b := big.NewInt(n)
+		if got, want := rsa.Isqrt(new(big.Int), b), new(big.Int).Sqrt(b); got.Cmp(want) != 0 {
+			t.Errorf("Isqrt(%v) = %v, expected %v", n, got, want)
+		}
+	}
+
+	// Around the square of a 300 bit number.
+	root := new(big.Int).Lsh(big.NewInt(0xbeef), 284)
+	square := new(big.Int).Mul(root, root)
+	for _, delta := range []int64{-1, 0, 1} {
+		n := new(big.Int).Add(square, big.NewInt(delta))
+		want := new(big.Int).Set(root)
+		if delta < 0 {
+			want.Sub(want, big.NewInt(1))
+		}
+		if got := rsa.Isqrt(n, n); got.Cmp(want) != 0 {
+			t.Errorf("Isqrt(root^2 %+d) = %v, expected %v", delta, got, want)
+		}
+	}
+}
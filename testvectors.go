@@ -0,0 +1,173 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	mathrand "math/rand/v2"
+)
+
+// Primitives covered by GenerateTestVectors.
+const (
+	PrimitiveEncrypt    = "textbook-encrypt"
+	PrimitiveDecryptCRT = "crt-decrypt"
+	PrimitiveSignCRT    = "crt-sign"
+	PrimitiveSignFDH    = "fdh-sign"
+	PrimitiveKDF2       = "kdf2-sha256"
+	PrimitiveKEM        = "rsa-kem"
+)
+
+// TestVectors is a reproducible set of inputs and outputs for every
+// primitive of the package under one key, for other implementations to
+// check themselves against. Byte strings are lowercase hexadecimal, the
+// numbers of the key and intermediates are written in Format, hexadecimal
+// unless changed with Reformat.
+type TestVectors struct {
+	Seed    string        `json:"seed"`
+	Bits    int           `json:"bits"`
+	Format  NumberFormat  `json:"format"`
+	Key     TestVectorKey `json:"key"`
+	Vectors []TestVector  `json:"vectors"`
+}
+
+// TestVectorKey is the private key of TestVectors with its CRT parameters.
+type TestVectorKey struct {
+	N    string `json:"n"`
+	E    string `json:"e"`
+	D    string `json:"d"`
+	P    string `json:"p"`
+	Q    string `json:"q"`
+	Dp   string `json:"dp"`
+	Dq   string `json:"dq"`
+	Qinv string `json:"qinv"`
+}
+
+// TestVector is one primitive applied to Input giving Output, with the
+// intermediate values a failing implementation can be debugged with.
+type TestVector struct {
+	Primitive     string            `json:"primitive"`
+	Input         string            `json:"input"`
+	Output        string            `json:"output"`
+	Intermediates map[string]string `json:"intermediates,omitempty"`
+}
+
+// testVectorsPerPrimitive is how many vectors each primitive gets.
+const testVectorsPerPrimitive = 3
+
+// GenerateTestVectors derives a bits bit key and the vectors of every
+// primitive from seed alone: the same seed gives the same vectors on any
+// machine and Go version, as all randomness comes from a ChaCha8 stream
+// keyed with SHA-256(seed).
+func GenerateTestVectors(seed string, bits int) (*TestVectors, error) {
+
+	random := mathrand.NewChaCha8(sha256.Sum256([]byte(seed)))
+	priv, err := GenerateKeyPair(random, bits, big.NewInt(65537))
+	if err != nil {
+		return nil, fmt.Errorf("GenerateTestVectors: %v", err)
+	}
+
+	tv := &TestVectors{
+		Seed:   seed,
+		Bits:   bits,
+		Format: FormatHex,
+		Key: TestVectorKey{
+			N: priv.N.Text(16), E: priv.E.Text(16), D: priv.D.Text(16),
+			P: priv.P.Text(16), Q: priv.Q.Text(16),
+			Dp: priv.Dp.Text(16), Dq: priv.Dq.Text(16), Qinv: priv.Qinv.Text(16),
+		},
+	}
+	add := func(primitive string, input, output []byte, intermediates map[string]string) {
+		tv.Vectors = append(tv.Vectors, TestVector{
+			Primitive:     primitive,
+			Input:         hex.EncodeToString(input),
+			Output:        hex.EncodeToString(output),
+			Intermediates: intermediates,
+		})
+	}
+
+	for i := 0; i < testVectorsPerPrimitive; i++ {
+		m, err := rand.Int(random, priv.N)
+		if err != nil {
+			return nil, fmt.Errorf("GenerateTestVectors: %v", err)
+		}
+		c := new(big.Int).Exp(m, priv.E, priv.N)
+		add(PrimitiveEncrypt, m.Bytes(), c.Bytes(), nil)
+
+		for _, primitive := range []string{PrimitiveDecryptCRT, PrimitiveSignCRT} {
+			x := c
+			if primitive == PrimitiveSignCRT {
+				x = m
+			}
+			mp := new(big.Int).Exp(x, priv.Dp, priv.P)
+			mq := new(big.Int).Exp(x, priv.Dq, priv.Q)
+			y, err := crtExp(priv, x, nil)
+			if err != nil {
+				return nil, fmt.Errorf("GenerateTestVectors: %v", err)
+			}
+			add(primitive, x.Bytes(), y.Bytes(), map[string]string{"mp": mp.Text(16), "mq": mq.Text(16)})
+		}
+
+		msg := make([]byte, 16+8*i)
+		if _, err := io.ReadFull(random, msg); err != nil {
+			return nil, fmt.Errorf("GenerateTestVectors: %v", err)
+		}
+		h := FDHHash(msg, priv.N)
+		s, err := SignFDH(priv, msg)
+		if err != nil {
+			return nil, fmt.Errorf("GenerateTestVectors: %v", err)
+		}
+		add(PrimitiveSignFDH, msg, s.Bytes(), map[string]string{"hash": h.Text(16)})
+
+		add(PrimitiveKDF2, msg, KDF2(msg, 32+16*i), nil)
+
+		key, kemC, err := EncapsulateKEM(random, &priv.PublicKey, 32)
+		if err != nil {
+			return nil, fmt.Errorf("GenerateTestVectors: %v", err)
+		}
+		z := new(big.Int).Exp(kemC, priv.D, priv.N)
+		add(PrimitiveKEM, kemC.Bytes(), key, map[string]string{"z": z.Text(16)})
+	}
+	return tv, nil
+}
+
+// Reformat rewrites the numbers of the key and intermediates from
+// tv.Format into f.
+func (tv *TestVectors) Reformat(f NumberFormat) error {
+
+	convert := func(s *string) error {
+		x, err := tv.Format.Parse(*s)
+		if err != nil {
+			return fmt.Errorf("Reformat: %v", err)
+		}
+		*s = f.Format(x)
+		return nil
+	}
+	k := &tv.Key
+	for _, s := range []*string{&k.N, &k.E, &k.D, &k.P, &k.Q, &k.Dp, &k.Dq, &k.Qinv} {
+		if err := convert(s); err != nil {
+			return err
+		}
+	}
+	for _, v := range tv.Vectors {
+		for name, value := range v.Intermediates {
+			if err := convert(&value); err != nil {
+				return err
+			}
+			v.Intermediates[name] = value
+		}
+	}
+	tv.Format = f
+	return nil
+}
+
+// WriteTestVectors writes tv as indented JSON.
+func WriteTestVectors(w io.Writer, tv *TestVectors) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tv)
+}
@@ -0,0 +1,111 @@
+package rsa_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestGenerateTestVectors(t *testing.T) {
+	tv, err := rsa.GenerateTestVectors("rsa test vectors", 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := rsa.GenerateTestVectors("rsa test vectors", 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first, second bytes.Buffer
+	if err := rsa.WriteTestVectors(&first, tv); err != nil {
+		t.Fatal(err)
+	}
+	rsa.WriteTestVectors(&second, again)
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("the same seed gave different test vectors")
+	}
+	if other, _ := rsa.GenerateTestVectors("other seed", 512); other.Key.N == tv.Key.N {
+		t.Error("different seeds gave the same key")
+	}
+
+	hexInt := func(s string) *big.Int {
+		x, _ := new(big.Int).SetString(s, 16)
+		return x
+	}
+	p, q, e := hexInt(tv.Key.P), hexInt(tv.Key.Q), hexInt(tv.Key.E)
+	priv, err := rsa.NewPrivateKey(p, q, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.N.Text(16) != tv.Key.N || priv.N.BitLen() != 512 {
+		t.Errorf("key n = %v does not match p*q of 512 bits", tv.Key.N)
+	}
+
+	for _, v := range tv.Vectors {
+		in, _ := hex.DecodeString(v.Input)
+		out, _ := hex.DecodeString(v.Output)
+		x, y := new(big.Int).SetBytes(in), new(big.Int).SetBytes(out)
+		var ok bool
+		switch v.Primitive {
+		case rsa.PrimitiveEncrypt:
+			ok = new(big.Int).Exp(x, priv.E, priv.N).Cmp(y) == 0
+		case rsa.PrimitiveDecryptCRT, rsa.PrimitiveSignCRT:
+			ok = new(big.Int).Exp(y, priv.E, priv.N).Cmp(x) == 0
+		case rsa.PrimitiveSignFDH:
+			ok = rsa.VerifyFDH(&priv.PublicKey, in, y) == nil
+		case rsa.PrimitiveKDF2:
+			ok = bytes.Equal(rsa.KDF2(in, len(out)), out)
+		case rsa.PrimitiveKEM:
+			key, err := rsa.DecapsulateKEM(priv, x, len(out))
+			ok = err == nil && bytes.Equal(key, out)
+		default:
+			t.Errorf("unexpected primitive %v", v.Primitive)
+			continue
+		}
+		if !ok {
+			t.Errorf("%v: output %v does not match input %v", v.Primitive, v.Output, v.Input)
+		}
+	}
+}
+
+// TestTestVectorsGolden pins the vectors of a fixed seed, so that a change
+// in how the standard library consumes the random stream, which would
+// silently change every published vector, fails here instead.
+func TestTestVectorsGolden(t *testing.T) {
+	tv, err := rsa.GenerateTestVectors("rsa test vectors", 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := rsa.WriteTestVectors(&buf, tv); err != nil {
+		t.Fatal(err)
+	}
+	const golden = "902ac17bdb83da343bc9e2efa5778d3c7594ead836ff7e8fe52a2c7aa05a2397"
+	sum := sha256.Sum256(buf.Bytes())
+	if got := hex.EncodeToString(sum[:]); got != golden {
+		t.Errorf("SHA-256 of the test vectors is %v, expected %v:\n%s", got, golden, buf.Bytes())
+	}
+}
+
+func TestReformatTestVectors(t *testing.T) {
+	tv, err := rsa.GenerateTestVectors("rsa test vectors", 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := new(big.Int).SetString(tv.Key.N, 16)
+	if err := tv.Reformat(rsa.FormatDecimal); err != nil {
+		t.Fatal(err)
+	}
+	if tv.Key.N != n.String() || tv.Format != rsa.FormatDecimal {
+		t.Errorf("reformatted n = %v in %v, expected %v in decimal", tv.Key.N, tv.Format, n)
+	}
+	if err := tv.Reformat(rsa.FormatBase64URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := tv.Reformat(rsa.FormatHex); err != nil || tv.Key.N != n.Text(16) {
+		t.Errorf("round trip gave n = %v (%v), expected %x", tv.Key.N, err, n)
+	}
+}
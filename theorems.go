@@ -0,0 +1,107 @@
+package rsa
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// PowerStep is one operation of a traced square and multiply:
+// Value = a^Exponent mod the modulus after a squaring (Op 'S'), which
+// doubles the exponent, or a multiplication by a (Op 'M'), which adds 1.
+type PowerStep struct {
+	Op              byte
+	Exponent, Value *big.Int
+}
+
+// Congruence holds both sides of a^Exponent = Right mod Modulus, computed
+// rather than assumed: Left is the traced power, Holds whether it equals
+// Right.
+type Congruence struct {
+	A, Exponent, Modulus *big.Int
+	Left, Right          *big.Int
+	Holds                bool
+	Steps                []PowerStep
+}
+
+// String shows the steps and the outcome, one per line, in decimal.
+func (c *Congruence) String() string {
+
+	return c.Format(FormatDecimal)
+}
+
+// Format is String with the numbers written in format f.
+func (c *Congruence) Format(f NumberFormat) string {
+
+	var sb strings.Builder
+	a, modulus := f.Format(c.A), f.Format(c.Modulus)
+	for _, step := range c.Steps {
+		fmt.Fprintf(&sb, "%c  %v^%v = %v mod %v\n", step.Op, a, f.Format(step.Exponent), f.Format(step.Value), modulus)
+	}
+	relation := "="
+	if !c.Holds {
+		relation = "!="
+	}
+	fmt.Fprintf(&sb, "%v^%v = %v %v %v mod %v\n", a, f.Format(c.Exponent), f.Format(c.Left), relation, f.Format(c.Right), modulus)
+	return sb.String()
+}
+
+// VerifyEulerTheorem checks a^φ(n) = 1 mod n for a coprime to n > 1,
+// computing φ(n) from the factorization of n, so n should not be an RSA
+// modulus of real size.
+// https://en.wikipedia.org/wiki/Euler%27s_theorem
+func VerifyEulerTheorem(a, n *big.Int) (*Congruence, error) {
+
+	if n.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("VerifyEulerTheorem: modulus %v must be > 1", n)
+	}
+	if gcd := new(big.Int).GCD(nil, nil, ModEuclid(new(big.Int), a, n), n); gcd.Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("VerifyEulerTheorem: %v and %v share the factor %v", a, n, gcd)
+	}
+	factors, err := Factorize(n)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyEulerTheorem: %v", err)
+	}
+	phi := Multiplicative(factors, func(p *big.Int, k int) *big.Int {
+		pk := new(big.Int).Exp(p, big.NewInt(int64(k)-1), nil)
+		return pk.Mul(pk, new(big.Int).Sub(p, big.NewInt(1)))
+	})
+	return traceCongruence(a, phi, n, big.NewInt(1)), nil
+}
+
+// VerifyFermatLittle checks a^p = a mod p, the form of Fermat's little
+// theorem that holds for every a when p is prime. A composite p > 1 is
+// accepted too: Holds is then false unless a is a Fermat liar for p,
+// which every a is for a Carmichael number.
+// https://en.wikipedia.org/wiki/Fermat%27s_little_theorem
+func VerifyFermatLittle(a, p *big.Int) (*Congruence, error) {
+
+	if p.Cmp(big.NewInt(1)) <= 0 {
+		return nil, fmt.Errorf("VerifyFermatLittle: modulus %v must be > 1", p)
+	}
+	return traceCongruence(a, p, p, ModEuclid(new(big.Int), a, p)), nil
+}
+
+// traceCongruence computes a^exp mod n step by step and compares it with
+// right.
+func traceCongruence(a, exp, n, right *big.Int) *Congruence {
+
+	c := &Congruence{
+		A:        new(big.Int).Set(a),
+		Exponent: new(big.Int).Set(exp),
+		Modulus:  new(big.Int).Set(n),
+		Right:    right,
+	}
+	prefix := new(big.Int)
+	c.Left = modExpObserved(ModEuclid(new(big.Int), a, n), exp, n, ExpSquareMultiply, func(op byte, value *big.Int) {
+
+		if op == opSquare {
+			prefix.Lsh(prefix, 1)
+		} else {
+			prefix.Add(prefix, big.NewInt(1))
+		}
+		c.Steps = append(c.Steps, PowerStep{Op: op, Exponent: new(big.Int).Set(prefix), Value: new(big.Int).Set(value)})
+	})
+	c.Holds = c.Left.Cmp(right) == 0
+	return c
+}
@@ -0,0 +1,56 @@
+package rsa_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/nethatix/rsa"
+)
+
+func TestVerifyEulerTheorem(t *testing.T) {
+	c, err := rsa.VerifyEulerTheorem(big.NewInt(7), big.NewInt(40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Holds || c.Exponent.Int64() != 16 || c.Left.Int64() != 1 {
+		t.Errorf("unexpected check %+v", c)
+	}
+	last := c.Steps[len(c.Steps)-1]
+	if last.Exponent.Cmp(c.Exponent) != 0 || last.Value.Cmp(c.Left) != 0 {
+		t.Errorf("the trace ends with %+v, expected 7^16 = 1", last)
+	}
+	for _, step := range c.Steps {
+		if expected := new(big.Int).Exp(c.A, step.Exponent, c.Modulus); step.Value.Cmp(expected) != 0 {
+			t.Errorf("step %c: 7^%v = %v, expected %v", step.Op, step.Exponent, step.Value, expected)
+		}
+	}
+	if !strings.Contains(c.String(), "7^16 = 1 = 1 mod 40") {
+		t.Errorf("unexpected trace:\n%v", c)
+	}
+	if trace := c.Format(rsa.FormatHex); !strings.Contains(trace, "7^10 = 1 = 1 mod 28") {
+		t.Errorf("unexpected hex trace:\n%v", trace)
+	}
+
+	if _, err := rsa.VerifyEulerTheorem(big.NewInt(6), big.NewInt(40)); err == nil {
+		t.Error("accepted a base sharing a factor with the modulus")
+	}
+}
+
+func TestVerifyFermatLittle(t *testing.T) {
+	for _, tc := range []struct {
+		a, p  int64
+		holds bool
+	}{
+		{3, 101, true},
+		{202, 101, true}, // a multiple of p: 0 = 0
+		{-5, 13, true},
+		{2, 15, false}, // 2 is a Fermat witness for 15
+		{2, 561, true}, // every base is a liar for a Carmichael number
+	} {
+		c, err := rsa.VerifyFermatLittle(big.NewInt(tc.a), big.NewInt(tc.p))
+		if err != nil || c.Holds != tc.holds {
+			t.Errorf("VerifyFermatLittle(%v, %v) = %v (%v), expected %v", tc.a, tc.p, c, err, tc.holds)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package rsa
+
+import (
+	"cmp"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TimingLabServer is a deliberately vulnerable decryption service for
+// timing attack exercises, serving POST requests whose body is a
+// hexadecimal ciphertext. It decrypts by Montgomery square and multiply,
+// neither constant time nor blinded, so the extra subtraction ending some
+// Montgomery multiplications, which depends on the ciphertext and on the
+// bits of d, shows in the response time. Delay lengthens each extra
+// subtraction to make a leak Brumley and Boneh measured across a real
+// network visible on a laptop within seconds.
+// Never expose it beyond localhost.
+// https://crypto.stanford.edu/~dabo/papers/ssl-timing.pdf
+type TimingLabServer struct {
+	// ExponentBlinding, if set, decrypts with a fresh d + k*λ(n) every
+	// time, which defeats TimingAttack.
+	ExponentBlinding bool
+
+	key   *PrivateKey
+	ctx   *ModContext
+	delay time.Duration
+}
+
+// NewTimingLabServer returns a server decrypting with priv whose extra
+// Montgomery subtractions each take delay longer.
+func NewTimingLabServer(priv *PrivateKey, delay time.Duration) (*TimingLabServer, error) {
+
+	ctx, err := NewModContext(priv.N, ReduceMontgomery)
+	if err != nil {
+		return nil, fmt.Errorf("NewTimingLabServer: %v", err)
+	}
+	return &TimingLabServer{key: priv, ctx: ctx, delay: delay}, nil
+}
+
+// ServeHTTP decrypts the ciphertext in the request body and answers "ok",
+// or 400 at once for a malformed or out of range ciphertext.
+func (s *TimingLabServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(s.key.N.BitLen())))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, ok := new(big.Int).SetString(strings.TrimSpace(string(body)), 16)
+	if !ok || c.Sign() < 0 || c.Cmp(s.key.N) >= 0 {
+		http.Error(w, "malformed ciphertext", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.decrypt(c, s.delay); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
+
+// SimulatedDuration returns the time the server would spend on the
+// ciphertext c if nothing but its extra subtractions took time, delay each:
+// noise free timings to run TimingAttack on without a network or a
+// scheduler. It honors ExponentBlinding.
+func (s *TimingLabServer) SimulatedDuration(c *big.Int) (time.Duration, error) {
+
+	if c.Sign() < 0 || c.Cmp(s.key.N) >= 0 {
+		return 0, fmt.Errorf("SimulatedDuration: ciphertext out of range [0, n)")
+	}
+	extras, err := s.decrypt(c, 0)
+	if err != nil {
+		return 0, fmt.Errorf("SimulatedDuration: %v", err)
+	}
+	return time.Duration(extras) * s.delay, nil
+}
+
+// decrypt runs the leaky exponentiation of c, spinning for delay after
+// every extra subtraction, and returns their number. The plaintext is
+// discarded, only the time spent on it matters.
+func (s *TimingLabServer) decrypt(c *big.Int, delay time.Duration) (int, error) {
+
+	d := s.key.D
+	if s.ExponentBlinding {
+		var err error
+		if d, err = BlindExponent(new(big.Int), s.key, defaultBlindingBits, nil); err != nil {
+			return 0, err
+		}
+		defer zeroizeInt(d)
+	}
+
+	x := s.ctx.toMontgomery(big.NewInt(1))
+	b := s.ctx.toMontgomery(c)
+	extras := 0
+	var extra bool
+	for i := d.BitLen() - 1; i >= 0; i-- {
+		if x, extra = s.ctx.montMulExtra(x, x); extra {
+			extras++
+			spin(delay)
+		}
+		if d.Bit(i) == 1 {
+			if x, extra = s.ctx.montMulExtra(x, b); extra {
+				extras++
+				spin(delay)
+			}
+		}
+	}
+	return extras, nil
+}
+
+// TimingSample is a ciphertext sent to a decryption server and the time
+// the server took to answer.
+type TimingSample struct {
+	C        *big.Int
+	Duration time.Duration
+}
+
+// timingOutliers is the percentage of slowest samples TimingAttack ignores.
+const timingOutliers = 2
+
+// timingRepeats is how often CollectTimings sends each ciphertext, keeping
+// the fastest answer as the one least disturbed by scheduling and network.
+const timingRepeats = 3
+
+// CollectTimings posts count random ciphertexts under pub, drawn from
+// random or crypto/rand.Reader if nil, to the server at url and measures
+// the response times.
+func CollectTimings(client *http.Client, url string, pub *PublicKey, count int, random io.Reader) ([]TimingSample, error) {
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+
+	samples := make([]TimingSample, count)
+	for i := range samples {
+		c, err := rand.Int(random, pub.N)
+		if err != nil {
+			return nil, fmt.Errorf("CollectTimings: %v", err)
+		}
+		samples[i].C = c
+		for r := 0; r < timingRepeats; r++ {
+			start := time.Now()
+			resp, err := client.Post(url, "text/plain", strings.NewReader(c.Text(16)))
+			if err != nil {
+				return nil, fmt.Errorf("CollectTimings: %v", err)
+			}
+			_, err = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			elapsed := time.Since(start)
+			if err != nil {
+				return nil, fmt.Errorf("CollectTimings: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("CollectTimings: server answered %v", resp.Status)
+			}
+			if r == 0 || elapsed < samples[i].Duration {
+				samples[i].Duration = elapsed
+			}
+		}
+	}
+	return samples, nil
+}
+
+// TimingAttack recovers d from the response times of a TimingLabServer,
+// bit by bit from the top as Dhem et al. did. Knowing the leading bits,
+// it replays every sample's exponentiation up to the next bit and predicts,
+// per hypothesis, whether an operation only that hypothesis performs ends
+// with an extra subtraction: the multiplication by the ciphertext for a 1,
+// the next squaring for a 0. Under the right hypothesis the samples
+// predicted to subtract are slower on average, under the wrong one the
+// prediction is noise. To sharpen the comparison the slowest samples are
+// dropped as outliers, and the time explained by the size of the
+// ciphertext, which every multiplication by it depends on, and by the
+// extra subtractions of the known bits is regressed out first.
+// A candidate d is accepted once it decrypts. If none does, the least
+// clear decisions are reversed one at a time and the bits after them
+// recovered again.
+// https://link.springer.com/chapter/10.1007/10721064_15
+func TimingAttack(pub *PublicKey, samples []TimingSample) (*big.Int, Cost, error) {
+
+	meter := newCostMeter()
+	meter.cost.OracleQueries = len(samples)
+	if len(samples) < 3 {
+		return nil, meter.done(), fmt.Errorf("TimingAttack: need at least 3 samples, got %v", len(samples))
+	}
+	ctx, err := NewModContext(pub.N, ReduceMontgomery)
+	if err != nil {
+		return nil, meter.done(), fmt.Errorf("TimingAttack: %v", err)
+	}
+	// The slowest answers were mostly held up by the scheduler or the
+	// garbage collector, dropping them removes noise rather than signal.
+	samples = slices.Clone(samples)
+	slices.SortFunc(samples, func(a, b TimingSample) int { return cmp.Compare(a.Duration, b.Duration) })
+	samples = samples[:len(samples)-len(samples)*timingOutliers/100]
+
+	attack := &timingAttack{
+		pub:   pub,
+		ctx:   ctx,
+		meter: meter,
+		bs:    make([]*big.Int, len(samples)),
+		sizes: make([]float64, len(samples)),
+		times: make([]float64, len(samples)),
+	}
+	nFloat := new(big.Float).SetInt(pub.N)
+	for j, sample := range samples {
+		attack.bs[j] = ctx.toMontgomery(sample.C)
+		attack.sizes[j], _ = new(big.Float).Quo(new(big.Float).SetInt(attack.bs[j]), nFloat).Float64()
+		attack.times[j] = float64(sample.Duration)
+	}
+	attack.times = regressOut(attack.times, attack.sizes)
+	// d is complete once (2^e)^d = 2.
+	attack.encrypted = new(big.Int).Exp(big.NewInt(2), pub.E, pub.N)
+	meter.exp(pub.E)
+
+	d, margins := attack.recover(-1)
+	if d != nil {
+		return d, meter.done(), nil
+	}
+	steps := make([]int, len(margins))
+	for i := range steps {
+		steps[i] = i
+	}
+	slices.SortFunc(steps, func(a, b int) int { return cmp.Compare(margins[a], margins[b]) })
+	for _, step := range steps[:min(len(steps), timingRetries)] {
+		if d, _ = attack.recover(step); d != nil {
+			return d, meter.done(), nil
+		}
+	}
+	return nil, meter.done(), fmt.Errorf("TimingAttack: no exponent recovered, the timings do not leak d")
+}
+
+// timingRetries bounds the reversed decisions TimingAttack tries.
+const timingRetries = 16
+
+// timingAttack is the state TimingAttack shares between its passes.
+type timingAttack struct {
+	pub       *PublicKey
+	ctx       *ModContext
+	meter     *costMeter
+	bs        []*big.Int // ciphertexts in Montgomery form
+	sizes     []float64  // ciphertexts relative to n
+	times     []float64  // with the part explained by sizes removed
+	encrypted *big.Int
+}
+
+// recover runs one pass of TimingAttack, reversing the decision for the
+// bit after the leading one numbered flip, if any. It returns d, or nil
+// and how clearly each bit was decided.
+func (a *timingAttack) recover(flip int) (*big.Int, []float64) {
+
+	n := len(a.bs)
+	xs := make([]*big.Int, n)
+	known := make([]float64, n)
+	oneMont := a.ctx.toMontgomery(big.NewInt(1))
+	for j := range xs {
+		// The leading bit of d is a 1.
+		x, extraSquare := a.ctx.montMulExtra(oneMont, oneMont)
+		x, extraMul := a.ctx.montMulExtra(x, a.bs[j])
+		xs[j] = x
+		known[j] = float64(boolInt(extraSquare) + boolInt(extraMul))
+	}
+	a.meter.cost.ModMuls += 2 * n
+
+	d := big.NewInt(1)
+	check := new(big.Int)
+	squares := make([]*big.Int, n)
+	products := make([]*big.Int, n)
+	squareExtra := make([]bool, n)
+	one := make([]bool, n)
+	zero := make([]bool, n)
+	var margins []float64
+
+	for d.BitLen() < a.pub.N.BitLen() {
+		a.meter.exp(d)
+		if check.Exp(a.encrypted, d, a.pub.N).Cmp(big.NewInt(2)) == 0 {
+			return d, margins
+		}
+
+		for j := range xs {
+			squares[j], squareExtra[j] = a.ctx.montMulExtra(xs[j], xs[j])
+			products[j], one[j] = a.ctx.montMulExtra(squares[j], a.bs[j])
+			_, zero[j] = a.ctx.montMulExtra(squares[j], squares[j])
+		}
+		a.meter.cost.ModMuls += 3 * n
+
+		residuals := regressOut(a.times, regressOut(known, a.sizes))
+		margin := splitMeanDifference(residuals, one) - splitMeanDifference(residuals, zero)
+		bit := margin > 0
+		if len(margins) == flip {
+			bit = !bit
+		}
+		margins = append(margins, math.Abs(margin))
+
+		d.Lsh(d, 1)
+		for j := range xs {
+			known[j] += float64(boolInt(squareExtra[j]) + boolInt(bit && one[j]))
+		}
+		if bit {
+			d.SetBit(d, 0, 1)
+			xs, products = products, xs
+		} else {
+			xs, squares = squares, xs
+		}
+		a.meter.sample()
+	}
+	return nil, margins
+}
+
+// montMulExtra returns the Montgomery product x*y/R mod N and whether it
+// needed the extra subtraction.
+func (ctx *ModContext) montMulExtra(x, y *big.Int) (*big.Int, bool) {
+
+	return ctx.redcExtra(new(big.Int).Mul(x, y))
+}
+
+// splitMeanDifference is the mean of the times marked in split minus the
+// mean of the others, 0 if either group is empty.
+func splitMeanDifference(times []float64, split []bool) float64 {
+
+	var sums [2]float64
+	var counts [2]int
+	for i, t := range times {
+		group := 0
+		if split[i] {
+			group = 1
+		}
+		sums[group] += t
+		counts[group]++
+	}
+	if counts[0] == 0 || counts[1] == 0 {
+		return 0
+	}
+	return sums[1]/float64(counts[1]) - sums[0]/float64(counts[0])
+}
+
+// regressOut returns the residuals of the least squares line fitting ys
+// to xs, ys with the part linearly explained by xs removed.
+func regressOut(ys, xs []float64) []float64 {
+
+	meanX, sdX := meanStdDev(xs)
+	meanY, sdY := meanStdDev(ys)
+	slope := 0.0
+	if sdX > 0 {
+		slope = correlation(xs, ys) * sdY / sdX
+	}
+	residuals := make([]float64, len(ys))
+	for i := range ys {
+		residuals[i] = ys[i] - meanY - slope*(xs[i]-meanX)
+	}
+	return residuals
+}
+
+// boolInt is 1 for true and 0 for false.
+func boolInt(b bool) int {
+
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// spin busy waits for d, more precise than time.Sleep at microseconds.
+func spin(d time.Duration) {
+
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
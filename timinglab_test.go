@@ -0,0 +1,108 @@
+package rsa_test
+
+import (
+	"crypto/rand"
+	"math/big"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nethatix/rsa"
+)
+
+// timingLabKey has 2 primes of 24 bits, making d short enough to recover
+// in seconds.
+func timingLabKey(t *testing.T) *rsa.PrivateKey {
+
+	priv, err := rsa.NewPrivateKey(big.NewInt(16777213), big.NewInt(16777199), big.NewInt(65537))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+// simulatedTimings returns count noise free samples of server for
+// ciphertexts drawn from a fixed seed.
+func simulatedTimings(t *testing.T, server *rsa.TimingLabServer, pub *rsa.PublicKey, count int) []rsa.TimingSample {
+
+	random := mathrand.NewChaCha8([32]byte{1})
+	samples := make([]rsa.TimingSample, count)
+	for i := range samples {
+		c, err := rand.Int(random, pub.N)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := server.SimulatedDuration(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples[i] = rsa.TimingSample{C: c, Duration: d}
+	}
+	return samples
+}
+
+func TestTimingAttackSimulated(t *testing.T) {
+	priv := timingLabKey(t)
+	server, err := rsa.NewTimingLabServer(priv, time.Microsecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := simulatedTimings(t, server, &priv.PublicKey, 2000)
+	d, cost, err := rsa.TimingAttack(&priv.PublicKey, samples)
+	if err != nil || d.Cmp(priv.D) != 0 {
+		t.Fatalf("recovered d = %v (%v), expected %v", d, err, priv.D)
+	}
+	if cost.OracleQueries != len(samples) {
+		t.Errorf("cost counts %v queries, expected %v", cost.OracleQueries, len(samples))
+	}
+}
+
+// TestTimingAttack measures a real server over HTTP; wall clock timings
+// depend on the machine and its load, so it only runs with
+// RSA_TIMING_LAB=1.
+func TestTimingAttack(t *testing.T) {
+	if os.Getenv("RSA_TIMING_LAB") != "1" {
+		t.Skip("set RSA_TIMING_LAB=1 to time a live server")
+	}
+	priv := timingLabKey(t)
+	server, err := rsa.NewTimingLabServer(priv, 20*time.Microsecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	samples, err := rsa.CollectTimings(ts.Client(), ts.URL, &priv.PublicKey, 2000, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, cost, err := rsa.TimingAttack(&priv.PublicKey, samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Cmp(priv.D) != 0 {
+		t.Errorf("recovered d = %v, expected %v", d, priv.D)
+	}
+	if cost.OracleQueries != len(samples) {
+		t.Errorf("cost counts %v queries, expected %v", cost.OracleQueries, len(samples))
+	}
+}
+
+func TestTimingLabServerRejects(t *testing.T) {
+	priv := timingLabKey(t)
+	server, err := rsa.NewTimingLabServer(priv, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"not hex", priv.N.Text(16)} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status %v, expected %v", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
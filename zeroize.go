@@ -0,0 +1,52 @@
+package rsa
+
+import "math/big"
+
+// Zeroize overwrites the secret fields d, p, q and the CRT parameters
+// with zeros and leaves them 0, keeping the public key. It is best
+// effort: math/big may have left copies in memory freed by earlier
+// arithmetic, and the garbage collector moves nothing but does not clear
+// freed memory either, so Go can only shorten how long secrets linger,
+// not guarantee they are gone.
+// https://en.wikipedia.org/wiki/Zeroisation
+func (priv *PrivateKey) Zeroize() {
+
+	for _, x := range []*big.Int{priv.D, priv.P, priv.Q, priv.Dp, priv.Dq, priv.Qinv} {
+		zeroizeInt(x)
+	}
+}
+
+// Zeroize overwrites the known bits of every secret field with zeros.
+func (pk *PartialKey) Zeroize() {
+
+	for _, bits := range []*PartialBits{pk.P, pk.Q, pk.D, pk.Dp, pk.Dq} {
+		if bits != nil {
+			zeroizeInt(bits.Value)
+			zeroizeInt(bits.Mask)
+		}
+	}
+}
+
+// Zeroize overwrites the recorded random bytes, from which every key of
+// the session can be regenerated, leaving the session unable to replay
+// them.
+func (s *Session) Zeroize() {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.events {
+		clear(s.events[i].Data)
+	}
+	clear(s.pending)
+}
+
+// zeroizeInt overwrites the words of x, if not nil, and sets it to 0.
+func zeroizeInt(x *big.Int) {
+
+	if x == nil {
+		return
+	}
+	words := x.Bits()
+	clear(words[:cap(words)])
+	x.SetInt64(0)
+}
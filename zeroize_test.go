@@ -0,0 +1,61 @@
+package rsa_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/nethatix/rsa"
+	"github.com/nethatix/rsa/rsatest"
+)
+
+func TestPrivateKeyZeroize(t *testing.T) {
+	priv, err := rsatest.RandomKeyPair(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := new(big.Int).Set(priv.N)
+	words := priv.D.Bits()
+
+	priv.Zeroize()
+	for name, x := range map[string]*big.Int{"d": priv.D, "p": priv.P, "q": priv.Q, "dp": priv.Dp, "dq": priv.Dq, "qinv": priv.Qinv} {
+		if x.Sign() != 0 {
+			t.Errorf("%v = %v after Zeroize", name, x)
+		}
+	}
+	// The words d was stored in are overwritten, not just dropped.
+	for _, word := range words[:cap(words)] {
+		if word != 0 {
+			t.Fatal("d's memory still holds the exponent")
+		}
+	}
+	if priv.N.Cmp(n) != 0 {
+		t.Error("Zeroize changed the public key")
+	}
+}
+
+func TestPartialKeyZeroize(t *testing.T) {
+	pk := &rsa.PartialKey{
+		P: &rsa.PartialBits{Value: big.NewInt(0xbeef), Mask: big.NewInt(0xffff)},
+		D: &rsa.PartialBits{Value: big.NewInt(0xcafe), Mask: big.NewInt(0xff00)},
+	}
+	pk.Zeroize()
+	if pk.P.Value.Sign() != 0 || pk.P.Mask.Sign() != 0 || pk.D.Value.Sign() != 0 {
+		t.Errorf("known bits survive Zeroize: %v %v", pk.P, pk.D)
+	}
+}
+
+func TestSessionZeroize(t *testing.T) {
+	session := rsa.NewSession(nil)
+	buf := make([]byte, 32)
+	if _, err := session.Random().Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	session.Zeroize()
+	for _, event := range session.Events() {
+		if !bytes.Equal(event.Data, make([]byte, len(event.Data))) {
+			t.Errorf("random bytes %x survive Zeroize", event.Data)
+		}
+	}
+}